@@ -0,0 +1,306 @@
+// Command seed bulk-loads species, their abilities and moves into the pokedex,
+// pokemon_abilities, moves and pokemon_moves tables, so catching or
+// challenging a seeded species never has to wait on PokéAPI. Species that
+// aren't seeded are still fetched on first use.
+//
+//	go run ./cmd/seed -snapshot sql/seed/starter.json
+//	go run ./cmd/seed -mirror ~/api-data/data/api/v2 -max-id 151 -dump pokedex.json
+//
+// A snapshot is the JSON written by -dump or cmd/simulate -save-snapshot. Only
+// -mirror, or a snapshot dumped from one, loads a full pokedex: the committed
+// sql/seed/starter.json is a sample of pikachu and meowth. A mirror is a copy
+// of the PokeAPI/api-data repository, whose species go through the same move
+// selection as a first catch. Species already in the pokedex are skipped, so
+// seeding again is safe, but the ones cached before abilities were get their
+// abilities filled in.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/handlers"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+	"github.com/JadedPigeon/pokemongolang/internal/snapshot"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+// Counts for the summary
+type progress struct {
+	added, skipped, failed, moves, abilities int
+}
+
+func main() {
+	godotenv.Load()
+
+	snapshotPath := flag.String("snapshot", "", "JSON snapshot to load")
+	mirror := flag.String("mirror", "", "load from this PokéAPI mirror directory instead of a snapshot")
+	maxID := flag.Int("max-id", 0, "with -mirror, only load species up to this pokedex number, 0 for all")
+	dump := flag.String("dump", "", "after seeding, write the whole cached pokedex to this JSON snapshot")
+	flag.Parse()
+	if (*snapshotPath == "") == (*mirror == "") {
+		fmt.Fprintln(os.Stderr, "seed: give one of -snapshot or -mirror")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		dbURL = os.Getenv("DB_URL")
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Error opening DB: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Error pinging DB: %v", err)
+	}
+	ctx := context.Background()
+	q := database.New(db)
+
+	var p progress
+	if *mirror != "" {
+		p, err = seedMirror(ctx, db, q, *mirror, *maxID)
+	} else {
+		p, err = seedSnapshot(ctx, db, *snapshotPath)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	summary := fmt.Sprintf("Seeded %d species", p.added)
+	if *mirror == "" {
+		summary += fmt.Sprintf(" and %d new moves", p.moves)
+	}
+	log.Printf("%s, skipped %d already cached, %d failed", summary, p.skipped, p.failed)
+	if p.abilities > 0 {
+		log.Printf("Backfilled abilities for %d cached species", p.abilities)
+	}
+
+	if *dump != "" {
+		s, err := snapshot.FromDB(ctx, q)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := s.Save(*dump); err != nil {
+			log.Fatalf("Error saving snapshot: %v", err)
+		}
+		log.Printf("Wrote %d species and %d moves to %s", len(s.Pokemon), len(s.Moves), *dump)
+	}
+	if p.failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// Inserts every species in a snapshot, each in its own transaction
+func seedSnapshot(ctx context.Context, db *sql.DB, path string) (progress, error) {
+	s, err := snapshot.Load(path)
+	if err != nil {
+		return progress{}, err
+	}
+	var p progress
+	for i, species := range s.Pokemon {
+		status := "added"
+		moves, err := seedSpecies(ctx, db, s, species)
+		switch {
+		case errors.Is(err, errCached):
+			p.skipped++
+			status = "already cached"
+		case errors.Is(err, errAbilitiesBackfilled):
+			p.skipped++
+			p.abilities++
+			status = "already cached, abilities added"
+		case err != nil:
+			p.failed++
+			status = err.Error()
+		default:
+			p.added++
+			p.moves += moves
+		}
+		log.Printf("[%d/%d] %s: %s", i+1, len(s.Pokemon), species.Name, status)
+	}
+	return p, nil
+}
+
+var (
+	errCached              = errors.New("already cached")
+	errAbilitiesBackfilled = errors.New("already cached, abilities added")
+)
+
+// Inserts a species with its abilities, its moves that aren't cached yet and
+// the links to them. It returns how many moves were new.
+func seedSpecies(ctx context.Context, db *sql.DB, s *snapshot.Snapshot, species snapshot.Species) (int, error) {
+	if len(species.Types) == 0 {
+		return 0, fmt.Errorf("%s has no types", species.Name)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	q := database.New(tx)
+
+	if _, err := q.FetchPokemonDataById(ctx, species.ID); err == nil {
+		// Species cached before abilities were get them from the snapshot
+		abilities, err := q.GetPokemonAbilities(ctx, species.ID)
+		if err != nil {
+			return 0, err
+		}
+		if len(abilities) > 0 || len(species.Abilities) == 0 {
+			return 0, errCached
+		}
+		if err := insertAbilities(ctx, q, species); err != nil {
+			return 0, err
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+		return 0, errAbilitiesBackfilled
+	} else if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	var type2 sql.NullString
+	if len(species.Types) > 1 {
+		type2 = sql.NullString{String: species.Types[1], Valid: true}
+	}
+	if err := q.UpsertPokedex(ctx, database.UpsertPokedexParams{
+		ID:             species.ID,
+		Name:           species.Name,
+		Type1:          species.Types[0],
+		Type2:          type2,
+		Hp:             species.Stats.HP,
+		Attack:         species.Stats.Attack,
+		Defense:        species.Stats.Defense,
+		SpecialAttack:  species.Stats.SpecialAttack,
+		SpecialDefense: species.Stats.SpecialDefense,
+		Speed:          species.Stats.Speed,
+		ImageUrl:       sql.NullString{String: species.ImageURL, Valid: species.ImageURL != ""},
+	}); err != nil {
+		return 0, fmt.Errorf("error inserting pokemon into db: %w", err)
+	}
+
+	for _, n := range species.Names {
+		if err := q.UpsertPokemonName(ctx, database.UpsertPokemonNameParams{
+			PokemonID:  species.ID,
+			Language:   n.Language,
+			Name:       sql.NullString{String: n.Name, Valid: n.Name != ""},
+			FlavorText: sql.NullString{String: n.FlavorText, Valid: n.FlavorText != ""},
+		}); err != nil {
+			return 0, fmt.Errorf("error inserting %s name for %s: %w", n.Language, species.Name, err)
+		}
+	}
+
+	if err := insertAbilities(ctx, q, species); err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, id := range species.Moves {
+		m, ok := s.Move(id)
+		if !ok {
+			return 0, fmt.Errorf("move %d isn't in the snapshot", id)
+		}
+		if _, err := q.GetMoveByID(ctx, id); err == sql.ErrNoRows {
+			target := m.Target
+			if target == "" {
+				target = battle.TargetSelected
+			}
+			hits := m.MinHits > 0 && m.MaxHits > 0
+			if err := q.UpsertMove(ctx, database.UpsertMoveParams{
+				MoveID:      m.ID,
+				Name:        m.Name,
+				Power:       m.Power,
+				Type:        m.Type,
+				Description: sql.NullString{String: m.Description, Valid: m.Description != ""},
+				DamageClass: m.DamageClass,
+				Priority:    m.Priority,
+				MinHits:     sql.NullInt32{Int32: m.MinHits, Valid: hits},
+				MaxHits:     sql.NullInt32{Int32: m.MaxHits, Valid: hits},
+				Target:      target,
+			}); err != nil {
+				return 0, fmt.Errorf("error inserting move %s: %w", m.Name, err)
+			}
+			for _, n := range m.Names {
+				if err := q.UpsertMoveName(ctx, database.UpsertMoveNameParams{
+					MoveID:     m.ID,
+					Language:   n.Language,
+					Name:       sql.NullString{String: n.Name, Valid: n.Name != ""},
+					FlavorText: sql.NullString{String: n.FlavorText, Valid: n.FlavorText != ""},
+				}); err != nil {
+					return 0, fmt.Errorf("error inserting %s name for move %s: %w", n.Language, m.Name, err)
+				}
+			}
+			added++
+		} else if err != nil {
+			return 0, err
+		}
+		if err := q.InsertPokemonMove(ctx, database.InsertPokemonMoveParams{
+			PokemonID: species.ID,
+			MoveID:    id,
+		}); err != nil {
+			return 0, fmt.Errorf("error linking move %s: %w", m.Name, err)
+		}
+	}
+	return added, tx.Commit()
+}
+
+func insertAbilities(ctx context.Context, q *database.Queries, species snapshot.Species) error {
+	for _, a := range species.Abilities {
+		if err := q.InsertPokemonAbility(ctx, database.InsertPokemonAbilityParams{
+			PokemonID: species.ID,
+			Ability:   a.Name,
+			IsHidden:  a.IsHidden,
+			Slot:      a.Slot,
+		}); err != nil {
+			return fmt.Errorf("error inserting ability %s: %w", a.Name, err)
+		}
+	}
+	return nil
+}
+
+// Fetches every species in a mirror the same way a first catch does, then
+// fills in the abilities of species cached before abilities were
+func seedMirror(ctx context.Context, db *sql.DB, q *database.Queries, root string, maxID int) (progress, error) {
+	dir := pokeapi.NewDir(root)
+	ids, err := dir.IDs("pokemon")
+	if err != nil {
+		return progress{}, fmt.Errorf("error listing species in %s: %w", root, err)
+	}
+	if maxID > 0 {
+		n := 0
+		for n < len(ids) && ids[n] <= maxID {
+			n++
+		}
+		ids = ids[:n]
+	}
+
+	cfg := &handlers.Config{DB: q, DBConn: db, PokeAPI: dir}
+	var p progress
+	for i, id := range ids {
+		status := "added"
+		if _, err := q.FetchPokemonDataById(ctx, int32(id)); err == nil {
+			p.skipped++
+			status = "already cached"
+		} else if err != sql.ErrNoRows {
+			return p, err
+		} else if err := cfg.FetchPokemonData(ctx, strconv.Itoa(id)); err != nil {
+			p.failed++
+			status = err.Error()
+		} else {
+			p.added++
+		}
+		log.Printf("[%d/%d] pokemon %d: %s", i+1, len(ids), id, status)
+	}
+	p.abilities, err = cfg.BackfillAbilities(ctx)
+	return p, err
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/snapshot"
+)
+
+// Saved teams are given as team:<name>, the party of that user in the
+// database or the team of that name in a snapshot
+const teamPrefix = "team:"
+
+// Splits a side into its team members. A side is a saved team or a comma
+// separated list of species, each optionally with an ability and held item
+// like gyarados/intimidate@leftovers.
+func parseSide(s *snapshot.Snapshot, side string) ([]snapshot.Member, error) {
+	if name, ok := strings.CutPrefix(side, teamPrefix); ok {
+		team, ok := s.Teams[name]
+		if !ok || len(team) == 0 {
+			return nil, fmt.Errorf("no saved team %q", name)
+		}
+		return team, nil
+	}
+
+	var members []snapshot.Member
+	for _, entry := range strings.Split(side, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var m snapshot.Member
+		entry, m.HeldItem, _ = strings.Cut(entry, "@")
+		m.Species, m.Ability, _ = strings.Cut(entry, "/")
+		members = append(members, m)
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("no pokemon in %q", side)
+	}
+	return members, nil
+}
+
+// Builds a side's pokemon for the battle engine. Pokemon without an ability
+// given get their species' first regular one.
+func buildTeam(s *snapshot.Snapshot, members []snapshot.Member, level int) ([]*battle.Pokemon, error) {
+	var team []*battle.Pokemon
+	for _, m := range members {
+		species, ok := s.Species(m.Species)
+		if !ok {
+			return nil, fmt.Errorf("%s isn't in the snapshot", m.Species)
+		}
+		p := battle.NewPokemon(species.Name, species.Types, battle.Stats{
+			HP:             int(species.Stats.HP),
+			Attack:         int(species.Stats.Attack),
+			Defense:        int(species.Stats.Defense),
+			SpecialAttack:  int(species.Stats.SpecialAttack),
+			SpecialDefense: int(species.Stats.SpecialDefense),
+			Speed:          int(species.Stats.Speed),
+		}, level)
+		p.Ability = strings.ToLower(m.Ability)
+		if p.Ability == "" {
+			p.Ability = defaultAbility(species.Abilities)
+		}
+		p.HeldItem = strings.ToLower(m.HeldItem)
+		for _, id := range species.Moves {
+			move, ok := s.Move(id)
+			if !ok {
+				return nil, fmt.Errorf("move %d of %s isn't in the snapshot", id, species.Name)
+			}
+			p.Moves = append(p.Moves, battle.Move{
+				ID:          move.ID,
+				Name:        move.Name,
+				Type:        move.Type,
+				Power:       int(move.Power),
+				DamageClass: move.DamageClass,
+				Priority:    int(move.Priority),
+				MinHits:     int(move.MinHits),
+				MaxHits:     int(move.MaxHits),
+				Target:      move.Target,
+			})
+		}
+		if len(p.Moves) == 0 {
+			return nil, fmt.Errorf("%s has no cached moves", species.Name)
+		}
+		team = append(team, p)
+	}
+	return team, nil
+}
+
+// The regular ability in the lowest slot, empty if none are cached
+func defaultAbility(abilities []snapshot.Ability) string {
+	var best *snapshot.Ability
+	for i, a := range abilities {
+		if !a.IsHidden && (best == nil || a.Slot < best.Slot) {
+			best = &abilities[i]
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.Name
+}
+
+// Copies what the sides need from the cached pokedex, moves and parties into
+// a snapshot. Nothing is fetched from PokéAPI, a species that has never been
+// caught or challenged isn't cached and is an error.
+func loadFromDB(ctx context.Context, q *database.Queries, sides []string) (*snapshot.Snapshot, error) {
+	s := &snapshot.Snapshot{Teams: map[string][]snapshot.Member{}}
+	for _, side := range sides {
+		if name, ok := strings.CutPrefix(side, teamPrefix); ok {
+			team, err := loadParty(ctx, q, s, name)
+			if err != nil {
+				return nil, err
+			}
+			s.Teams[name] = team
+			continue
+		}
+
+		members, err := parseSide(s, side)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range members {
+			var p database.Pokedex
+			if id, err := strconv.Atoi(m.Species); err == nil {
+				p, err = q.FetchPokemonDataById(ctx, int32(id))
+			} else {
+				p, err = q.FetchPokemonDataByName(ctx, m.Species)
+			}
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil, fmt.Errorf("%s isn't in the cached pokedex, catch or challenge it once first", m.Species)
+				}
+				return nil, fmt.Errorf("error fetching pokemon data for %s: %w", m.Species, err)
+			}
+			if err := s.AddSpecies(ctx, q, p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// A user's party as a saved team, at full health
+func loadParty(ctx context.Context, q *database.Queries, s *snapshot.Snapshot, username string) ([]snapshot.Member, error) {
+	user, err := q.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no user %q", username)
+		}
+		return nil, fmt.Errorf("error getting user %s: %w", username, err)
+	}
+	party, err := q.GetUserPartyPokemon(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting party for %s: %w", username, err)
+	}
+	if len(party) == 0 {
+		return nil, fmt.Errorf("%s has no pokemon in their party", username)
+	}
+
+	var team []snapshot.Member
+	for _, up := range party {
+		p, err := q.FetchPokemonDataById(ctx, up.PokemonID.Int32)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching pokemon data for %d: %w", up.PokemonID.Int32, err)
+		}
+		if err := s.AddSpecies(ctx, q, p); err != nil {
+			return nil, err
+		}
+		m := snapshot.Member{Species: p.Name, Ability: up.Ability.String}
+		if up.HeldItemID.Valid {
+			item, err := q.GetItemByID(ctx, up.HeldItemID.Int32)
+			if err != nil {
+				return nil, fmt.Errorf("error getting held item %d: %w", up.HeldItemID.Int32, err)
+			}
+			m.HeldItem = item.Name
+		}
+		team = append(team, m)
+	}
+	return team, nil
+}
@@ -0,0 +1,266 @@
+// Command simulate runs thousands of headless battles between two sides with
+// the battle engine and challenger AI, and reports how the matchup goes.
+//
+//	go run ./cmd/simulate -a pikachu -b gyarados -n 10000
+//	go run ./cmd/simulate -a team:ash -b team:gary -snapshot pokedex.json
+//
+// Species and moves come from the cached pokedex and moves tables, or from a
+// JSON snapshot with -snapshot, never from PokéAPI.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/snapshot"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+// Results of a run, summed over every battle
+type tally struct {
+	Wins     [2]int
+	Draws    int
+	Turns    int
+	MoveUses [2]map[string]int
+}
+
+func newTally() tally {
+	return tally{MoveUses: [2]map[string]int{{}, {}}}
+}
+
+func (t *tally) add(o battle.Outcome) {
+	if o.Winner >= 0 {
+		t.Wins[o.Winner]++
+	} else {
+		t.Draws++
+	}
+	t.Turns += o.Turns
+	for side, uses := range o.MoveUses {
+		for name, n := range uses {
+			t.MoveUses[side][name] += n
+		}
+	}
+}
+
+func (t *tally) merge(o tally) {
+	t.Wins[0] += o.Wins[0]
+	t.Wins[1] += o.Wins[1]
+	t.Draws += o.Draws
+	t.Turns += o.Turns
+	for side, uses := range o.MoveUses {
+		for name, n := range uses {
+			t.MoveUses[side][name] += n
+		}
+	}
+}
+
+// Plays n battles split across workers, each with its own random source
+func simulate(teams [2][]*battle.Pokemon, n, workers int, seed int64) tally {
+	results := make([]tally, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		battles := n / workers
+		if w < n%workers {
+			battles++
+		}
+		wg.Add(1)
+		go func(w, battles int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed + int64(w)))
+			t := newTally()
+			for i := 0; i < battles; i++ {
+				var fresh [2][]*battle.Pokemon
+				for side, team := range teams {
+					for _, p := range team {
+						fresh[side] = append(fresh[side], p.Clone())
+					}
+				}
+				t.add(battle.PlayOut(rng, fresh))
+			}
+			results[w] = t
+		}(w, battles)
+	}
+	wg.Wait()
+
+	total := newTally()
+	for _, t := range results {
+		total.merge(t)
+	}
+	return total
+}
+
+type moveUseDTO struct {
+	Move    string  `json:"move"`
+	Uses    int     `json:"uses"`
+	Percent float64 `json:"percent"`
+}
+
+type sideDTO struct {
+	Side     string       `json:"side"`
+	Pokemon  []string     `json:"pokemon"`
+	Wins     int          `json:"wins"`
+	WinRate  float64      `json:"win_rate"`
+	MoveUses []moveUseDTO `json:"move_usage"`
+}
+
+type reportDTO struct {
+	Battles      int        `json:"battles"`
+	Workers      int        `json:"workers"`
+	Seed         int64      `json:"seed"`
+	Level        int        `json:"level"`
+	Sides        [2]sideDTO `json:"sides"`
+	Draws        int        `json:"draws"`
+	AverageTurns float64    `json:"average_turns"`
+}
+
+// n as a percent of of, to one decimal place
+func percent(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*1000/float64(of)) / 10
+}
+
+func report(sides [2]string, teams [2][]*battle.Pokemon, t tally, n, workers, level int, seed int64) reportDTO {
+	r := reportDTO{
+		Battles: n,
+		Workers: workers,
+		Seed:    seed,
+		Level:   level,
+		Draws:   t.Draws,
+	}
+	if n > 0 {
+		r.AverageTurns = float64(t.Turns) / float64(n)
+	}
+	for side := range sides {
+		s := sideDTO{
+			Side:    sides[side],
+			Wins:    t.Wins[side],
+			WinRate: percent(t.Wins[side], n),
+		}
+		for _, p := range teams[side] {
+			s.Pokemon = append(s.Pokemon, p.Name)
+		}
+		used := 0
+		for _, uses := range t.MoveUses[side] {
+			used += uses
+		}
+		for name, uses := range t.MoveUses[side] {
+			s.MoveUses = append(s.MoveUses, moveUseDTO{Move: name, Uses: uses, Percent: percent(uses, used)})
+		}
+		sort.Slice(s.MoveUses, func(i, j int) bool {
+			if s.MoveUses[i].Uses != s.MoveUses[j].Uses {
+				return s.MoveUses[i].Uses > s.MoveUses[j].Uses
+			}
+			return s.MoveUses[i].Move < s.MoveUses[j].Move
+		})
+		r.Sides[side] = s
+	}
+	return r
+}
+
+func printReport(r reportDTO) {
+	fmt.Printf("%s vs %s: %d battles at level %d, %d workers, seed %d\n\n",
+		r.Sides[0].Side, r.Sides[1].Side, r.Battles, r.Level, r.Workers, r.Seed)
+	for _, s := range r.Sides {
+		fmt.Printf("%-30s %7d wins  %5.1f%%\n", s.Side, s.Wins, s.WinRate)
+	}
+	fmt.Printf("%-30s %7d       %5.1f%%\n", "draws", r.Draws, percent(r.Draws, r.Battles))
+	fmt.Printf("average turns %.1f\n", r.AverageTurns)
+	for _, s := range r.Sides {
+		fmt.Printf("\nmove usage, %s:\n", s.Side)
+		for _, m := range s.MoveUses {
+			fmt.Printf("  %-28s %9d  %5.1f%%\n", m.Move, m.Uses, m.Percent)
+		}
+	}
+}
+
+func main() {
+	godotenv.Load()
+
+	a := flag.String("a", "", "first side: species like pikachu,gyarados/intimidate@leftovers, or team:<name>")
+	b := flag.String("b", "", "second side, like -a")
+	n := flag.Int("n", 1000, "number of battles")
+	workers := flag.Int("workers", runtime.NumCPU(), "battles run in parallel")
+	level := flag.Int("level", battle.DefaultLevel, "level of every pokemon")
+	seed := flag.Int64("seed", 0, "random seed, 0 for the current time")
+	snapshotPath := flag.String("snapshot", "", "read species and moves from this JSON snapshot instead of the database")
+	savePath := flag.String("save-snapshot", "", "write the species, moves and teams used to this JSON snapshot")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *a == "" || *b == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *n < 1 || *workers < 1 || *level < 1 || *level > 100 {
+		log.Fatal("-n and -workers must be at least 1 and -level from 1 to 100")
+	}
+	*workers = min(*workers, *n)
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	sides := [2]string{*a, *b}
+
+	var s *snapshot.Snapshot
+	var err error
+	if *snapshotPath != "" {
+		s, err = snapshot.Load(*snapshotPath)
+	} else {
+		dbURL := os.Getenv("DATABASE_URL")
+		if dbURL == "" {
+			dbURL = os.Getenv("DB_URL")
+		}
+		db, dbErr := sql.Open("postgres", dbURL)
+		if dbErr != nil {
+			log.Fatalf("Error opening DB: %v", dbErr)
+		}
+		defer db.Close()
+		s, err = loadFromDB(context.Background(), database.New(db), sides[:])
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *savePath != "" {
+		if err := s.Save(*savePath); err != nil {
+			log.Fatalf("Error saving snapshot: %v", err)
+		}
+	}
+
+	var teams [2][]*battle.Pokemon
+	for i, side := range sides {
+		members, err := parseSide(s, side)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if teams[i], err = buildTeam(s, members, *level); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	t := simulate(teams, *n, *workers, *seed)
+	r := report(sides, teams, t, *n, *workers, *level, *seed)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	printReport(r)
+}
@@ -9,3 +9,5 @@ require (
 )
 
 require github.com/lib/pq v1.10.9
+
+require golang.org/x/sync v0.16.0
@@ -0,0 +1,86 @@
+package battle
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Abilities that power up moves of their type by 50% once the user is at or
+// below a third of its max HP, keyed by PokéAPI ability name
+var pinchAbilities = map[string]string{
+	"blaze":    "fire",
+	"torrent":  "water",
+	"overgrow": "grass",
+}
+
+// Damage multiplier the attacker's ability gives a move
+func abilityMultiplier(attacker *Pokemon, move Move) float64 {
+	if t, ok := pinchAbilities[attacker.Ability]; ok && t == move.Type && attacker.HP*3 <= attacker.Stats.HP {
+		return 1.5
+	}
+	return 1
+}
+
+// Levitate makes a pokemon immune to ground moves
+func immuneByAbility(defender *Pokemon, move Move) bool {
+	return defender.Ability == "levitate" && move.Type == "ground"
+}
+
+func levitate(defender *Pokemon) *Effect {
+	return &Effect{
+		Pokemon: defender,
+		Source:  "levitate",
+		Message: fmt.Sprintf("%s avoided the attack with levitate!", defender.Name),
+	}
+}
+
+// EntryAbility triggers the abilities that act when p comes out against foes,
+// like Intimidate lowering their Attack. Call it once when p enters battle,
+// nil foes are skipped.
+func EntryAbility(p *Pokemon, foes ...*Pokemon) []Effect {
+	var effects []Effect
+	for _, foe := range foes {
+		if foe == nil {
+			continue
+		}
+		if e := intimidate(p, foe); e != nil {
+			effects = append(effects, *e)
+		}
+	}
+	return effects
+}
+
+// ApplyEntryAbilities triggers the entry abilities of two pokemon coming out
+// at the same time. Call it once when the two meet.
+func ApplyEntryAbilities(a, b *Pokemon) []Effect {
+	return append(EntryAbility(a, b), EntryAbility(b, a)...)
+}
+
+func intimidate(user, foe *Pokemon) *Effect {
+	if user.Ability != "intimidate" || user.Fainted() || foe.Fainted() || foe.Stages.Attack <= minStage {
+		return nil
+	}
+	foe.Stages.Attack--
+	return &Effect{
+		Pokemon: foe,
+		Source:  "intimidate",
+		Message: fmt.Sprintf("%s's intimidate lowered %s's attack!", user.Name, foe.Name),
+	}
+}
+
+// Static has a 30% chance to paralyze a pokemon that hits it with a physical
+// move. Physical moves stand in for contact moves until those are tracked.
+func static(rng *rand.Rand, attacker, defender *Pokemon, move Move, damage int) *Effect {
+	if defender.Ability != "static" || move.DamageClass != Physical || damage <= 0 {
+		return nil
+	}
+	if attacker.Fainted() || attacker.Status != "" || attacker.hasType("electric") || rng.Intn(10) >= 3 {
+		return nil
+	}
+	attacker.Status = Paralysis
+	return &Effect{
+		Pokemon: attacker,
+		Source:  "static",
+		Message: fmt.Sprintf("%s's static paralyzed %s!", defender.Name, attacker.Name),
+	}
+}
@@ -0,0 +1,397 @@
+// Package battle holds the game rules for resolving fights: stats, damage and
+// turn order. It knows nothing about the database or HTTP so handlers, tools
+// and calculators can all share it.
+package battle
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Every pokemon battles at this level until leveling exists
+const DefaultLevel = 50
+
+const (
+	Physical = "physical"
+	Special  = "special"
+	Status   = "status"
+)
+
+// Status conditions, matching the names the status cure items use
+const Paralysis = "paralysis"
+
+// Stat stages run from -6 to +6, each one is half the stat again
+const (
+	minStage = -6
+	maxStage = 6
+)
+
+type Stats struct {
+	HP             int
+	Attack         int
+	Defense        int
+	SpecialAttack  int
+	SpecialDefense int
+	Speed          int
+}
+
+// CalcStats scales base stats to a level, with no IVs, EVs or nature
+func CalcStats(base Stats, level int) Stats {
+	stat := func(b int) int { return 2*b*level/100 + 5 }
+	return Stats{
+		HP:             2*base.HP*level/100 + level + 10,
+		Attack:         stat(base.Attack),
+		Defense:        stat(base.Defense),
+		SpecialAttack:  stat(base.SpecialAttack),
+		SpecialDefense: stat(base.SpecialDefense),
+		Speed:          stat(base.Speed),
+	}
+}
+
+// Scales a stat by its stage, +1 is 1.5x and -1 is 2/3
+func applyStage(stat, stage int) int {
+	stage = min(max(stage, minStage), maxStage)
+	if stage >= 0 {
+		return stat * (2 + stage) / 2
+	}
+	return stat * 2 / (2 - stage)
+}
+
+// MaxHP is the HP a pokemon with the given base HP has at full health
+func MaxHP(baseHP, level int) int {
+	return CalcStats(Stats{HP: baseHP}, level).HP
+}
+
+type Move struct {
+	ID          int32
+	Name        string
+	Type        string
+	Power       int
+	DamageClass string
+	Priority    int // higher priority moves go first regardless of speed
+	MinHits     int // 0 for moves that hit once
+	MaxHits     int
+	Target      string // PokéAPI target name, only used in double battles
+}
+
+type Pokemon struct {
+	Name     string
+	Types    []string
+	Level    int
+	Stats    Stats // level-scaled, Stats.HP is max HP
+	HP       int
+	Status   string
+	Moves    []Move
+	HeldItem string // PokéAPI item name, cleared when a single-use item is consumed
+	Ability  string // PokéAPI ability name
+	Stages   Stats  // stat stage changes for this battle, HP is unused
+
+	Charging   *Move // a move charged last turn, which must be used this turn
+	Recharging bool  // used a move like hyper-beam last turn and can't act this turn
+}
+
+// NewPokemon builds a pokemon at full HP from its species' base stats
+func NewPokemon(name string, types []string, base Stats, level int) *Pokemon {
+	stats := CalcStats(base, level)
+	return &Pokemon{
+		Name:  name,
+		Types: types,
+		Level: level,
+		Stats: stats,
+		HP:    stats.HP,
+	}
+}
+
+func (p *Pokemon) Fainted() bool {
+	return p.HP <= 0
+}
+
+// Speed after stages, paralysis halves it
+func (p *Pokemon) speed() int {
+	s := applyStage(p.Stats.Speed, p.Stages.Speed)
+	if p.Status == Paralysis {
+		s /= 2
+	}
+	return s
+}
+
+func (p *Pokemon) hasType(t string) bool {
+	for _, pt := range p.Types {
+		if pt == t {
+			return true
+		}
+	}
+	return false
+}
+
+type DamageResult struct {
+	Damage        int
+	Effectiveness float64
+	Critical      bool // any hit was a critical hit
+	STAB          bool
+	Hits          int // times a multi-hit move hit
+}
+
+// Damage rolls one hit of move from attacker to defender using the main
+// series formula, under the field's weather. Status moves deal no damage and
+// a nil field means clear weather.
+func Damage(rng *rand.Rand, field *Field, attacker, defender *Pokemon, move Move) DamageResult {
+	return damage(rng, field, attacker, defender, move, false)
+}
+
+// Damage, with spread moves that hit more than one pokemon doing less to each
+func damage(rng *rand.Rand, field *Field, attacker, defender *Pokemon, move Move, spread bool) DamageResult {
+	roll, crit := 85+rng.Intn(16), rng.Intn(24) == 0
+	return damageRoll(field, attacker, defender, move, spread, roll, crit)
+}
+
+// Damage with the random roll (85 to 100 percent) and critical hit decided
+func damageRoll(field *Field, attacker, defender *Pokemon, move Move, spread bool, roll int, crit bool) DamageResult {
+	res := DamageResult{Effectiveness: 1}
+	if move.Power <= 0 || move.DamageClass == Status {
+		return res
+	}
+	res.Effectiveness = Effectiveness(move.Type, defender.Types)
+	if immuneByAbility(defender, move) {
+		res.Effectiveness = 0
+	}
+	if res.Effectiveness == 0 {
+		return res
+	}
+	if field == nil {
+		field = &Field{}
+	}
+
+	atk := applyStage(attacker.Stats.Attack, attacker.Stages.Attack)
+	def := applyStage(defender.Stats.Defense, defender.Stages.Defense)
+	if move.DamageClass == Special {
+		atk = applyStage(attacker.Stats.SpecialAttack, attacker.Stages.SpecialAttack)
+		def = weatherSpecialDefense(field, defender, applyStage(defender.Stats.SpecialDefense, defender.Stages.SpecialDefense))
+	}
+
+	base := (2*attacker.Level/5+2)*move.Power*atk/def/50 + 2
+
+	mult := float64(roll) / 100
+	if crit {
+		res.Critical = true
+		mult *= 1.5
+	}
+	if attacker.hasType(move.Type) {
+		res.STAB = true
+		mult *= 1.5
+	}
+	mult *= res.Effectiveness
+	mult *= heldItemMultiplier(attacker, move)
+	mult *= abilityMultiplier(attacker, move)
+	mult *= weatherMultiplier(field, move)
+	if spread {
+		mult *= spreadMultiplier
+	}
+
+	res.Damage = max(int(float64(base)*mult), 1)
+	return res
+}
+
+// One pokemon using a move on another during a turn
+type Event struct {
+	Attacker *Pokemon
+	Defender *Pokemon
+	Move     Move
+	Result   DamageResult
+	Fainted  bool   // the defender fainted from this hit
+	Weather  string // the weather when the move was used
+	Spread   bool   // the move hit more than one pokemon, each gets its own event
+}
+
+// Something other than a move changing a pokemon's HP or state, like a held item
+type Effect struct {
+	Pokemon  *Pokemon // nil for effects on the whole field, like weather ending
+	Source   string   // what caused it, e.g. "leftovers"
+	HPChange int      // positive heals, negative damages
+	Message  string
+}
+
+type Turn struct {
+	Events  []Event  // moves in the order they were used
+	Effects []Effect // in the order they happened
+}
+
+// ResolveTurn has both pokemon use their move, higher priority moves first,
+// then the faster pokemon with speed ties broken randomly, and applies the
+// damage, held item, ability and weather effects, updating the field as it
+// goes. A nil move means that side spent its turn on something else, like
+// using an item. A pokemon that faints before its move doesn't get to use it,
+// and a paralyzed one has a 1 in 4 chance of not moving.
+//
+// A pokemon that charged a move last turn uses it whatever move it's given,
+// and one that must recharge does nothing. Charging and Recharging are left
+// set for the next turn.
+func ResolveTurn(rng *rand.Rand, field *Field, a, b *Pokemon, moveA, moveB *Move) Turn {
+	if field == nil {
+		field = &Field{}
+	}
+	// With one pokemon a side every move hits the other one
+	foe := func(p *Pokemon) func(Move) []*Pokemon {
+		return func(Move) []*Pokemon {
+			if p.Fainted() {
+				return nil
+			}
+			return []*Pokemon{p}
+		}
+	}
+	return resolve(rng, field, []actor{
+		{pokemon: a, move: moveA, targets: foe(b)},
+		{pokemon: b, move: moveB, targets: foe(a)},
+	})
+}
+
+// A pokemon taking its turn
+type actor struct {
+	pokemon *Pokemon
+	move    *Move
+	targets func(Move) []*Pokemon // who the move hits, worked out as it's used
+}
+
+// Plays out a turn for any number of pokemon, see ResolveTurn
+func resolve(rng *rand.Rand, field *Field, actors []actor) Turn {
+	for i := range actors {
+		if actors[i].pokemon.Charging != nil {
+			actors[i].move = actors[i].pokemon.Charging
+		}
+	}
+
+	// Shuffling first breaks speed ties randomly
+	rng.Shuffle(len(actors), func(i, j int) { actors[i], actors[j] = actors[j], actors[i] })
+	sort.SliceStable(actors, func(i, j int) bool {
+		pi, pj := priority(actors[i].move), priority(actors[j].move)
+		if pi != pj {
+			return pi > pj
+		}
+		return actors[i].pokemon.speed() > actors[j].pokemon.speed()
+	})
+
+	var t Turn
+	addEffect := func(e *Effect) {
+		if e != nil {
+			t.Effects = append(t.Effects, *e)
+		}
+	}
+	for _, act := range actors {
+		attacker := act.pokemon
+		if attacker.Fainted() {
+			continue
+		}
+		if attacker.Recharging {
+			attacker.Recharging = false
+			addEffect(&Effect{
+				Pokemon: attacker,
+				Source:  "recharge",
+				Message: fmt.Sprintf("%s must recharge!", attacker.Name),
+			})
+			continue
+		}
+		if act.move == nil {
+			continue
+		}
+		move := *act.move
+		targets := act.targets(move)
+		if len(targets) == 0 {
+			continue
+		}
+		if attacker.Status == Paralysis && rng.Intn(4) == 0 {
+			// Being fully paralyzed also wastes a charged move
+			attacker.Charging = nil
+			addEffect(&Effect{
+				Pokemon: attacker,
+				Source:  Paralysis,
+				Message: fmt.Sprintf("%s is paralyzed! It can't move!", attacker.Name),
+			})
+			continue
+		}
+		if attacker.Charging != nil {
+			attacker.Charging = nil
+		} else if needsCharge(field, move) {
+			addEffect(charge(attacker, move))
+			continue
+		}
+
+		if w, ok := weatherMoves[move.Name]; ok {
+			addEffect(setWeather(field, w, attacker, move.Name))
+		}
+
+		spread := len(targets) > 1
+		total := 0
+		for _, defender := range targets {
+			// Each hit of a multi-hit move rolls its own damage and crit,
+			// and stops early if the defender faints
+			var res DamageResult
+			hits := hitCount(rng, move)
+			for i := 0; i < hits && !defender.Fainted(); i++ {
+				hit := damage(rng, field, attacker, defender, move, spread)
+				if i == 0 {
+					res.Effectiveness, res.STAB = hit.Effectiveness, hit.STAB
+				}
+				var sash *Effect
+				hit.Damage, sash = focusSash(defender, hit.Damage)
+				addEffect(sash)
+				defender.HP = max(defender.HP-hit.Damage, 0)
+				res.Damage += hit.Damage
+				res.Critical = res.Critical || hit.Critical
+				res.Hits++
+				addEffect(static(rng, attacker, defender, move, hit.Damage))
+				if hit.Effectiveness == 0 {
+					break
+				}
+			}
+			if res.Effectiveness == 0 && immuneByAbility(defender, move) {
+				addEffect(levitate(defender))
+			}
+			t.Events = append(t.Events, Event{
+				Attacker: attacker,
+				Defender: defender,
+				Move:     move,
+				Result:   res,
+				Fainted:  defender.Fainted(),
+				Weather:  field.Weather,
+				Spread:   spread,
+			})
+			total += res.Damage
+		}
+		addEffect(lifeOrbRecoil(attacker, total))
+		if rechargeMoves[move.Name] && total > 0 {
+			attacker.Recharging = true
+		}
+	}
+
+	// End of turn, in the order the pokemon moved
+	for _, act := range actors {
+		addEffect(weatherChip(field, act.pokemon))
+	}
+	for _, act := range actors {
+		addEffect(leftovers(act.pokemon))
+	}
+	addEffect(weatherTick(field))
+	return t
+}
+
+// A side's move priority, spending the turn on something else counts as 0
+func priority(m *Move) int {
+	if m == nil {
+		return 0
+	}
+	return m.Priority
+}
+
+// CanFlee rolls whether runner gets away from foe in a wild battle using the
+// main series odds. A faster pokemon always escapes, a slower one has a better
+// chance the closer its speed and the more times it has tried. attempts
+// counts this one.
+func CanFlee(rng *rand.Rand, runner, foe *Pokemon, attempts int) bool {
+	a, b := runner.speed(), foe.speed()
+	if a > b || b == 0 {
+		return true
+	}
+	odds := a*128/b + 30*attempts
+	return odds > 255 || rng.Intn(256) < odds
+}
@@ -0,0 +1,269 @@
+package battle
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// A level 50 pokemon at full HP with 100 in every stat but HP
+func testPokemon(types ...string) *Pokemon {
+	stats := Stats{HP: 150, Attack: 100, Defense: 100, SpecialAttack: 100, SpecialDefense: 100, Speed: 100}
+	return &Pokemon{Name: "test", Types: types, Level: 50, Stats: stats, HP: stats.HP}
+}
+
+func TestCalcStats(t *testing.T) {
+	// Pikachu's base stats
+	got := CalcStats(Stats{HP: 35, Attack: 55, Defense: 40, SpecialAttack: 50, SpecialDefense: 50, Speed: 90}, 50)
+	want := Stats{HP: 95, Attack: 60, Defense: 45, SpecialAttack: 55, SpecialDefense: 55, Speed: 95}
+	if got != want {
+		t.Errorf("CalcStats(pikachu, 50) = %+v, want %+v", got, want)
+	}
+	if hp := MaxHP(35, 50); hp != 95 {
+		t.Errorf("MaxHP(35, 50) = %d, want 95", hp)
+	}
+}
+
+func TestApplyStage(t *testing.T) {
+	tests := []struct{ stage, want int }{
+		{0, 100},
+		{1, 150},
+		{2, 200},
+		{6, 400},
+		{-1, 66},
+		{-2, 50},
+		{-6, 25},
+		// Stages past +-6 are clamped
+		{8, 400},
+		{-8, 25},
+	}
+	for _, tt := range tests {
+		if got := applyStage(100, tt.stage); got != tt.want {
+			t.Errorf("applyStage(100, %d) = %d, want %d", tt.stage, got, tt.want)
+		}
+	}
+}
+
+func TestDamageRoll(t *testing.T) {
+	// With 100 attack against 100 defense a 80 power move does
+	// (2*50/5+2)*80*100/100/50+2 = 37 before modifiers
+	move := func(typ, class string) Move {
+		return Move{Name: "test-move", Type: typ, Power: 80, DamageClass: class}
+	}
+	tests := []struct {
+		name       string
+		field      *Field
+		attacker   func() *Pokemon
+		defender   func() *Pokemon
+		move       Move
+		roll       int
+		crit       bool
+		spread     bool
+		want       int
+		wantEffect float64
+		wantSTAB   bool
+	}{
+		{name: "neutral", move: move("water", Physical), want: 37, wantEffect: 1},
+		{name: "lowest roll", move: move("water", Physical), roll: 85, want: 31, wantEffect: 1},
+		{name: "critical hit", move: move("water", Physical), crit: true, want: 55, wantEffect: 1},
+		{
+			name:     "stab",
+			attacker: func() *Pokemon { return testPokemon("water") },
+			move:     move("water", Physical),
+			want:     55, wantEffect: 1, wantSTAB: true,
+		},
+		{
+			name:     "super effective",
+			defender: func() *Pokemon { return testPokemon("fire") },
+			move:     move("water", Physical),
+			want:     74, wantEffect: 2,
+		},
+		{
+			name:     "double resisted is at least 1",
+			defender: func() *Pokemon { return testPokemon("grass", "dragon") },
+			move:     Move{Type: "water", Power: 1, DamageClass: Physical},
+			roll:     85,
+			want:     1, wantEffect: 0.25,
+		},
+		{
+			name:     "immune",
+			defender: func() *Pokemon { return testPokemon("ghost") },
+			move:     move("normal", Physical),
+			want:     0, wantEffect: 0,
+		},
+		{name: "status move", move: move("water", Status), want: 0, wantEffect: 1},
+		{
+			name: "special uses special stats",
+			defender: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Stats.SpecialDefense = 50
+				return p
+			},
+			move: move("water", Special),
+			want: 72, wantEffect: 1,
+		},
+		{
+			name: "attack stage",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Stages.Attack = 1
+				return p
+			},
+			move: move("water", Physical),
+			want: 54, wantEffect: 1,
+		},
+		{
+			name: "intimidated",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Stages.Attack = -1
+				return p
+			},
+			move: move("water", Physical),
+			want: 25, wantEffect: 1,
+		},
+		{name: "spread", move: move("water", Physical), spread: true, want: 27, wantEffect: 1},
+		{name: "rain boosts water", field: &Field{Weather: Rain}, move: move("water", Physical), want: 55, wantEffect: 1},
+		{name: "rain weakens fire", field: &Field{Weather: Rain}, move: move("fire", Physical), want: 18, wantEffect: 1},
+		{name: "sun boosts fire", field: &Field{Weather: Sun}, move: move("fire", Physical), want: 55, wantEffect: 1},
+		{name: "sun weakens water", field: &Field{Weather: Sun}, move: move("water", Physical), want: 18, wantEffect: 1},
+		{name: "hail leaves damage alone", field: &Field{Weather: Hail}, move: move("water", Physical), want: 37, wantEffect: 1},
+		{
+			name:     "sandstorm raises rock special defense",
+			field:    &Field{Weather: Sandstorm},
+			defender: func() *Pokemon { return testPokemon("rock") },
+			move:     move("electric", Special),
+			want:     25, wantEffect: 1,
+		},
+		{
+			name:     "sandstorm leaves rock defense alone",
+			field:    &Field{Weather: Sandstorm},
+			defender: func() *Pokemon { return testPokemon("rock") },
+			move:     move("electric", Physical),
+			want:     37, wantEffect: 1,
+		},
+		{
+			name: "type boosting item",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.HeldItem = "charcoal"
+				return p
+			},
+			move: move("fire", Physical),
+			want: 44, wantEffect: 1,
+		},
+		{
+			name: "type boosting item of another type",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.HeldItem = "charcoal"
+				return p
+			},
+			move: move("water", Physical),
+			want: 37, wantEffect: 1,
+		},
+		{
+			name: "life orb",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.HeldItem = "life-orb"
+				return p
+			},
+			move: move("water", Physical),
+			want: 48, wantEffect: 1,
+		},
+		{
+			name: "blaze in a pinch",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Ability, p.HP = "blaze", 50
+				return p
+			},
+			move: move("fire", Physical),
+			want: 55, wantEffect: 1,
+		},
+		{
+			name: "blaze above a third of its hp",
+			attacker: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Ability, p.HP = "blaze", 51
+				return p
+			},
+			move: move("fire", Physical),
+			want: 37, wantEffect: 1,
+		},
+		{
+			name: "levitate",
+			defender: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Ability = "levitate"
+				return p
+			},
+			move: move("ground", Physical),
+			want: 0, wantEffect: 0,
+		},
+	}
+	for _, tt := range tests {
+		attacker, defender := testPokemon("normal"), testPokemon("normal")
+		if tt.attacker != nil {
+			attacker = tt.attacker()
+		}
+		if tt.defender != nil {
+			defender = tt.defender()
+		}
+		roll := tt.roll
+		if roll == 0 {
+			roll = 100
+		}
+		res := damageRoll(tt.field, attacker, defender, tt.move, tt.spread, roll, tt.crit)
+		if res.Damage != tt.want || res.Effectiveness != tt.wantEffect || res.STAB != tt.wantSTAB {
+			t.Errorf("%s: damage %d, effectiveness %v, stab %v, want %d, %v, %v",
+				tt.name, res.Damage, res.Effectiveness, res.STAB, tt.want, tt.wantEffect, tt.wantSTAB)
+		}
+	}
+}
+
+func TestDamageRange(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	move := Move{Type: "water", Power: 80, DamageClass: Physical}
+	for i := 0; i < 1000; i++ {
+		res := Damage(rng, nil, testPokemon("normal"), testPokemon("normal"), move)
+		// 85% of 37 up to a critical hit
+		if res.Damage < 31 || res.Damage > 55 {
+			t.Fatalf("Damage = %d, want 31 to 55", res.Damage)
+		}
+		if !res.Critical && res.Damage > 37 {
+			t.Fatalf("Damage = %d without a critical hit, want at most 37", res.Damage)
+		}
+	}
+}
+
+func TestCanFlee(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	fast, slow := testPokemon("normal"), testPokemon("normal")
+	slow.Stats.Speed = 50
+	if !CanFlee(rng, fast, slow, 1) {
+		t.Error("a faster pokemon couldn't flee")
+	}
+
+	// 50*128/100 + 30*attempts is past 255 by the fifth try
+	if !CanFlee(rng, slow, fast, 5) {
+		t.Error("a slower pokemon couldn't flee on its fifth try")
+	}
+	escaped := 0
+	for i := 0; i < 10000; i++ {
+		if CanFlee(rng, slow, fast, 1) {
+			escaped++
+		}
+	}
+	// (64+30)/256 is about 37%
+	if escaped < 3300 || escaped > 4100 {
+		t.Errorf("a slower pokemon fled %d times in 10000 first tries, want about 3670", escaped)
+	}
+
+	// Paralysis halves speed, so a paralyzed pokemon can be outrun
+	slow.Stats.Speed = 60
+	fast.Status = Paralysis
+	if !CanFlee(rng, slow, fast, 1) {
+		t.Error("couldn't flee from a paralyzed pokemon")
+	}
+}
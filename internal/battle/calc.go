@@ -0,0 +1,84 @@
+package battle
+
+// Calculation is every possible outcome of one use of a move, for damage
+// calculators that don't want to run a battle
+type Calculation struct {
+	Effectiveness    float64
+	STAB             bool
+	Min, Max         int     // total damage without critical hits, across rolls and hit counts
+	CritMin, CritMax int     // total damage when every hit is critical
+	MinHits, MaxHits int     // 1 for moves that hit once
+	KOChance         float64 // chance of knocking the defender out from its current HP
+}
+
+// Chance a single hit is critical, see Damage
+const critChance = 1.0 / 24
+
+// Calculate works out the damage range and knock out chance of attacker using
+// move on defender, with the same formula, abilities, held items and weather
+// as a battle. A focus-sash on a defender at full HP is taken into account,
+// other effects that happen after the hit are not.
+func Calculate(field *Field, attacker, defender *Pokemon, move Move) Calculation {
+	if field == nil {
+		field = &Field{}
+	}
+	hits := hitDistribution(move)
+	var calc Calculation
+	for h := range hits {
+		if calc.MinHits == 0 || h < calc.MinHits {
+			calc.MinHits = h
+		}
+		calc.MaxHits = max(calc.MaxHits, h)
+	}
+
+	// Every roll and crit outcome of a single hit, with its chance
+	perHit := map[int]float64{}
+	lo, hi, critLo, critHi := 0, 0, 0, 0
+	for roll := 85; roll <= 100; roll++ {
+		for _, crit := range []bool{false, true} {
+			res := damageRoll(field, attacker, defender, move, false, roll, crit)
+			calc.Effectiveness, calc.STAB = res.Effectiveness, res.STAB
+			chance := (1 - critChance) / 16
+			if crit {
+				chance = critChance / 16
+				if roll == 85 {
+					critLo = res.Damage
+				}
+				critHi = res.Damage
+			} else {
+				if roll == 85 {
+					lo = res.Damage
+				}
+				hi = res.Damage
+			}
+			perHit[res.Damage] += chance
+		}
+	}
+	calc.Min, calc.Max = lo*calc.MinHits, hi*calc.MaxHits
+	calc.CritMin, calc.CritMax = critLo*calc.MinHits, critHi*calc.MaxHits
+
+	// Add up the hits one at a time. A focus-sash stops the first hit from
+	// knocking out a defender at full HP
+	sash := defender.HeldItem == "focus-sash" && defender.HP == defender.Stats.HP
+	total := map[int]float64{0: 1}
+	for n := 1; n <= calc.MaxHits; n++ {
+		next := map[int]float64{}
+		for sum, p := range total {
+			for d, q := range perHit {
+				if n == 1 && sash && d >= defender.HP {
+					d = defender.HP - 1
+				}
+				next[sum+d] += p * q
+			}
+		}
+		total = next
+		if chance, ok := hits[n]; ok {
+			for sum, p := range total {
+				if sum >= defender.HP {
+					calc.KOChance += chance * p
+				}
+			}
+		}
+	}
+	return calc
+}
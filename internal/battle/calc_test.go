@@ -0,0 +1,154 @@
+package battle
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	tackle := Move{Type: "water", Power: 80, DamageClass: Physical}
+	doubleHit := Move{Type: "water", Power: 80, DamageClass: Physical, MinHits: 2, MaxHits: 2}
+	multiHit := Move{Type: "water", Power: 80, DamageClass: Physical, MinHits: 2, MaxHits: 5}
+
+	// 31 to 37 a hit, 47 to 55 on a critical hit, see TestDamageRoll
+	tests := []struct {
+		name     string
+		move     Move
+		defender func() *Pokemon
+		want     Calculation
+	}{
+		{
+			name: "can't knock out",
+			move: tackle,
+			want: Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1},
+		},
+		{
+			name:     "every roll knocks out",
+			move:     tackle,
+			defender: withHP(31),
+			want:     Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1, KOChance: 1},
+		},
+		{
+			// 14 of 16 rolls without a crit, or any crit
+			name:     "most rolls knock out",
+			move:     tackle,
+			defender: withHP(32),
+			want:     Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1, KOChance: 14.0/16*(1-critChance) + critChance},
+		},
+		{
+			name:     "only a crit knocks out",
+			move:     tackle,
+			defender: withHP(38),
+			want:     Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1, KOChance: critChance},
+		},
+		{
+			name:     "too much hp even for a crit",
+			move:     tackle,
+			defender: withHP(56),
+			want:     Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1},
+		},
+		{
+			name: "super effective",
+			move: tackle,
+			defender: func() *Pokemon {
+				return testPokemon("fire")
+			},
+			want: Calculation{Effectiveness: 2, Min: 62, Max: 74, CritMin: 94, CritMax: 111, MinHits: 1, MaxHits: 1},
+		},
+		{
+			name:     "immune",
+			move:     Move{Type: "normal", Power: 80, DamageClass: Physical},
+			defender: func() *Pokemon { return testPokemon("ghost") },
+			want:     Calculation{MinHits: 1, MaxHits: 1},
+		},
+		{
+			name: "focus sash",
+			move: tackle,
+			defender: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Stats.HP, p.HP, p.HeldItem = 31, 31, "focus-sash"
+				return p
+			},
+			want: Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1},
+		},
+		{
+			name: "focus sash against a second hit",
+			move: doubleHit,
+			defender: func() *Pokemon {
+				p := testPokemon("normal")
+				p.Stats.HP, p.HP, p.HeldItem = 31, 31, "focus-sash"
+				return p
+			},
+			want: Calculation{Effectiveness: 1, Min: 62, Max: 74, CritMin: 94, CritMax: 110, MinHits: 2, MaxHits: 2, KOChance: 1},
+		},
+		{
+			name:     "focus sash below full hp",
+			move:     tackle,
+			defender: withHP(31, "focus-sash"),
+			want:     Calculation{Effectiveness: 1, Min: 31, Max: 37, CritMin: 47, CritMax: 55, MinHits: 1, MaxHits: 1, KOChance: 1},
+		},
+		{
+			name:     "two hits knock out",
+			move:     doubleHit,
+			defender: withHP(62),
+			want:     Calculation{Effectiveness: 1, Min: 62, Max: 74, CritMin: 94, CritMax: 110, MinHits: 2, MaxHits: 2, KOChance: 1},
+		},
+		{
+			// Three or more hits always knock out, two only when both crit
+			name:     "2 to 5 hits",
+			move:     multiHit,
+			defender: withHP(93),
+			want:     Calculation{Effectiveness: 1, Min: 62, Max: 185, CritMin: 94, CritMax: 275, MinHits: 2, MaxHits: 5, KOChance: 0.65 + 0.35*critChance*critChance},
+		},
+	}
+	for _, tt := range tests {
+		defender := testPokemon("normal")
+		if tt.defender != nil {
+			defender = tt.defender()
+		}
+		got := Calculate(nil, testPokemon("normal"), defender, tt.move)
+		if math.Abs(got.KOChance-tt.want.KOChance) > 1e-9 {
+			t.Errorf("%s: KOChance = %v, want %v", tt.name, got.KOChance, tt.want.KOChance)
+		}
+		got.KOChance, tt.want.KOChance = 0, 0
+		if got != tt.want {
+			t.Errorf("%s: Calculate = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A defender at hp of its 150 max HP holding item, if any
+func withHP(hp int, item ...string) func() *Pokemon {
+	return func() *Pokemon {
+		p := testPokemon("normal")
+		p.HP = hp
+		if len(item) > 0 {
+			p.HeldItem = item[0]
+		}
+		return p
+	}
+}
+
+func TestHitDistribution(t *testing.T) {
+	tests := []struct {
+		min, max int
+		want     map[int]float64
+	}{
+		{0, 0, map[int]float64{1: 1}},
+		{2, 2, map[int]float64{2: 1}},
+		{2, 5, map[int]float64{2: 0.35, 3: 0.35, 4: 0.15, 5: 0.15}},
+	}
+	for _, tt := range tests {
+		got := hitDistribution(Move{MinHits: tt.min, MaxHits: tt.max})
+		if len(got) != len(tt.want) {
+			t.Errorf("hitDistribution(%d-%d) = %v, want %v", tt.min, tt.max, got, tt.want)
+			continue
+		}
+		for h, p := range tt.want {
+			if math.Abs(got[h]-p) > 1e-9 {
+				t.Errorf("hitDistribution(%d-%d) = %v, want %v", tt.min, tt.max, got, tt.want)
+				break
+			}
+		}
+	}
+}
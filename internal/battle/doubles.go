@@ -0,0 +1,148 @@
+package battle
+
+import "math/rand"
+
+// Move targets that matter in a double battle, by PokéAPI target name. Any
+// other target, including none, is treated as TargetSelected.
+const (
+	TargetSelected  = "selected-pokemon"  // one pokemon the user picks, foe or ally
+	TargetRandomFoe = "random-opponent"   // one foe picked at random, like outrage
+	TargetAllFoes   = "all-opponents"     // both foes, like rock-slide
+	TargetAllOthers = "all-other-pokemon" // everyone else including the ally, like earthquake
+)
+
+// Moves that hit more than one pokemon do 75% damage to each
+const spreadMultiplier = 0.75
+
+// Sides of a double battle
+const (
+	UserSide       = 0
+	ChallengerSide = 1
+)
+
+// Slot is a place on the field in a double battle
+type Slot struct {
+	Side     int // UserSide or ChallengerSide
+	Position int // 0 for the lead, 1 for its partner
+}
+
+// Ally is the other slot on the same side
+func (s Slot) Ally() Slot {
+	return Slot{Side: s.Side, Position: 1 - s.Position}
+}
+
+// DoubleField is a double battle, two pokemon a side. Empty slots are nil.
+type DoubleField struct {
+	Field
+	Slots [2][2]*Pokemon
+}
+
+// At returns the pokemon in a slot, nil if it's empty
+func (f *DoubleField) At(s Slot) *Pokemon {
+	if s.Side < 0 || s.Side > 1 || s.Position < 0 || s.Position > 1 {
+		return nil
+	}
+	return f.Slots[s.Side][s.Position]
+}
+
+// Pokemon on a side that can still fight
+func (f *DoubleField) alive(side int) []*Pokemon {
+	var out []*Pokemon
+	for _, p := range f.Slots[side] {
+		if p != nil && !p.Fainted() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// SideFainted reports whether every pokemon on a side has fainted
+func (f *DoubleField) SideFainted(side int) bool {
+	return len(f.alive(side)) == 0
+}
+
+// SlotOf finds the slot a pokemon is in
+func (f *DoubleField) SlotOf(p *Pokemon) (Slot, bool) {
+	for side := range f.Slots {
+		for pos, q := range f.Slots[side] {
+			if q != nil && q == p {
+				return Slot{Side: side, Position: pos}, true
+			}
+		}
+	}
+	return Slot{}, false
+}
+
+// Action is what the pokemon in a slot does this turn
+type Action struct {
+	Slot   Slot
+	Move   *Move // nil when it spends the turn on something else
+	Target Slot  // the pokemon a TargetSelected move is aimed at
+}
+
+// ResolveDoublesTurn plays a turn of a double battle. It follows the same
+// rules as ResolveTurn with every pokemon that has an action taking part, in
+// priority then speed order.
+//
+// Moves aimed at a foe that has already fainted hit the other foe instead,
+// one aimed at a fainted ally fails. Spread moves hit every target they can
+// at 75% damage each, with an event per target.
+func ResolveDoublesTurn(rng *rand.Rand, f *DoubleField, actions []Action) Turn {
+	actors := make([]actor, 0, len(actions))
+	for _, a := range actions {
+		p := f.At(a.Slot)
+		if p == nil {
+			continue
+		}
+		a := a
+		actors = append(actors, actor{
+			pokemon: p,
+			move:    a.Move,
+			targets: func(move Move) []*Pokemon { return f.targets(rng, a.Slot, a.Target, move) },
+		})
+	}
+	return resolve(rng, &f.Field, actors)
+}
+
+// Who a move used from a slot hits
+func (f *DoubleField) targets(rng *rand.Rand, user, chosen Slot, move Move) []*Pokemon {
+	foes := f.alive(1 - user.Side)
+	switch move.Target {
+	case TargetAllFoes:
+		return foes
+	case TargetAllOthers:
+		if ally := f.At(user.Ally()); ally != nil && !ally.Fainted() {
+			return append(foes, ally)
+		}
+		return foes
+	case TargetRandomFoe:
+		if len(foes) == 0 {
+			return nil
+		}
+		return []*Pokemon{foes[rng.Intn(len(foes))]}
+	}
+
+	if chosen != user {
+		if t := f.At(chosen); t != nil && !t.Fainted() {
+			return []*Pokemon{t}
+		}
+	}
+	if chosen.Side == user.Side && chosen != user {
+		return nil
+	}
+	if len(foes) == 0 {
+		return nil
+	}
+	return foes[:1]
+}
+
+// EntryAbility triggers the entry abilities of the pokemon in s against both
+// foes, Intimidate lowers the Attack of each. Call it once when it comes out.
+func (f *DoubleField) EntryAbility(s Slot) []Effect {
+	p := f.At(s)
+	if p == nil {
+		return nil
+	}
+	foes := f.Slots[1-s.Side]
+	return EntryAbility(p, foes[0], foes[1])
+}
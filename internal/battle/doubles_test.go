@@ -0,0 +1,148 @@
+package battle
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// A double battle with test pokemon named after their slots
+func testDoubleField() *DoubleField {
+	f := &DoubleField{}
+	for side, names := range [2][2]string{{"user-lead", "user-partner"}, {"foe-lead", "foe-partner"}} {
+		for pos, name := range names {
+			p := testPokemon("normal")
+			p.Name = name
+			f.Slots[side][pos] = p
+		}
+	}
+	return f
+}
+
+func TestDoubleFieldTargets(t *testing.T) {
+	lead := Slot{Side: UserSide, Position: 0}
+	partner := lead.Ally()
+	foeLead := Slot{Side: ChallengerSide, Position: 0}
+	foePartner := foeLead.Ally()
+
+	tests := []struct {
+		name    string
+		target  string
+		chosen  Slot
+		fainted []Slot
+		want    []string
+	}{
+		{name: "selected foe", chosen: foePartner, want: []string{"foe-partner"}},
+		{name: "selected foe that fainted hits the other foe", chosen: foeLead, fainted: []Slot{foeLead}, want: []string{"foe-partner"}},
+		{name: "selected foe with both fainted", chosen: foeLead, fainted: []Slot{foeLead, foePartner}},
+		{name: "selected ally", chosen: partner, want: []string{"user-partner"}},
+		{name: "selected ally that fainted fails", chosen: partner, fainted: []Slot{partner}},
+		{name: "no target picks the first foe", chosen: lead, want: []string{"foe-lead"}},
+		{name: "unknown target is selected", target: "user", chosen: foePartner, want: []string{"foe-partner"}},
+		{name: "all foes", target: TargetAllFoes, want: []string{"foe-lead", "foe-partner"}},
+		{name: "all foes with one fainted", target: TargetAllFoes, fainted: []Slot{foeLead}, want: []string{"foe-partner"}},
+		{name: "all others", target: TargetAllOthers, want: []string{"foe-lead", "foe-partner", "user-partner"}},
+		{name: "all others with the ally fainted", target: TargetAllOthers, fainted: []Slot{partner}, want: []string{"foe-lead", "foe-partner"}},
+		{name: "all others with a foe fainted", target: TargetAllOthers, fainted: []Slot{foePartner}, want: []string{"foe-lead", "user-partner"}},
+		{name: "random foe with one fainted", target: TargetRandomFoe, fainted: []Slot{foePartner}, want: []string{"foe-lead"}},
+		{name: "random foe with both fainted", target: TargetRandomFoe, fainted: []Slot{foeLead, foePartner}},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		f := testDoubleField()
+		for _, s := range tt.fainted {
+			f.At(s).HP = 0
+		}
+		got := f.targets(rng, lead, tt.chosen, Move{Target: tt.target})
+		var names []string
+		for _, p := range got {
+			names = append(names, p.Name)
+		}
+		if len(names) != len(tt.want) {
+			t.Errorf("%s: targets = %v, want %v", tt.name, names, tt.want)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.want[i] {
+				t.Errorf("%s: targets = %v, want %v", tt.name, names, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestDoubleFieldRandomFoe(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	f := testDoubleField()
+	hit := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		for _, p := range f.targets(rng, Slot{Side: UserSide}, Slot{}, Move{Target: TargetRandomFoe}) {
+			hit[p.Name]++
+		}
+	}
+	if hit["foe-lead"] < 400 || hit["foe-partner"] < 400 || len(hit) != 2 {
+		t.Errorf("random foe hit %v in 1000 moves, want about 500 each foe", hit)
+	}
+}
+
+func TestResolveDoublesTurnSpread(t *testing.T) {
+	rockSlide := &Move{Name: "rock-slide", Type: "rock", Power: 80, DamageClass: Physical, Target: TargetAllFoes}
+	tests := []struct {
+		name       string
+		foeFainted bool
+		wantHits   int
+		wantSpread bool
+		maxDamage  int // most a hit can do without a critical hit
+	}{
+		// 37 a hit against one target, 75% of that against two
+		{name: "both foes", wantHits: 2, wantSpread: true, maxDamage: 27},
+		{name: "one foe left", foeFainted: true, wantHits: 1, wantSpread: false, maxDamage: 37},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			f := testDoubleField()
+			if tt.foeFainted {
+				f.Slots[ChallengerSide][1].HP = 0
+			}
+			turn := ResolveDoublesTurn(rng, f, []Action{{Slot: Slot{Side: UserSide}, Move: rockSlide}})
+			if len(turn.Events) != tt.wantHits {
+				t.Fatalf("%s: %d events, want %d", tt.name, len(turn.Events), tt.wantHits)
+			}
+			for _, ev := range turn.Events {
+				if ev.Spread != tt.wantSpread {
+					t.Errorf("%s: event spread = %v, want %v", tt.name, ev.Spread, tt.wantSpread)
+				}
+				if !ev.Result.Critical && ev.Result.Damage > tt.maxDamage {
+					t.Errorf("%s: hit %s for %d, want at most %d", tt.name, ev.Defender.Name, ev.Result.Damage, tt.maxDamage)
+				}
+			}
+		}
+	}
+}
+
+func TestResolveDoublesTurnRetarget(t *testing.T) {
+	tackle := &Move{Name: "tackle", Type: "normal", Power: 40, DamageClass: Physical}
+	foeLead := Slot{Side: ChallengerSide, Position: 0}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		f := testDoubleField()
+		// The faster lead knocks out the foe both of them aimed at, so the
+		// partner's move goes to the other foe
+		f.Slots[UserSide][0].Stats.Speed = 200
+		f.At(foeLead).HP = 1
+		turn := ResolveDoublesTurn(rng, f, []Action{
+			{Slot: Slot{Side: UserSide, Position: 1}, Move: tackle, Target: foeLead},
+			{Slot: Slot{Side: UserSide, Position: 0}, Move: tackle, Target: foeLead},
+		})
+		if len(turn.Events) != 2 {
+			t.Fatalf("%d events, want 2", len(turn.Events))
+		}
+		first, second := turn.Events[0], turn.Events[1]
+		if first.Defender.Name != "foe-lead" || !first.Fainted {
+			t.Fatalf("first move hit %s (fainted %v), want foe-lead to faint", first.Defender.Name, first.Fainted)
+		}
+		if second.Attacker.Name != "user-partner" || second.Defender.Name != "foe-partner" {
+			t.Fatalf("second move was %s hitting %s, want user-partner hitting foe-partner", second.Attacker.Name, second.Defender.Name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package battle
+
+import "fmt"
+
+// Held items that boost moves of one type by 20%, keyed by PokéAPI item name
+var typeBoostItems = map[string]string{
+	"silk-scarf":     "normal",
+	"charcoal":       "fire",
+	"mystic-water":   "water",
+	"magnet":         "electric",
+	"miracle-seed":   "grass",
+	"never-melt-ice": "ice",
+	"black-belt":     "fighting",
+	"poison-barb":    "poison",
+	"soft-sand":      "ground",
+	"sharp-beak":     "flying",
+	"twisted-spoon":  "psychic",
+	"silver-powder":  "bug",
+	"hard-stone":     "rock",
+	"spell-tag":      "ghost",
+	"dragon-fang":    "dragon",
+	"black-glasses":  "dark",
+	"metal-coat":     "steel",
+	"fairy-feather":  "fairy",
+}
+
+// Damage multiplier the attacker's held item gives a move
+func heldItemMultiplier(attacker *Pokemon, move Move) float64 {
+	if t, ok := typeBoostItems[attacker.HeldItem]; ok && t == move.Type {
+		return 1.2
+	}
+	if attacker.HeldItem == "life-orb" {
+		return 1.3
+	}
+	return 1
+}
+
+// Focus Sash lets a pokemon at full HP survive a hit that would knock it out, once
+func focusSash(defender *Pokemon, damage int) (int, *Effect) {
+	if defender.HeldItem != "focus-sash" || defender.HP != defender.Stats.HP || damage < defender.HP {
+		return damage, nil
+	}
+	defender.HeldItem = ""
+	return defender.HP - 1, &Effect{
+		Pokemon: defender,
+		Source:  "focus-sash",
+		Message: fmt.Sprintf("%s hung on using its focus-sash!", defender.Name),
+	}
+}
+
+// Life Orb costs the attacker a tenth of its max HP each time it deals damage
+func lifeOrbRecoil(attacker *Pokemon, damage int) *Effect {
+	if attacker.HeldItem != "life-orb" || damage <= 0 || attacker.Fainted() {
+		return nil
+	}
+	recoil := max(attacker.Stats.HP/10, 1)
+	attacker.HP = max(attacker.HP-recoil, 0)
+	return &Effect{
+		Pokemon:  attacker,
+		Source:   "life-orb",
+		HPChange: -recoil,
+		Message:  fmt.Sprintf("%s lost some of its HP to its life-orb!", attacker.Name),
+	}
+}
+
+// Leftovers restores a sixteenth of max HP at the end of every turn
+func leftovers(p *Pokemon) *Effect {
+	if p.HeldItem != "leftovers" || p.Fainted() || p.HP == p.Stats.HP {
+		return nil
+	}
+	heal := min(max(p.Stats.HP/16, 1), p.Stats.HP-p.HP)
+	p.HP += heal
+	return &Effect{
+		Pokemon:  p,
+		Source:   "leftovers",
+		HPChange: heal,
+		Message:  fmt.Sprintf("%s restored a little HP using its leftovers!", p.Name),
+	}
+}
@@ -0,0 +1,81 @@
+package battle
+
+import "testing"
+
+func TestFocusSash(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   string
+		hp     int
+		damage int
+		want   int
+		used   bool
+	}{
+		{"survives a knockout", "focus-sash", 150, 200, 149, true},
+		{"survives exact damage", "focus-sash", 150, 150, 149, true},
+		{"doesn't change a hit it survives", "focus-sash", 150, 100, 100, false},
+		{"only works at full hp", "focus-sash", 149, 200, 200, false},
+		{"without the item", "leftovers", 150, 200, 200, false},
+	}
+	for _, tt := range tests {
+		p := testPokemon("normal")
+		p.HeldItem, p.HP = tt.item, tt.hp
+		got, e := focusSash(p, tt.damage)
+		if got != tt.want {
+			t.Errorf("%s: focusSash = %d, want %d", tt.name, got, tt.want)
+		}
+		// The sash is used up once it saves its pokemon
+		if (e != nil) != tt.used || (p.HeldItem == "") != tt.used {
+			t.Errorf("%s: focusSash effect %+v left held item %q, want used %v", tt.name, e, p.HeldItem, tt.used)
+		}
+	}
+}
+
+func TestLifeOrbRecoil(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   string
+		hp     int
+		damage int
+		wantHP int
+	}{
+		{"costs a tenth of max hp", "life-orb", 150, 30, 135},
+		{"can knock its holder out", "life-orb", 10, 30, 0},
+		{"nothing when the move missed", "life-orb", 150, 0, 150},
+		{"without the item", "charcoal", 150, 30, 150},
+	}
+	for _, tt := range tests {
+		p := testPokemon("normal")
+		p.HeldItem, p.HP = tt.item, tt.hp
+		lifeOrbRecoil(p, tt.damage)
+		if p.HP != tt.wantHP {
+			t.Errorf("%s: HP after life-orb recoil = %d, want %d", tt.name, p.HP, tt.wantHP)
+		}
+	}
+}
+
+func TestLeftovers(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   string
+		hp     int
+		wantHP int
+	}{
+		{"restores a sixteenth of max hp", "leftovers", 100, 109},
+		{"doesn't heal past max hp", "leftovers", 145, 150},
+		{"nothing at full hp", "leftovers", 150, 150},
+		{"nothing once fainted", "leftovers", 0, 0},
+		{"without the item", "life-orb", 100, 100},
+	}
+	for _, tt := range tests {
+		p := testPokemon("normal")
+		p.HeldItem, p.HP = tt.item, tt.hp
+		e := leftovers(p)
+		if p.HP != tt.wantHP {
+			t.Errorf("%s: HP after leftovers = %d, want %d", tt.name, p.HP, tt.wantHP)
+		}
+		if e != nil && e.HPChange != tt.wantHP-tt.hp {
+			t.Errorf("%s: leftovers effect HPChange = %d, want %d", tt.name, e.HPChange, tt.wantHP-tt.hp)
+		}
+	}
+}
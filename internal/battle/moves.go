@@ -0,0 +1,95 @@
+package battle
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Moves that spend a turn charging before they hit, keyed by PokéAPI move
+// name, with the message shown while charging. Semi-invulnerable moves like
+// fly and dig just charge, the user can still be hit in between.
+var chargeMoves = map[string]string{
+	"solar-beam":    "%s absorbed light!",
+	"solar-blade":   "%s absorbed light!",
+	"sky-attack":    "%s became cloaked in a harsh light!",
+	"razor-wind":    "%s whipped up a whirlwind!",
+	"skull-bash":    "%s tucked in its head!",
+	"meteor-beam":   "%s is overflowing with space power!",
+	"freeze-shock":  "%s became cloaked in a freezing light!",
+	"ice-burn":      "%s became cloaked in freezing air!",
+	"fly":           "%s flew up high!",
+	"bounce":        "%s sprang up!",
+	"dig":           "%s burrowed its way under the ground!",
+	"dive":          "%s hid underwater!",
+	"phantom-force": "%s vanished instantly!",
+	"shadow-force":  "%s vanished instantly!",
+}
+
+// Moves that leave the user needing a turn to recharge after they hit
+var rechargeMoves = map[string]bool{
+	"hyper-beam":      true,
+	"giga-impact":     true,
+	"blast-burn":      true,
+	"hydro-cannon":    true,
+	"frenzy-plant":    true,
+	"rock-wrecker":    true,
+	"roar-of-time":    true,
+	"prismatic-laser": true,
+	"eternabeam":      true,
+	"meteor-assault":  true,
+}
+
+// Whether a move needs a charging turn, solar moves skip it in harsh sunlight
+func needsCharge(f *Field, move Move) bool {
+	if _, ok := chargeMoves[move.Name]; !ok {
+		return false
+	}
+	return !(f.Weather == Sun && (move.Name == "solar-beam" || move.Name == "solar-blade"))
+}
+
+// Starts charging a move, the pokemon uses it automatically next turn
+func charge(p *Pokemon, move Move) *Effect {
+	p.Charging = &move
+	return &Effect{
+		Pokemon: p,
+		Source:  move.Name,
+		Message: fmt.Sprintf(chargeMoves[move.Name], p.Name),
+	}
+}
+
+// How many times a move hits. 2-5 hit moves use the main series odds:
+// 2 and 3 hits 35% each, 4 and 5 hits 15% each.
+func hitCount(rng *rand.Rand, move Move) int {
+	if move.MaxHits <= 1 || move.MaxHits < move.MinHits {
+		return 1
+	}
+	if move.MinHits == 2 && move.MaxHits == 5 {
+		switch roll := rng.Intn(20); {
+		case roll < 7:
+			return 2
+		case roll < 14:
+			return 3
+		case roll < 17:
+			return 4
+		default:
+			return 5
+		}
+	}
+	return move.MinHits + rng.Intn(move.MaxHits-move.MinHits+1)
+}
+
+// The chance of each hit count for a move, matching hitCount
+func hitDistribution(move Move) map[int]float64 {
+	if move.MaxHits <= 1 || move.MaxHits < move.MinHits {
+		return map[int]float64{1: 1}
+	}
+	if move.MinHits == 2 && move.MaxHits == 5 {
+		return map[int]float64{2: 0.35, 3: 0.35, 4: 0.15, 5: 0.15}
+	}
+	dist := map[int]float64{}
+	n := move.MaxHits - move.MinHits + 1
+	for h := move.MinHits; h <= move.MaxHits; h++ {
+		dist[h] = 1 / float64(n)
+	}
+	return dist
+}
@@ -0,0 +1,100 @@
+package battle
+
+import "math/rand"
+
+// MaxTurns ends a headless battle as a draw, for matchups where neither side
+// can knock the other out, like two pokemon immune to each other's moves
+const MaxTurns = 500
+
+// ChooseMove is the challenger AI, a random move from those the pokemon
+// knows. It returns nil for a pokemon with no moves.
+func ChooseMove(rng *rand.Rand, p *Pokemon) *Move {
+	if len(p.Moves) == 0 {
+		return nil
+	}
+	m := p.Moves[rng.Intn(len(p.Moves))]
+	return &m
+}
+
+// Clone copies a pokemon so it can fight without changing the original.
+// Types and Moves are shared, the engine never changes them.
+func (p *Pokemon) Clone() *Pokemon {
+	c := *p
+	c.Charging = nil
+	c.Recharging = false
+	return &c
+}
+
+// Outcome is how a headless battle went
+type Outcome struct {
+	Winner   int // UserSide or ChallengerSide, -1 for a draw
+	Turns    int
+	MoveUses [2]map[string]int // times each side used each move, by move name
+}
+
+// PlayOut fights two teams single battle style until one side has no pokemon
+// left, with both sides choosing moves like the challenger AI. Each team sends
+// its pokemon out in order and the next one replaces one that faints, with
+// entry abilities and weather triggering as it comes out. The pokemon are
+// changed as they fight, so pass clones to play the same teams again.
+func PlayOut(rng *rand.Rand, teams [2][]*Pokemon) Outcome {
+	out := Outcome{Winner: -1, MoveUses: [2]map[string]int{{}, {}}}
+	field := &Field{}
+	var next [2]int
+	var active [2]*Pokemon
+
+	// Sends out the next pokemon on a side, false if it has none left
+	sendOut := func(side int) bool {
+		for next[side] < len(teams[side]) {
+			p := teams[side][next[side]]
+			next[side]++
+			if !p.Fainted() {
+				active[side] = p
+				return true
+			}
+		}
+		return false
+	}
+	if !sendOut(UserSide) || !sendOut(ChallengerSide) {
+		return out
+	}
+	ApplyEntryAbilities(active[UserSide], active[ChallengerSide])
+	EntryWeather(field, active[UserSide])
+	EntryWeather(field, active[ChallengerSide])
+
+	for out.Turns < MaxTurns {
+		out.Turns++
+		a, b := active[UserSide], active[ChallengerSide]
+		turn := ResolveTurn(rng, field, a, b, ChooseMove(rng, a), ChooseMove(rng, b))
+		for _, ev := range turn.Events {
+			side := UserSide
+			if ev.Attacker == b {
+				side = ChallengerSide
+			}
+			out.MoveUses[side][ev.Move.Name]++
+		}
+
+		// Replacements come out after the turn, each facing the other side's pokemon
+		var left [2]bool
+		for side := range active {
+			left[side] = !active[side].Fainted() || sendOut(side)
+		}
+		switch {
+		case !left[UserSide] && !left[ChallengerSide]:
+			return out
+		case !left[ChallengerSide]:
+			out.Winner = UserSide
+			return out
+		case !left[UserSide]:
+			out.Winner = ChallengerSide
+			return out
+		}
+		for side, p := range active {
+			if p != a && p != b {
+				intimidate(p, active[1-side])
+				EntryWeather(field, p)
+			}
+		}
+	}
+	return out
+}
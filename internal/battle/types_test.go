@@ -0,0 +1,47 @@
+package battle
+
+import "testing"
+
+func TestEffectiveness(t *testing.T) {
+	tests := []struct {
+		move  string
+		types []string
+		want  float64
+		label string
+	}{
+		{"fire", []string{"grass"}, 2, "super-effective"},
+		{"ice", []string{"dragon", "flying"}, 4, "super-effective"},
+		{"water", []string{"fire", "water"}, 1, ""},
+		{"normal", []string{"normal"}, 1, ""},
+		{"fire", []string{"water"}, 0.5, "not very effective"},
+		{"grass", []string{"fire", "flying"}, 0.25, "not very effective"},
+		{"electric", []string{"ground"}, 0, "no effect"},
+		{"ghost", []string{"normal", "psychic"}, 0, "no effect"},
+		{"dragon", []string{"fairy"}, 0, "no effect"},
+		// Types the chart doesn't know about are neutral
+		{"fire", []string{"shadow"}, 1, ""},
+		{"shadow", []string{"fire"}, 1, ""},
+	}
+	for _, tt := range tests {
+		got := Effectiveness(tt.move, tt.types)
+		if got != tt.want {
+			t.Errorf("Effectiveness(%q, %v) = %v, want %v", tt.move, tt.types, got, tt.want)
+		}
+		if label := EffectivenessLabel(got); label != tt.label {
+			t.Errorf("EffectivenessLabel(%v) = %q, want %q", got, label, tt.label)
+		}
+	}
+}
+
+func TestIsType(t *testing.T) {
+	for _, name := range []string{"normal", "fire", "fairy", "steel"} {
+		if !IsType(name) {
+			t.Errorf("IsType(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"", "Fire", "shadow"} {
+		if IsType(name) {
+			t.Errorf("IsType(%q) = true, want false", name)
+		}
+	}
+}
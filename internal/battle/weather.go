@@ -0,0 +1,140 @@
+package battle
+
+import "fmt"
+
+const (
+	Rain      = "rain"
+	Sun       = "sun"
+	Sandstorm = "sandstorm"
+	Hail      = "hail"
+)
+
+// Weather set by a move or ability lasts this many turns, counting the one it starts on
+const WeatherDuration = 5
+
+// Battle-wide state that isn't tied to either pokemon
+type Field struct {
+	Weather      string // "" when the weather is clear
+	WeatherTurns int    // turns left, including the current one
+}
+
+// Moves that set the weather, keyed by PokéAPI move name
+var weatherMoves = map[string]string{
+	"rain-dance": Rain,
+	"sunny-day":  Sun,
+	"sandstorm":  Sandstorm,
+	"hail":       Hail,
+}
+
+// Abilities that set the weather when their pokemon enters battle
+var weatherAbilities = map[string]string{
+	"drizzle":      Rain,
+	"drought":      Sun,
+	"sand-stream":  Sandstorm,
+	"snow-warning": Hail,
+}
+
+var weatherStartMessages = map[string]string{
+	Rain:      "It started to rain!",
+	Sun:       "The sunlight turned harsh!",
+	Sandstorm: "A sandstorm kicked up!",
+	Hail:      "It started to hail!",
+}
+
+var weatherEndMessages = map[string]string{
+	Rain:      "The rain stopped.",
+	Sun:       "The harsh sunlight faded.",
+	Sandstorm: "The sandstorm subsided.",
+	Hail:      "The hail stopped.",
+}
+
+// IsWeatherMove reports whether a move does nothing but change the weather
+func IsWeatherMove(name string) bool {
+	_, ok := weatherMoves[name]
+	return ok
+}
+
+// Starts a weather, failing if it's already active. source is the pokemon
+// whose move or ability caused it.
+func setWeather(f *Field, weather string, source *Pokemon, cause string) *Effect {
+	if f.Weather == weather {
+		return nil
+	}
+	f.Weather = weather
+	f.WeatherTurns = WeatherDuration
+	return &Effect{
+		Pokemon: source,
+		Source:  cause,
+		Message: weatherStartMessages[weather],
+	}
+}
+
+// EntryWeather sets the weather from an ability like Drizzle as its pokemon
+// enters battle. Returns nil if the pokemon has no such ability or the
+// weather is already active.
+func EntryWeather(f *Field, p *Pokemon) *Effect {
+	w, ok := weatherAbilities[p.Ability]
+	if !ok || p.Fainted() {
+		return nil
+	}
+	return setWeather(f, w, p, p.Ability)
+}
+
+// Damage multiplier the weather gives a move, rain boosts water and weakens
+// fire and sun does the opposite
+func weatherMultiplier(f *Field, move Move) float64 {
+	switch {
+	case f.Weather == Rain && move.Type == "water", f.Weather == Sun && move.Type == "fire":
+		return 1.5
+	case f.Weather == Rain && move.Type == "fire", f.Weather == Sun && move.Type == "water":
+		return 0.5
+	}
+	return 1
+}
+
+// Sandstorm raises rock types' special defense by half
+func weatherSpecialDefense(f *Field, defender *Pokemon, def int) int {
+	if f.Weather == Sandstorm && defender.hasType("rock") {
+		return def * 3 / 2
+	}
+	return def
+}
+
+// Sandstorm and hail hurt every pokemon not of a type that shrugs them off
+// for a sixteenth of its max HP at the end of the turn
+func weatherChip(f *Field, p *Pokemon) *Effect {
+	if p.Fainted() {
+		return nil
+	}
+	switch {
+	case f.Weather == Sandstorm && !p.hasType("rock") && !p.hasType("ground") && !p.hasType("steel"):
+	case f.Weather == Hail && !p.hasType("ice"):
+	default:
+		return nil
+	}
+	chip := min(max(p.Stats.HP/16, 1), p.HP)
+	p.HP -= chip
+	return &Effect{
+		Pokemon:  p,
+		Source:   f.Weather,
+		HPChange: -chip,
+		Message:  fmt.Sprintf("%s is buffeted by the %s!", p.Name, f.Weather),
+	}
+}
+
+// Counts the weather down at the end of a turn, clearing it when it runs out
+func weatherTick(f *Field) *Effect {
+	if f.Weather == "" {
+		return nil
+	}
+	f.WeatherTurns--
+	if f.WeatherTurns > 0 {
+		return nil
+	}
+	ended := f.Weather
+	f.Weather, f.WeatherTurns = "", 0
+	return &Effect{
+		Source:  ended,
+		Message: weatherEndMessages[ended],
+	}
+}
@@ -0,0 +1,80 @@
+package battle
+
+import "testing"
+
+func TestWeatherChip(t *testing.T) {
+	tests := []struct {
+		weather string
+		types   []string
+		hp      int
+		want    int // HP change
+	}{
+		{Sandstorm, []string{"normal"}, 150, -9},
+		{Sandstorm, []string{"rock"}, 150, 0},
+		{Sandstorm, []string{"water", "ground"}, 150, 0},
+		{Sandstorm, []string{"steel"}, 150, 0},
+		{Hail, []string{"normal"}, 150, -9},
+		{Hail, []string{"ice"}, 150, 0},
+		{Rain, []string{"normal"}, 150, 0},
+		{Sun, []string{"normal"}, 150, 0},
+		{"", []string{"normal"}, 150, 0},
+		// Chip damage doesn't take more HP than is left
+		{Sandstorm, []string{"normal"}, 4, -4},
+		{Sandstorm, []string{"normal"}, 0, 0},
+	}
+	for _, tt := range tests {
+		p := testPokemon(tt.types...)
+		p.HP = tt.hp
+		e := weatherChip(&Field{Weather: tt.weather}, p)
+		got := 0
+		if e != nil {
+			got = e.HPChange
+		}
+		if got != tt.want || p.HP != tt.hp+tt.want {
+			t.Errorf("weatherChip(%q, %v at %d HP) = %d leaving %d HP, want %d", tt.weather, tt.types, tt.hp, got, p.HP, tt.want)
+		}
+	}
+}
+
+func TestWeatherTick(t *testing.T) {
+	f := &Field{Weather: Rain, WeatherTurns: WeatherDuration}
+	for i := 1; i < WeatherDuration; i++ {
+		if e := weatherTick(f); e != nil {
+			t.Fatalf("the rain ended after %d turns, want %d", i, WeatherDuration)
+		}
+	}
+	e := weatherTick(f)
+	if e == nil || e.Message != "The rain stopped." {
+		t.Fatalf("weatherTick on the last turn = %+v, want the rain to stop", e)
+	}
+	if f.Weather != "" || f.WeatherTurns != 0 {
+		t.Errorf("field after the rain stopped = %+v, want clear", f)
+	}
+	if e := weatherTick(f); e != nil {
+		t.Errorf("weatherTick with clear weather = %+v, want nil", e)
+	}
+}
+
+func TestEntryWeather(t *testing.T) {
+	f := &Field{}
+	p := testPokemon("water")
+	if e := EntryWeather(f, p); e != nil {
+		t.Errorf("EntryWeather without a weather ability = %+v, want nil", e)
+	}
+
+	p.Ability = "drizzle"
+	if e := EntryWeather(f, p); e == nil || f.Weather != Rain || f.WeatherTurns != WeatherDuration {
+		t.Errorf("EntryWeather(drizzle) left the field %+v, want %d turns of rain", f, WeatherDuration)
+	}
+	// Rain that's already falling isn't started again
+	f.WeatherTurns = 2
+	if e := EntryWeather(f, p); e != nil || f.WeatherTurns != 2 {
+		t.Errorf("EntryWeather(drizzle) in the rain = %+v with %d turns left, want nil with 2", e, f.WeatherTurns)
+	}
+
+	q := testPokemon("fire")
+	q.Ability = "drought"
+	if e := EntryWeather(f, q); e == nil || f.Weather != Sun || f.WeatherTurns != WeatherDuration {
+		t.Errorf("EntryWeather(drought) in the rain left the field %+v, want %d turns of sun", f, WeatherDuration)
+	}
+}
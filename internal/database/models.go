@@ -11,11 +11,65 @@ import (
 	"github.com/google/uuid"
 )
 
+type Battle struct {
+	ID                              uuid.UUID
+	UserID                          uuid.UUID
+	ChallengerPokemonID             uuid.NullUUID
+	UserPokemonID                   uuid.NullUUID
+	Turn                            int32
+	Weather                         sql.NullString
+	WeatherTurns                    int32
+	CreatedAt                       time.Time
+	UpdatedAt                       time.Time
+	UserChargingMoveID              sql.NullInt32
+	UserRecharging                  bool
+	ChallengerChargingMoveID        sql.NullInt32
+	ChallengerRecharging            bool
+	Kind                            string
+	ChallengerSpeciesID             sql.NullInt32
+	FleeAttempts                    int32
+	Result                          sql.NullString
+	EndedAt                         sql.NullTime
+	Format                          string
+	UserPartnerID                   uuid.NullUUID
+	UserPartnerChargingMoveID       sql.NullInt32
+	UserPartnerRecharging           bool
+	ChallengerPartnerID             uuid.NullUUID
+	ChallengerPartnerChargingMoveID sql.NullInt32
+	ChallengerPartnerRecharging     bool
+	UserAttackStage                 int32
+	UserPartnerAttackStage          int32
+	ChallengerAttackStage           int32
+	ChallengerPartnerAttackStage    int32
+}
+
 type ChallengerPokemon struct {
-	ID        uuid.UUID
-	PokemonID sql.NullInt32
-	CurrentHp int32
-	CreatedAt sql.NullTime
+	ID         uuid.UUID
+	PokemonID  sql.NullInt32
+	CurrentHp  int32
+	CreatedAt  sql.NullTime
+	HeldItemID sql.NullInt32
+	Ability    sql.NullString
+	Status     sql.NullString
+}
+
+type CurrencyLedger struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	Amount       int32
+	BalanceAfter int32
+	Reason       string
+	Reference    sql.NullString
+	CreatedAt    time.Time
+}
+
+type Item struct {
+	ID       int32
+	Name     string
+	Category string
+	Cost     int32
+	Effect   sql.NullString
+	ImageUrl sql.NullString
 }
 
 type Move struct {
@@ -24,6 +78,18 @@ type Move struct {
 	Power       int32
 	Type        string
 	Description sql.NullString
+	DamageClass string
+	Priority    int32
+	MinHits     sql.NullInt32
+	MaxHits     sql.NullInt32
+	Target      string
+}
+
+type MoveName struct {
+	MoveID     int32
+	Language   string
+	Name       sql.NullString
+	FlavorText sql.NullString
 }
 
 type Pokedex struct {
@@ -40,12 +106,59 @@ type Pokedex struct {
 	ImageUrl       sql.NullString
 }
 
+type PokedexProgress struct {
+	UserID    uuid.UUID
+	PokemonID int32
+	Caught    bool
+	SeenAt    time.Time
+	CaughtAt  sql.NullTime
+}
+
+type PokemonAbility struct {
+	PokemonID int32
+	Ability   string
+	IsHidden  bool
+	Slot      int32
+}
+
 type PokemonMove struct {
 	ID        int32
 	PokemonID int32
 	MoveID    int32
 }
 
+type PokemonName struct {
+	PokemonID  int32
+	Language   string
+	Name       sql.NullString
+	FlavorText sql.NullString
+}
+
+type PokemonSprite struct {
+	PokemonID int32
+	Variant   string
+	Url       sql.NullString
+}
+
+type ShopItem struct {
+	ItemName string
+	Price    int32
+}
+
+type Trade struct {
+	ID                 uuid.UUID
+	FromUserID         uuid.UUID
+	ToUserID           uuid.UUID
+	OfferedPokemonID   uuid.UUID
+	RequestedPokemonID uuid.UUID
+	OfferedSpeciesID   int32
+	RequestedSpeciesID int32
+	Status             string
+	CounterOf          uuid.NullUUID
+	CreatedAt          time.Time
+	ResolvedAt         sql.NullTime
+}
+
 type User struct {
 	ID                 uuid.UUID
 	Username           string
@@ -54,14 +167,26 @@ type User struct {
 	SessionToken       sql.NullString
 	CsrfToken          sql.NullString
 	ChallengePokemonID uuid.NullUUID
+	Balance            int32
 }
 
 type UserPokemon struct {
-	ID        uuid.UUID
-	UserID    uuid.UUID
-	PokemonID sql.NullInt32
-	Nickname  sql.NullString
-	CurrentHp int32
-	IsActive  bool
-	CreatedAt sql.NullTime
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	PokemonID  sql.NullInt32
+	Nickname   sql.NullString
+	CurrentHp  int32
+	IsActive   bool
+	CreatedAt  sql.NullTime
+	InBox      bool
+	PartySlot  sql.NullInt32
+	Status     sql.NullString
+	HeldItemID sql.NullInt32
+	Ability    sql.NullString
+}
+
+type UserItem struct {
+	UserID   uuid.UUID
+	ItemID   int32
+	Quantity int32
 }
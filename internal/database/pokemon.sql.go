@@ -31,6 +31,56 @@ func (q *Queries) ActivateUserPokemon(ctx context.Context, arg ActivateUserPokem
 	return id, err
 }
 
+const clearChallengePokemonHeldItem = `-- name: ClearChallengePokemonHeldItem :exec
+UPDATE challenger_pokemon
+SET held_item_id = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ClearChallengePokemonHeldItem(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearChallengePokemonHeldItem, id)
+	return err
+}
+
+const compactUserPartySlots = `-- name: CompactUserPartySlots :exec
+UPDATE user_pokemon up
+SET party_slot = s.new_slot
+FROM (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY party_slot, created_at) AS new_slot
+    FROM user_pokemon
+    WHERE user_id = $1 AND in_box = false
+) s
+WHERE up.id = s.id AND up.party_slot IS DISTINCT FROM s.new_slot
+`
+
+// Renumber party slots 1..n, keeping their current order
+func (q *Queries) CompactUserPartySlots(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, compactUserPartySlots, userID)
+	return err
+}
+
+const countUserBoxPokemon = `-- name: CountUserBoxPokemon :one
+SELECT COUNT(*) FROM user_pokemon WHERE user_id = $1 AND in_box = true
+`
+
+func (q *Queries) CountUserBoxPokemon(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserBoxPokemon, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserPartyPokemon = `-- name: CountUserPartyPokemon :one
+SELECT COUNT(*) FROM user_pokemon WHERE user_id = $1 AND in_box = false
+`
+
+func (q *Queries) CountUserPartyPokemon(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserPartyPokemon, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countUserPokemon = `-- name: CountUserPokemon :one
 SELECT COUNT(*) FROM user_pokemon WHERE user_id = $1
 `
@@ -63,6 +113,21 @@ func (q *Queries) DeleteChallengePokemon(ctx context.Context, id uuid.UUID) erro
 	return err
 }
 
+const deleteUserPokemon = `-- name: DeleteUserPokemon :exec
+DELETE FROM user_pokemon
+WHERE user_id = $1 AND id = $2
+`
+
+type DeleteUserPokemonParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) DeleteUserPokemon(ctx context.Context, arg DeleteUserPokemonParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserPokemon, arg.UserID, arg.ID)
+	return err
+}
+
 const fetchPokemonDataById = `-- name: FetchPokemonDataById :one
 SELECT id, name, type_1, type_2, hp, attack, defense, special_attack, special_defense, speed, image_url FROM pokedex WHERE id = $1
 `
@@ -110,7 +175,7 @@ func (q *Queries) FetchPokemonDataByName(ctx context.Context, lower string) (Pok
 }
 
 const getActiveUserPokemon = `-- name: GetActiveUserPokemon :one
-SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at
+SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at, in_box, party_slot, status, held_item_id, ability
 FROM user_pokemon
 WHERE user_id = $1 AND is_active = True
 `
@@ -126,15 +191,21 @@ func (q *Queries) GetActiveUserPokemon(ctx context.Context, userID uuid.UUID) (U
 		&i.CurrentHp,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.InBox,
+		&i.PartySlot,
+		&i.Status,
+		&i.HeldItemID,
+		&i.Ability,
 	)
 	return i, err
 }
 
 const getAllUserPokemon = `-- name: GetAllUserPokemon :many
-SELECT p.id, p.name, p.type_1, p.type_2, p.hp, p.attack, p.defense, p.special_attack, p.special_defense, p.speed, p.image_url, up.is_active
+SELECT p.id, p.name, p.type_1, p.type_2, p.hp, p.attack, p.defense, p.special_attack, p.special_defense, p.speed, p.image_url, up.id AS user_pokemon_id, up.is_active, up.nickname, up.party_slot
 FROM user_pokemon up
 JOIN pokedex p ON up.pokemon_id = p.id
-WHERE up.user_id = $1
+WHERE up.user_id = $1 AND up.in_box = false
+ORDER BY up.party_slot
 `
 
 type GetAllUserPokemonRow struct {
@@ -149,7 +220,10 @@ type GetAllUserPokemonRow struct {
 	SpecialDefense int32
 	Speed          int32
 	ImageUrl       sql.NullString
+	UserPokemonID  uuid.UUID
 	IsActive       bool
+	Nickname       sql.NullString
+	PartySlot      sql.NullInt32
 }
 
 func (q *Queries) GetAllUserPokemon(ctx context.Context, userID uuid.UUID) ([]GetAllUserPokemonRow, error) {
@@ -173,7 +247,10 @@ func (q *Queries) GetAllUserPokemon(ctx context.Context, userID uuid.UUID) ([]Ge
 			&i.SpecialDefense,
 			&i.Speed,
 			&i.ImageUrl,
+			&i.UserPokemonID,
 			&i.IsActive,
+			&i.Nickname,
+			&i.PartySlot,
 		); err != nil {
 			return nil, err
 		}
@@ -188,8 +265,49 @@ func (q *Queries) GetAllUserPokemon(ctx context.Context, userID uuid.UUID) ([]Ge
 	return items, nil
 }
 
+const getChallengePokemon = `-- name: GetChallengePokemon :one
+SELECT id, pokemon_id, current_hp, created_at, held_item_id, ability, status FROM challenger_pokemon
+WHERE id = $1
+`
+
+func (q *Queries) GetChallengePokemon(ctx context.Context, id uuid.UUID) (ChallengerPokemon, error) {
+	row := q.db.QueryRowContext(ctx, getChallengePokemon, id)
+	var i ChallengerPokemon
+	err := row.Scan(
+		&i.ID,
+		&i.PokemonID,
+		&i.CurrentHp,
+		&i.CreatedAt,
+		&i.HeldItemID,
+		&i.Ability,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getChallengePokemonForUpdate = `-- name: GetChallengePokemonForUpdate :one
+SELECT id, pokemon_id, current_hp, created_at, held_item_id, ability, status FROM challenger_pokemon
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetChallengePokemonForUpdate(ctx context.Context, id uuid.UUID) (ChallengerPokemon, error) {
+	row := q.db.QueryRowContext(ctx, getChallengePokemonForUpdate, id)
+	var i ChallengerPokemon
+	err := row.Scan(
+		&i.ID,
+		&i.PokemonID,
+		&i.CurrentHp,
+		&i.CreatedAt,
+		&i.HeldItemID,
+		&i.Ability,
+		&i.Status,
+	)
+	return i, err
+}
+
 const getMoveByID = `-- name: GetMoveByID :one
-SELECT move_id, name, power, type, description FROM moves WHERE move_id = $1
+SELECT move_id, name, power, type, description, damage_class, priority, min_hits, max_hits, target FROM moves WHERE move_id = $1
 `
 
 func (q *Queries) GetMoveByID(ctx context.Context, moveID int32) (Move, error) {
@@ -201,12 +319,52 @@ func (q *Queries) GetMoveByID(ctx context.Context, moveID int32) (Move, error) {
 		&i.Power,
 		&i.Type,
 		&i.Description,
+		&i.DamageClass,
+		&i.Priority,
+		&i.MinHits,
+		&i.MaxHits,
+		&i.Target,
+	)
+	return i, err
+}
+
+const getMoveByName = `-- name: GetMoveByName :one
+SELECT move_id, name, power, type, description, damage_class, priority, min_hits, max_hits, target FROM moves WHERE name = $1
+`
+
+func (q *Queries) GetMoveByName(ctx context.Context, name string) (Move, error) {
+	row := q.db.QueryRowContext(ctx, getMoveByName, name)
+	var i Move
+	err := row.Scan(
+		&i.MoveID,
+		&i.Name,
+		&i.Power,
+		&i.Type,
+		&i.Description,
+		&i.DamageClass,
+		&i.Priority,
+		&i.MinHits,
+		&i.MaxHits,
+		&i.Target,
 	)
 	return i, err
 }
 
+const getNextPartySlot = `-- name: GetNextPartySlot :one
+SELECT (COALESCE(MAX(party_slot), 0) + 1)::int AS next_slot
+FROM user_pokemon
+WHERE user_id = $1 AND in_box = false
+`
+
+func (q *Queries) GetNextPartySlot(ctx context.Context, userID uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getNextPartySlot, userID)
+	var next_slot int32
+	err := row.Scan(&next_slot)
+	return next_slot, err
+}
+
 const getOneUserPokemon = `-- name: GetOneUserPokemon :one
-SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at
+SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at, in_box, party_slot, status, held_item_id, ability
 FROM user_pokemon
 WHERE user_id = $1 and pokemon_id = $2
 `
@@ -216,6 +374,7 @@ type GetOneUserPokemonParams struct {
 	PokemonID sql.NullInt32
 }
 
+// Deprecated: a user can own several of the same species, use GetUserPokemonByID
 func (q *Queries) GetOneUserPokemon(ctx context.Context, arg GetOneUserPokemonParams) (UserPokemon, error) {
 	row := q.db.QueryRowContext(ctx, getOneUserPokemon, arg.UserID, arg.PokemonID)
 	var i UserPokemon
@@ -227,12 +386,85 @@ func (q *Queries) GetOneUserPokemon(ctx context.Context, arg GetOneUserPokemonPa
 		&i.CurrentHp,
 		&i.IsActive,
 		&i.CreatedAt,
+		&i.InBox,
+		&i.PartySlot,
+		&i.Status,
+		&i.HeldItemID,
+		&i.Ability,
+	)
+	return i, err
+}
+
+const getOneUserPokemonByLocation = `-- name: GetOneUserPokemonByLocation :one
+SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at, in_box, party_slot, status, held_item_id, ability
+FROM user_pokemon
+WHERE user_id = $1 AND pokemon_id = $2 AND in_box = $3
+ORDER BY created_at
+LIMIT 1
+`
+
+type GetOneUserPokemonByLocationParams struct {
+	UserID    uuid.UUID
+	PokemonID sql.NullInt32
+	InBox     bool
+}
+
+func (q *Queries) GetOneUserPokemonByLocation(ctx context.Context, arg GetOneUserPokemonByLocationParams) (UserPokemon, error) {
+	row := q.db.QueryRowContext(ctx, getOneUserPokemonByLocation, arg.UserID, arg.PokemonID, arg.InBox)
+	var i UserPokemon
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PokemonID,
+		&i.Nickname,
+		&i.CurrentHp,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.InBox,
+		&i.PartySlot,
+		&i.Status,
+		&i.HeldItemID,
+		&i.Ability,
 	)
 	return i, err
 }
 
+const getPokemonAbilities = `-- name: GetPokemonAbilities :many
+SELECT pokemon_id, ability, is_hidden, slot FROM pokemon_abilities
+WHERE pokemon_id = $1
+ORDER BY slot
+`
+
+func (q *Queries) GetPokemonAbilities(ctx context.Context, pokemonID int32) ([]PokemonAbility, error) {
+	rows, err := q.db.QueryContext(ctx, getPokemonAbilities, pokemonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PokemonAbility
+	for rows.Next() {
+		var i PokemonAbility
+		if err := rows.Scan(
+			&i.PokemonID,
+			&i.Ability,
+			&i.IsHidden,
+			&i.Slot,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPokemonMoves = `-- name: GetPokemonMoves :many
-SELECT m.move_id, m.name, m.power, m.type, m.description
+SELECT m.move_id, m.name, m.power, m.type, m.description, m.damage_class, m.priority, m.min_hits, m.max_hits, m.target
 FROM pokemon_moves pm
 JOIN moves m on pm.move_id = m.move_id
 WHERE pm.pokemon_id = $1
@@ -253,6 +485,11 @@ func (q *Queries) GetPokemonMoves(ctx context.Context, pokemonID int32) ([]Move,
 			&i.Power,
 			&i.Type,
 			&i.Description,
+			&i.DamageClass,
+			&i.Priority,
+			&i.MinHits,
+			&i.MaxHits,
+			&i.Target,
 		); err != nil {
 			return nil, err
 		}
@@ -268,7 +505,7 @@ func (q *Queries) GetPokemonMoves(ctx context.Context, pokemonID int32) ([]Move,
 }
 
 const getUserChallengePokemon = `-- name: GetUserChallengePokemon :one
-SELECT cp.id, cp.pokemon_id, cp.current_hp, cp.created_at
+SELECT cp.id, cp.pokemon_id, cp.current_hp, cp.created_at, cp.held_item_id, cp.ability, cp.status
 FROM users u
 JOIN challenger_pokemon cp ON u.challenge_pokemon_id = cp.id
 WHERE u.id = $1
@@ -282,6 +519,110 @@ func (q *Queries) GetUserChallengePokemon(ctx context.Context, id uuid.UUID) (Ch
 		&i.PokemonID,
 		&i.CurrentHp,
 		&i.CreatedAt,
+		&i.HeldItemID,
+		&i.Ability,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getUserPartyPokemon = `-- name: GetUserPartyPokemon :many
+SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at, in_box, party_slot, status, held_item_id, ability
+FROM user_pokemon
+WHERE user_id = $1 AND in_box = false
+ORDER BY party_slot
+`
+
+func (q *Queries) GetUserPartyPokemon(ctx context.Context, userID uuid.UUID) ([]UserPokemon, error) {
+	rows, err := q.db.QueryContext(ctx, getUserPartyPokemon, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserPokemon
+	for rows.Next() {
+		var i UserPokemon
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.PokemonID,
+			&i.Nickname,
+			&i.CurrentHp,
+			&i.IsActive,
+			&i.CreatedAt,
+			&i.InBox,
+			&i.PartySlot,
+			&i.Status,
+			&i.HeldItemID,
+			&i.Ability,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserPokemonForUpdate = `-- name: GetUserPokemonForUpdate :one
+SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at, in_box, party_slot, status, held_item_id, ability
+FROM user_pokemon
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserPokemonForUpdate(ctx context.Context, id uuid.UUID) (UserPokemon, error) {
+	row := q.db.QueryRowContext(ctx, getUserPokemonForUpdate, id)
+	var i UserPokemon
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PokemonID,
+		&i.Nickname,
+		&i.CurrentHp,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.InBox,
+		&i.PartySlot,
+		&i.Status,
+		&i.HeldItemID,
+		&i.Ability,
+	)
+	return i, err
+}
+
+const getUserPokemonByID = `-- name: GetUserPokemonByID :one
+SELECT id, user_id, pokemon_id, nickname, current_hp, is_active, created_at, in_box, party_slot, status, held_item_id, ability
+FROM user_pokemon
+WHERE user_id = $1 AND id = $2
+`
+
+type GetUserPokemonByIDParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) GetUserPokemonByID(ctx context.Context, arg GetUserPokemonByIDParams) (UserPokemon, error) {
+	row := q.db.QueryRowContext(ctx, getUserPokemonByID, arg.UserID, arg.ID)
+	var i UserPokemon
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.PokemonID,
+		&i.Nickname,
+		&i.CurrentHp,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.InBox,
+		&i.PartySlot,
+		&i.Status,
+		&i.HeldItemID,
+		&i.Ability,
 	)
 	return i, err
 }
@@ -291,82 +632,52 @@ INSERT INTO challenger_pokemon (
     id,
     pokemon_id,
     current_hp,
+    held_item_id,
+    ability,
     created_at
 ) VALUES (
-    $1, $2, $3, DEFAULT
+    $1, $2, $3, $4, $5, DEFAULT
 )
 `
 
 type InsertChallengePokemonParams struct {
-	ID        uuid.UUID
-	PokemonID sql.NullInt32
-	CurrentHp int32
+	ID         uuid.UUID
+	PokemonID  sql.NullInt32
+	CurrentHp  int32
+	HeldItemID sql.NullInt32
+	Ability    sql.NullString
 }
 
 func (q *Queries) InsertChallengePokemon(ctx context.Context, arg InsertChallengePokemonParams) error {
-	_, err := q.db.ExecContext(ctx, insertChallengePokemon, arg.ID, arg.PokemonID, arg.CurrentHp)
-	return err
-}
-
-const insertMove = `-- name: InsertMove :exec
-INSERT INTO moves (move_id, name, power, type, description)
-VALUES ($1, $2, $3, $4, $5)
-`
-
-type InsertMoveParams struct {
-	MoveID      int32
-	Name        string
-	Power       int32
-	Type        string
-	Description sql.NullString
-}
-
-func (q *Queries) InsertMove(ctx context.Context, arg InsertMoveParams) error {
-	_, err := q.db.ExecContext(ctx, insertMove,
-		arg.MoveID,
-		arg.Name,
-		arg.Power,
-		arg.Type,
-		arg.Description,
+	_, err := q.db.ExecContext(ctx, insertChallengePokemon,
+		arg.ID,
+		arg.PokemonID,
+		arg.CurrentHp,
+		arg.HeldItemID,
+		arg.Ability,
 	)
 	return err
 }
 
-const insertPokedex = `-- name: InsertPokedex :exec
-INSERT INTO pokedex (
-    id, name, type_1, type_2, hp, attack, defense, special_attack, special_defense, speed, image_url
-) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-)
+const insertPokemonAbility = `-- name: InsertPokemonAbility :exec
+INSERT INTO pokemon_abilities (pokemon_id, ability, is_hidden, slot)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (pokemon_id, ability) DO NOTHING
 `
 
-type InsertPokedexParams struct {
-	ID             int32
-	Name           string
-	Type1          string
-	Type2          sql.NullString
-	Hp             int32
-	Attack         int32
-	Defense        int32
-	SpecialAttack  int32
-	SpecialDefense int32
-	Speed          int32
-	ImageUrl       sql.NullString
+type InsertPokemonAbilityParams struct {
+	PokemonID int32
+	Ability   string
+	IsHidden  bool
+	Slot      int32
 }
 
-func (q *Queries) InsertPokedex(ctx context.Context, arg InsertPokedexParams) error {
-	_, err := q.db.ExecContext(ctx, insertPokedex,
-		arg.ID,
-		arg.Name,
-		arg.Type1,
-		arg.Type2,
-		arg.Hp,
-		arg.Attack,
-		arg.Defense,
-		arg.SpecialAttack,
-		arg.SpecialDefense,
-		arg.Speed,
-		arg.ImageUrl,
+func (q *Queries) InsertPokemonAbility(ctx context.Context, arg InsertPokemonAbilityParams) error {
+	_, err := q.db.ExecContext(ctx, insertPokemonAbility,
+		arg.PokemonID,
+		arg.Ability,
+		arg.IsHidden,
+		arg.Slot,
 	)
 	return err
 }
@@ -395,9 +706,12 @@ INSERT INTO user_pokemon (
     nickname,
     current_hp,
     is_active,
+    in_box,
+    party_slot,
+    ability,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, DEFAULT
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, DEFAULT
 )
 `
 
@@ -408,6 +722,9 @@ type InsertUserPokemonParams struct {
 	Nickname  sql.NullString
 	CurrentHp int32
 	IsActive  bool
+	InBox     bool
+	PartySlot sql.NullInt32
+	Ability   sql.NullString
 }
 
 func (q *Queries) InsertUserPokemon(ctx context.Context, arg InsertUserPokemonParams) error {
@@ -418,22 +735,491 @@ func (q *Queries) InsertUserPokemon(ctx context.Context, arg InsertUserPokemonPa
 		arg.Nickname,
 		arg.CurrentHp,
 		arg.IsActive,
+		arg.InBox,
+		arg.PartySlot,
+		arg.Ability,
 	)
 	return err
 }
 
-const setUserChallengePokemon = `-- name: SetUserChallengePokemon :exec
-UPDATE users
-SET challenge_pokemon_id = $1
-WHERE id = $2
+const listPokedex = `-- name: ListPokedex :many
+SELECT id, name, type_1, type_2, hp, attack, defense, special_attack, special_defense, speed, image_url FROM pokedex ORDER BY id
 `
 
-type SetUserChallengePokemonParams struct {
-	ChallengePokemonID uuid.NullUUID
-	ID                 uuid.UUID
-}
-
-func (q *Queries) SetUserChallengePokemon(ctx context.Context, arg SetUserChallengePokemonParams) error {
-	_, err := q.db.ExecContext(ctx, setUserChallengePokemon, arg.ChallengePokemonID, arg.ID)
+func (q *Queries) ListPokedex(ctx context.Context) ([]Pokedex, error) {
+	rows, err := q.db.QueryContext(ctx, listPokedex)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Pokedex
+	for rows.Next() {
+		var i Pokedex
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type1,
+			&i.Type2,
+			&i.Hp,
+			&i.Attack,
+			&i.Defense,
+			&i.SpecialAttack,
+			&i.SpecialDefense,
+			&i.Speed,
+			&i.ImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPokedexNames = `-- name: ListPokedexNames :many
+SELECT name FROM pokedex ORDER BY name
+`
+
+func (q *Queries) ListPokedexNames(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listPokedexNames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPokedexWithoutAbilities = `-- name: ListPokedexWithoutAbilities :many
+SELECT id FROM pokedex p
+WHERE NOT EXISTS (SELECT 1 FROM pokemon_abilities a WHERE a.pokemon_id = p.id)
+ORDER BY id
+`
+
+// Cached species whose abilities haven't been stored yet
+func (q *Queries) ListPokedexWithoutAbilities(ctx context.Context) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, listPokedexWithoutAbilities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserBoxPokemon = `-- name: ListUserBoxPokemon :many
+SELECT p.id, p.name, p.type_1, p.type_2, p.hp, p.attack, p.defense, p.special_attack, p.special_defense, p.speed, p.image_url, up.id AS user_pokemon_id, up.nickname
+FROM user_pokemon up
+JOIN pokedex p ON up.pokemon_id = p.id
+WHERE up.user_id = $1 AND up.in_box = true
+ORDER BY up.created_at, up.id
+LIMIT $2 OFFSET $3
+`
+
+type ListUserBoxPokemonParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type ListUserBoxPokemonRow struct {
+	ID             int32
+	Name           string
+	Type1          string
+	Type2          sql.NullString
+	Hp             int32
+	Attack         int32
+	Defense        int32
+	SpecialAttack  int32
+	SpecialDefense int32
+	Speed          int32
+	ImageUrl       sql.NullString
+	UserPokemonID  uuid.UUID
+	Nickname       sql.NullString
+}
+
+func (q *Queries) ListUserBoxPokemon(ctx context.Context, arg ListUserBoxPokemonParams) ([]ListUserBoxPokemonRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserBoxPokemon, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserBoxPokemonRow
+	for rows.Next() {
+		var i ListUserBoxPokemonRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type1,
+			&i.Type2,
+			&i.Hp,
+			&i.Attack,
+			&i.Defense,
+			&i.SpecialAttack,
+			&i.SpecialDefense,
+			&i.Speed,
+			&i.ImageUrl,
+			&i.UserPokemonID,
+			&i.Nickname,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserPokemonForTrade = `-- name: ListUserPokemonForTrade :many
+SELECT up.id, up.nickname, up.in_box, p.id AS pokemon_id, p.name, p.type_1, p.type_2, p.image_url
+FROM user_pokemon up
+JOIN pokedex p ON up.pokemon_id = p.id
+WHERE up.user_id = $1
+ORDER BY up.in_box, up.party_slot, up.created_at
+`
+
+type ListUserPokemonForTradeRow struct {
+	ID        uuid.UUID
+	Nickname  sql.NullString
+	InBox     bool
+	PokemonID int32
+	Name      string
+	Type1     string
+	Type2     sql.NullString
+	ImageUrl  sql.NullString
+}
+
+func (q *Queries) ListUserPokemonForTrade(ctx context.Context, userID uuid.UUID) ([]ListUserPokemonForTradeRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserPokemonForTrade, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserPokemonForTradeRow
+	for rows.Next() {
+		var i ListUserPokemonForTradeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Nickname,
+			&i.InBox,
+			&i.PokemonID,
+			&i.Name,
+			&i.Type1,
+			&i.Type2,
+			&i.ImageUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveUserPokemonToBox = `-- name: MoveUserPokemonToBox :execrows
+UPDATE user_pokemon
+SET in_box = true, party_slot = NULL
+WHERE user_id = $1 AND id = $2 AND in_box = false AND is_active = false
+`
+
+type MoveUserPokemonToBoxParams struct {
+	UserID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) MoveUserPokemonToBox(ctx context.Context, arg MoveUserPokemonToBoxParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveUserPokemonToBox, arg.UserID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const moveUserPokemonToParty = `-- name: MoveUserPokemonToParty :execrows
+UPDATE user_pokemon
+SET in_box = false, party_slot = $3
+WHERE user_id = $1 AND id = $2 AND in_box = true
+`
+
+type MoveUserPokemonToPartyParams struct {
+	UserID    uuid.UUID
+	ID        uuid.UUID
+	PartySlot sql.NullInt32
+}
+
+func (q *Queries) MoveUserPokemonToParty(ctx context.Context, arg MoveUserPokemonToPartyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveUserPokemonToParty, arg.UserID, arg.ID, arg.PartySlot)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setChallengePokemonHealth = `-- name: SetChallengePokemonHealth :exec
+UPDATE challenger_pokemon
+SET current_hp = $2, status = $3
+WHERE id = $1
+`
+
+type SetChallengePokemonHealthParams struct {
+	ID        uuid.UUID
+	CurrentHp int32
+	Status    sql.NullString
+}
+
+func (q *Queries) SetChallengePokemonHealth(ctx context.Context, arg SetChallengePokemonHealthParams) error {
+	_, err := q.db.ExecContext(ctx, setChallengePokemonHealth, arg.ID, arg.CurrentHp, arg.Status)
+	return err
+}
+
+const setUserChallengePokemon = `-- name: SetUserChallengePokemon :exec
+UPDATE users
+SET challenge_pokemon_id = $1
+WHERE id = $2
+`
+
+type SetUserChallengePokemonParams struct {
+	ChallengePokemonID uuid.NullUUID
+	ID                 uuid.UUID
+}
+
+func (q *Queries) SetUserChallengePokemon(ctx context.Context, arg SetUserChallengePokemonParams) error {
+	_, err := q.db.ExecContext(ctx, setUserChallengePokemon, arg.ChallengePokemonID, arg.ID)
+	return err
+}
+
+const setUserPokemonHealth = `-- name: SetUserPokemonHealth :exec
+UPDATE user_pokemon
+SET current_hp = $3, status = $4
+WHERE user_id = $1 AND id = $2
+`
+
+type SetUserPokemonHealthParams struct {
+	UserID    uuid.UUID
+	ID        uuid.UUID
+	CurrentHp int32
+	Status    sql.NullString
+}
+
+func (q *Queries) SetUserPokemonHealth(ctx context.Context, arg SetUserPokemonHealthParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPokemonHealth,
+		arg.UserID,
+		arg.ID,
+		arg.CurrentHp,
+		arg.Status,
+	)
+	return err
+}
+
+const setUserPokemonHeldItem = `-- name: SetUserPokemonHeldItem :exec
+UPDATE user_pokemon
+SET held_item_id = $3
+WHERE user_id = $1 AND id = $2
+`
+
+type SetUserPokemonHeldItemParams struct {
+	UserID     uuid.UUID
+	ID         uuid.UUID
+	HeldItemID sql.NullInt32
+}
+
+func (q *Queries) SetUserPokemonHeldItem(ctx context.Context, arg SetUserPokemonHeldItemParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPokemonHeldItem, arg.UserID, arg.ID, arg.HeldItemID)
+	return err
+}
+
+const setUserPokemonNickname = `-- name: SetUserPokemonNickname :exec
+UPDATE user_pokemon
+SET nickname = $3
+WHERE user_id = $1 AND id = $2
+`
+
+type SetUserPokemonNicknameParams struct {
+	UserID   uuid.UUID
+	ID       uuid.UUID
+	Nickname sql.NullString
+}
+
+func (q *Queries) SetUserPokemonNickname(ctx context.Context, arg SetUserPokemonNicknameParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPokemonNickname, arg.UserID, arg.ID, arg.Nickname)
+	return err
+}
+
+const setUserPokemonPartySlot = `-- name: SetUserPokemonPartySlot :exec
+UPDATE user_pokemon
+SET party_slot = $3
+WHERE user_id = $1 AND id = $2 AND in_box = false
+`
+
+type SetUserPokemonPartySlotParams struct {
+	UserID    uuid.UUID
+	ID        uuid.UUID
+	PartySlot sql.NullInt32
+}
+
+func (q *Queries) SetUserPokemonPartySlot(ctx context.Context, arg SetUserPokemonPartySlotParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPokemonPartySlot, arg.UserID, arg.ID, arg.PartySlot)
+	return err
+}
+
+const transferUserPokemon = `-- name: TransferUserPokemon :exec
+UPDATE user_pokemon
+SET user_id = $2,
+    in_box = $3,
+    party_slot = $4,
+    is_active = false
+WHERE id = $1
+`
+
+type TransferUserPokemonParams struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	InBox     bool
+	PartySlot sql.NullInt32
+}
+
+// Hands a pokemon to another user, placing it where the pokemon it was swapped for used to be
+func (q *Queries) TransferUserPokemon(ctx context.Context, arg TransferUserPokemonParams) error {
+	_, err := q.db.ExecContext(ctx, transferUserPokemon,
+		arg.ID,
+		arg.UserID,
+		arg.InBox,
+		arg.PartySlot,
+	)
+	return err
+}
+
+const upsertMove = `-- name: UpsertMove :exec
+INSERT INTO moves (move_id, name, power, type, description, damage_class, priority, min_hits, max_hits, target)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (move_id) DO UPDATE SET
+    name = EXCLUDED.name,
+    power = EXCLUDED.power,
+    type = EXCLUDED.type,
+    description = EXCLUDED.description,
+    damage_class = EXCLUDED.damage_class,
+    priority = EXCLUDED.priority,
+    min_hits = EXCLUDED.min_hits,
+    max_hits = EXCLUDED.max_hits,
+    target = EXCLUDED.target
+`
+
+type UpsertMoveParams struct {
+	MoveID      int32
+	Name        string
+	Power       int32
+	Type        string
+	Description sql.NullString
+	DamageClass string
+	Priority    int32
+	MinHits     sql.NullInt32
+	MaxHits     sql.NullInt32
+	Target      string
+}
+
+func (q *Queries) UpsertMove(ctx context.Context, arg UpsertMoveParams) error {
+	_, err := q.db.ExecContext(ctx, upsertMove,
+		arg.MoveID,
+		arg.Name,
+		arg.Power,
+		arg.Type,
+		arg.Description,
+		arg.DamageClass,
+		arg.Priority,
+		arg.MinHits,
+		arg.MaxHits,
+		arg.Target,
+	)
+	return err
+}
+
+const upsertPokedex = `-- name: UpsertPokedex :exec
+INSERT INTO pokedex (
+    id, name, type_1, type_2, hp, attack, defense, special_attack, special_defense, speed, image_url
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    type_1 = EXCLUDED.type_1,
+    type_2 = EXCLUDED.type_2,
+    hp = EXCLUDED.hp,
+    attack = EXCLUDED.attack,
+    defense = EXCLUDED.defense,
+    special_attack = EXCLUDED.special_attack,
+    special_defense = EXCLUDED.special_defense,
+    speed = EXCLUDED.speed,
+    image_url = EXCLUDED.image_url
+`
+
+type UpsertPokedexParams struct {
+	ID             int32
+	Name           string
+	Type1          string
+	Type2          sql.NullString
+	Hp             int32
+	Attack         int32
+	Defense        int32
+	SpecialAttack  int32
+	SpecialDefense int32
+	Speed          int32
+	ImageUrl       sql.NullString
+}
+
+func (q *Queries) UpsertPokedex(ctx context.Context, arg UpsertPokedexParams) error {
+	_, err := q.db.ExecContext(ctx, upsertPokedex,
+		arg.ID,
+		arg.Name,
+		arg.Type1,
+		arg.Type2,
+		arg.Hp,
+		arg.Attack,
+		arg.Defense,
+		arg.SpecialAttack,
+		arg.SpecialDefense,
+		arg.Speed,
+		arg.ImageUrl,
+	)
 	return err
 }
@@ -35,7 +35,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) error {
 }
 
 const getUserBySessionToken = `-- name: GetUserBySessionToken :one
-SELECT id, username, password_hash, created_at, session_token, csrf_token, challenge_pokemon_id FROM users WHERE session_token = $1
+SELECT id, username, password_hash, created_at, session_token, csrf_token, challenge_pokemon_id, balance FROM users WHERE session_token = $1
 `
 
 func (q *Queries) GetUserBySessionToken(ctx context.Context, sessionToken sql.NullString) (User, error) {
@@ -49,12 +49,13 @@ func (q *Queries) GetUserBySessionToken(ctx context.Context, sessionToken sql.Nu
 		&i.SessionToken,
 		&i.CsrfToken,
 		&i.ChallengePokemonID,
+		&i.Balance,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password_hash, created_at, session_token, csrf_token, challenge_pokemon_id FROM users WHERE username = $1
+SELECT id, username, password_hash, created_at, session_token, csrf_token, challenge_pokemon_id, balance FROM users WHERE username = $1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -68,10 +69,21 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.SessionToken,
 		&i.CsrfToken,
 		&i.ChallengePokemonID,
+		&i.Balance,
 	)
 	return i, err
 }
 
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users WHERE id = $1 FOR UPDATE
+`
+
+// Serializes changes to a user's party and box, held until the transaction ends
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockUser, id)
+	return err
+}
+
 const setUserSession = `-- name: SetUserSession :exec
 UPDATE users
 SET session_token = $1,
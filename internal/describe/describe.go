@@ -5,12 +5,14 @@ import "context"
 // ActionContext = one move being used by one Pokémon on another.
 type ActionContext struct {
 	Source struct {
-		Name  string
-		Types []string
+		Name    string
+		Types   []string
+		Ability string // PokéAPI ability name, empty if unknown
 	}
 	Target struct {
-		Name  string
-		Types []string
+		Name    string
+		Types   []string
+		Ability string
 	}
 	Move struct {
 		ID          int32
@@ -24,6 +26,9 @@ type ActionContext struct {
 	//Missed        bool
 	//Crit          bool
 	Effectiveness string // "super-effective", "not very effective", "no effect", ""
+	Weather       string // "rain", "sun", "sandstorm", "hail", "" when clear
+	Hits          int    // times a multi-hit move hit, 0 for single-hit moves
+	Language      string // PokéAPI language the names are in and to narrate in, e.g. "es", "" for English
 	//StatHint string // e.g., "lowers the target's Speed"
 }
 
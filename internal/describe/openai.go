@@ -64,8 +64,12 @@ func (o *OpenAI) DescribeAction(ctx context.Context, a ActionContext) (string, e
 	- Use the source Pokémon's typical look/feel (wings, flames, vines, armor-like hide, etc.) without inventing new anatomy.
 	- Use the move description for flavor (what it does / how it looks).
 	- If hints say missed, crit, or effectiveness, reflect it naturally.
+	- If hits is above 0 the move struck that many times in a row; convey the flurry without stating numbers.
+	- If there is weather, set the scene with it (rain, harsh sunlight, sandstorm, hail).
+	- An ability may be mentioned if it plausibly shaped the action (e.g., levitate dodging a ground move), otherwise ignore it.
 	- If a stat hint is provided (e.g., "lowers Speed"), imply it (e.g., "slowing it down").
 	- Avoid repetition across lines; vary verbs and imagery.
+	- Write in the language given (a PokéAPI language name like en, es, fr or ja-Hrkt), English if none; the names are already in it.
 	Output strict JSON: {"description": "..."}
 	`
 
@@ -73,19 +77,23 @@ func (o *OpenAI) DescribeAction(ctx context.Context, a ActionContext) (string, e
 		`Action:
 	source_name=%q
 	source_types=%v
+	source_ability=%q
 	target_name=%q
 	target_types=%v
+	target_ability=%q
 	move_name=%q
 	move_type=%q
 	move_power=%d
 	move_description=%q
-	hints: effectiveness=%q
+	hints: effectiveness=%q weather=%q hits=%d
+	language=%q
 
 	Write ONLY JSON. No explanations.`,
-		a.Source.Name, a.Source.Types,
-		a.Target.Name, a.Target.Types,
+		a.Source.Name, a.Source.Types, a.Source.Ability,
+		a.Target.Name, a.Target.Types, a.Target.Ability,
 		a.Move.Name, a.Move.Type, a.Move.Power, a.Move.Description,
-		a.Effectiveness,
+		a.Effectiveness, a.Weather, a.Hits,
+		a.Language,
 	)
 
 	body, _ := json.Marshal(chatReq{
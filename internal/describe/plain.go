@@ -6,7 +6,8 @@ import (
 	"strings"
 )
 
-// Plain is a deterministic, zero-dependency fallback.
+// Plain is a deterministic, zero-dependency fallback. It narrates in English
+// whatever the language, using the names it's given.
 type Plain struct{}
 
 func (Plain) DescribeAction(ctx context.Context, a ActionContext) (string, error) {
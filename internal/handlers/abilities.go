@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+)
+
+// 1 in hiddenAbilityOdds pokemon get their species' hidden ability
+const hiddenAbilityOdds = 20
+
+// Cache the abilities a species can have
+func storeAbilities(ctx context.Context, q *database.Queries, data pokeapi.Pokemon) error {
+	for _, a := range data.Abilities {
+		if err := q.InsertPokemonAbility(ctx, database.InsertPokemonAbilityParams{
+			PokemonID: int32(data.ID),
+			Ability:   strings.ToLower(a.Ability.Name),
+			IsHidden:  a.IsHidden,
+			Slot:      int32(a.Slot),
+		}); err != nil {
+			return fmt.Errorf("error inserting ability %s for pokemon %d: %w", a.Ability.Name, data.ID, err)
+		}
+	}
+	return nil
+}
+
+// ErrAbilitiesNotCached is returned when rolling an ability for a species
+// cached before abilities were. cmd/seed backfills them
+var ErrAbilitiesNotCached = errors.New("species abilities aren't cached")
+
+// BackfillAbilities fetches and stores the abilities of cached species that
+// don't have any yet and returns how many it filled in. Species PokeAPI
+// doesn't have are skipped.
+func (cfg *Config) BackfillAbilities(ctx context.Context) (int, error) {
+	ids, err := cfg.DB.ListPokedexWithoutAbilities(ctx)
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, id := range ids {
+		data, err := cfg.PokeAPI.Pokemon(ctx, strconv.Itoa(int(id)))
+		if errors.Is(err, pokeapi.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return added, fmt.Errorf("error fetching abilities for pokemon %d: %w", id, err)
+		}
+		if err := storeAbilities(ctx, cfg.DB, *data); err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+// Pick the ability a newly caught or challenging pokemon has, one of its
+// species' regular abilities or, rarely, its hidden one. Only cached abilities
+// are used, a species without any is ErrAbilitiesNotCached
+func (cfg *Config) rollAbility(ctx context.Context, pokemonID int32) (sql.NullString, error) {
+	abilities, err := cfg.DB.GetPokemonAbilities(ctx, pokemonID)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	if len(abilities) == 0 {
+		return sql.NullString{}, fmt.Errorf("pokemon %d: %w", pokemonID, ErrAbilitiesNotCached)
+	}
+
+	var regular, hidden []string
+	for _, a := range abilities {
+		if a.IsHidden {
+			hidden = append(hidden, a.Ability)
+		} else {
+			regular = append(regular, a.Ability)
+		}
+	}
+
+	pool := regular
+	if len(hidden) > 0 && (len(regular) == 0 || rand.Intn(hiddenAbilityOdds) == 0) {
+		pool = hidden
+	}
+	return sql.NullString{String: pool[rand.Intn(len(pool))], Valid: true}, nil
+}
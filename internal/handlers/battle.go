@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/describe"
+	"github.com/google/uuid"
+)
+
+type weatherDTO struct {
+	Name      string `json:"name"`
+	TurnsLeft int    `json:"turns_left"`
+}
+
+// Locks the battle against a challenger, creating it for challenges that
+// started before battles were tracked
+func lockBattle(ctx context.Context, q *database.Queries, userID uuid.UUID, challenger database.ChallengerPokemon) (database.Battle, error) {
+	id := uuid.NullUUID{UUID: challenger.ID, Valid: true}
+	if err := q.CreateBattle(ctx, database.CreateBattleParams{
+		ID:                  uuid.New(),
+		UserID:              userID,
+		ChallengerPokemonID: id,
+		ChallengerSpeciesID: challenger.PokemonID,
+		Kind:                battleKindTrainer,
+		Format:              battleFormatSingles,
+	}); err != nil {
+		return database.Battle{}, err
+	}
+	return q.GetBattleForUpdate(ctx, id)
+}
+
+func pokemonTypes(p database.Pokedex) []string {
+	if p.Type2.Valid {
+		return []string{p.Type1, p.Type2.String}
+	}
+	return []string{p.Type1}
+}
+
+// The HP a species has at full health in battle, what current_hp is measured against
+func maxHP(p database.Pokedex) int32 {
+	return int32(battle.MaxHP(int(p.Hp), battle.DefaultLevel))
+}
+
+func toBattleMove(m database.Move) battle.Move {
+	return battle.Move{
+		ID:          m.MoveID,
+		Name:        m.Name,
+		Type:        m.Type,
+		Power:       int(m.Power),
+		DamageClass: m.DamageClass,
+		Priority:    int(m.Priority),
+		MinHits:     int(m.MinHits.Int32),
+		MaxHits:     int(m.MaxHits.Int32),
+		Target:      m.Target,
+	}
+}
+
+// A species' base stats for the battle engine
+func baseStats(p database.Pokedex) battle.Stats {
+	return battle.Stats{
+		HP:             int(p.Hp),
+		Attack:         int(p.Attack),
+		Defense:        int(p.Defense),
+		SpecialAttack:  int(p.SpecialAttack),
+		SpecialDefense: int(p.SpecialDefense),
+		Speed:          int(p.Speed),
+	}
+}
+
+// Builds the battle engine's view of a pokemon from its species, moves and stored HP
+func toBattlePokemon(p database.Pokedex, moves []database.Move, hp int32, status sql.NullString) *battle.Pokemon {
+	bp := battle.NewPokemon(p.Name, pokemonTypes(p), baseStats(p), battle.DefaultLevel)
+	bp.HP = int(hp)
+	bp.Status = status.String
+	for _, m := range moves {
+		bp.Moves = append(bp.Moves, toBattleMove(m))
+	}
+	return bp
+}
+
+// A battle pokemon's status for storing, fainting clears it
+func battleStatus(p *battle.Pokemon) sql.NullString {
+	if p.Fainted() || p.Status == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: p.Status, Valid: true}
+}
+
+// Finds the move a pokemon is locked into among the moves it knows
+func lockedMove(moves []database.Move, id sql.NullInt32) *battle.Move {
+	if !id.Valid {
+		return nil
+	}
+	for _, m := range moves {
+		if m.MoveID == id.Int32 {
+			bm := toBattleMove(m)
+			return &bm
+		}
+	}
+	return nil
+}
+
+// The id of the move a pokemon is charging for storing, nothing once it's fainted
+func chargingMoveID(p *battle.Pokemon) sql.NullInt32 {
+	if p.Charging == nil || p.Fainted() {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: p.Charging.ID, Valid: true}
+}
+
+// Prize money for beating a challenger, stronger species pay more
+func battlePrize(p database.Pokedex) int32 {
+	total := p.Hp + p.Attack + p.Defense + p.SpecialAttack + p.SpecialDefense + p.Speed
+	return total * battle.DefaultLevel / 100
+}
+
+// Narrates a move hitting, through the configured describer with plain text
+// as the fallback. description is the move's own description, if it has one.
+// Names are in the localizer's language, which must have both pokemon and
+// the move loaded
+func (cfg *Config) describeEvent(ctx context.Context, loc *localizer, ev battle.Event, description string) string {
+	action := describe.ActionContext{}
+	action.Source.Name = loc.nameOf(ev.Attacker.Name)
+	action.Source.Types = ev.Attacker.Types
+	action.Source.Ability = ev.Attacker.Ability
+	action.Target.Name = loc.nameOf(ev.Defender.Name)
+	action.Target.Types = ev.Defender.Types
+	action.Target.Ability = ev.Defender.Ability
+	action.Move.ID = ev.Move.ID
+	action.Move.Name = loc.moveName(ev.Move.ID, ev.Move.Name)
+	action.Move.Type = ev.Move.Type
+	action.Move.Power = int32(ev.Move.Power)
+	action.Move.Description = description
+	action.Effectiveness = battle.EffectivenessLabel(ev.Result.Effectiveness)
+	action.Weather = ev.Weather
+	action.Language = loc.lang
+	if ev.Move.MaxHits > 1 {
+		action.Hits = ev.Result.Hits
+	}
+
+	line, err := cfg.Describer.DescribeAction(ctx, action)
+	if err != nil || line == "" {
+		if err != nil {
+			log.Printf("AI describe err: %v", err)
+		}
+		line, _ = (describe.Plain{}).DescribeAction(ctx, action)
+	}
+	return line
+}
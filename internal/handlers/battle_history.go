@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+// Battle types, must match the battles kind check
+const (
+	battleKindTrainer = "trainer"
+	battleKindWild    = "wild"
+)
+
+// Battle formats, must match the battles format check
+const (
+	battleFormatSingles = "singles"
+	battleFormatDoubles = "doubles"
+)
+
+// Battle results, must match the battles result check
+const (
+	battleWon       = "won"
+	battleLost      = "lost"
+	battleFled      = "fled"
+	battleForfeited = "forfeited"
+)
+
+const (
+	defaultBattleHistoryPageSize = 20
+	maxBattleHistoryPageSize     = 100
+
+	defaultLeaderboardPageSize = 20
+	maxLeaderboardPageSize     = 100
+)
+
+// Ends a battle the user walked away from, recording the result and removing
+// the challenger, and its partner in a double battle, so a new one can be chosen
+func endBattle(ctx context.Context, q *database.Queries, userID, battleID, challengerID uuid.UUID, result string) error {
+	if err := q.FinishBattle(ctx, database.FinishBattleParams{
+		ID:     battleID,
+		Result: sql.NullString{String: result, Valid: true},
+	}); err != nil {
+		return err
+	}
+	if err := q.SetUserChallengePokemon(ctx, database.SetUserChallengePokemonParams{
+		ID: userID,
+	}); err != nil {
+		return err
+	}
+	if err := q.DeleteChallengerPartner(ctx, uuid.NullUUID{UUID: challengerID, Valid: true}); err != nil {
+		return err
+	}
+	return q.DeleteChallengePokemon(ctx, challengerID)
+}
+
+// List the user's finished battles, newest first
+func (cfg *Config) GetBattleHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, defaultBattleHistoryPageSize, maxBattleHistoryPageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	total, err := cfg.DB.CountBattleHistory(ctx, user.ID)
+	if err != nil {
+		log.Printf("error counting battle history: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	rows, err := cfg.DB.ListBattleHistory(ctx, database.ListBattleHistoryParams{
+		UserID: user.ID,
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		log.Printf("error listing battle history: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	type battleDTO struct {
+		ID             string     `json:"id"`
+		BattleType     string     `json:"battle_type"`
+		Result         string     `json:"result"`
+		Turns          int32      `json:"turns"`
+		Challenger     string     `json:"challenger,omitempty"`
+		LastPokemonOut string     `json:"last_pokemon_out,omitempty"`
+		StartedAt      time.Time  `json:"started_at"`
+		EndedAt        *time.Time `json:"ended_at,omitempty"`
+	}
+	battles := make([]battleDTO, 0, len(rows))
+	for _, b := range rows {
+		dto := battleDTO{
+			ID:             b.ID.String(),
+			BattleType:     b.Kind,
+			Result:         b.Result.String,
+			Turns:          b.Turn,
+			Challenger:     b.ChallengerSpeciesName.String,
+			LastPokemonOut: b.UserSpeciesName.String,
+			StartedAt:      b.CreatedAt,
+		}
+		if b.EndedAt.Valid {
+			dto.EndedAt = &b.EndedAt.Time
+		}
+		battles = append(battles, dto)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"battles":   battles,
+	})
+}
+
+// Rank trainers by trainer battles won, forfeits count as losses
+func (cfg *Config) GetLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, defaultLeaderboardPageSize, maxLeaderboardPageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	total, err := cfg.DB.CountLeaderboard(ctx)
+	if err != nil {
+		log.Printf("error counting leaderboard: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	offset := (page - 1) * pageSize
+	rows, err := cfg.DB.ListLeaderboard(ctx, database.ListLeaderboardParams{
+		Limit:  int32(pageSize),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		log.Printf("error listing leaderboard: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	type standingDTO struct {
+		Rank     int    `json:"rank"`
+		Username string `json:"username"`
+		Wins     int64  `json:"wins"`
+		Losses   int64  `json:"losses"`
+	}
+	standings := make([]standingDTO, 0, len(rows))
+	for i, row := range rows {
+		standings = append(standings, standingDTO{
+			Rank:     offset + i + 1,
+			Username: row.Username,
+			Wins:     row.Wins,
+			Losses:   row.Losses,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"standings": standings,
+	})
+}
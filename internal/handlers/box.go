@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxPartySize = 6
+	// 8 boxes of 30, like the Gameboy games' PC
+	maxBoxSize = 240
+
+	defaultBoxPageSize = 30
+	maxBoxPageSize     = 100
+)
+
+// Why a pokemon can't be added to or moved between the party and the box,
+// checked with the user locked so concurrent requests can't both pass
+var (
+	errPartyFull        = errors.New("party is full")
+	errPartyAndBoxFull  = errors.New("party and box are full")
+	errBoxFull          = errors.New("box is full")
+	errLastPartyPokemon = errors.New("last pokemon in party")
+)
+
+// Locks the user for the rest of the transaction and checks there's room for
+// one more pokemon. Returns the party slot it goes in, or null if the party
+// is full and it goes in the box. With allowBox false a full party is
+// errPartyFull.
+func reservePartySpace(ctx context.Context, q *database.Queries, userID uuid.UUID, allowBox bool) (sql.NullInt32, error) {
+	if err := q.LockUser(ctx, userID); err != nil {
+		return sql.NullInt32{}, err
+	}
+	partysize, err := q.CountUserPartyPokemon(ctx, userID)
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+	if partysize >= maxPartySize {
+		if !allowBox {
+			return sql.NullInt32{}, errPartyFull
+		}
+		boxsize, err := q.CountUserBoxPokemon(ctx, userID)
+		if err != nil {
+			return sql.NullInt32{}, err
+		}
+		if boxsize >= maxBoxSize {
+			return sql.NullInt32{}, errPartyAndBoxFull
+		}
+		return sql.NullInt32{}, nil
+	}
+	// New party members go to the end of the party
+	slot, err := q.GetNextPartySlot(ctx, userID)
+	if err != nil {
+		return sql.NullInt32{}, err
+	}
+	return sql.NullInt32{Valid: true, Int32: slot}, nil
+}
+
+// Move a pokemon from the user's party into their PC box
+func (cfg *Config) DepositPokemonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, inParty)
+	if !ok {
+		return
+	}
+
+	if userPokemon.IsActive {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "You can't deposit your active pokemon, change your active pokemon first"})
+		return
+	}
+
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		if err := q.LockUser(ctx, user.ID); err != nil {
+			return err
+		}
+		partysize, err := q.CountUserPartyPokemon(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if partysize <= 1 {
+			return errLastPartyPokemon
+		}
+		boxsize, err := q.CountUserBoxPokemon(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if boxsize >= maxBoxSize {
+			return errBoxFull
+		}
+
+		// Already deposited, released or made active by another request
+		moved, err := q.MoveUserPokemonToBox(ctx, database.MoveUserPokemonToBoxParams{
+			UserID: user.ID,
+			ID:     userPokemon.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			return sql.ErrNoRows
+		}
+		// Close the gap the deposited pokemon leaves in the party order
+		return q.CompactUserPartySlots(ctx, user.ID)
+	})
+	switch {
+	case errors.Is(err, errLastPartyPokemon):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "You must keep at least one pokemon in your party"})
+		return
+	case errors.Is(err, errBoxFull):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Your PC box is full"})
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found in user's party"})
+		return
+	case err != nil:
+		log.Printf("error depositing user pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":         "Pokemon deposited in PC box successfully",
+		"user_pokemon_id": userPokemon.ID.String(),
+		"pokemon_id":      strconv.Itoa(int(userPokemon.PokemonID.Int32)),
+		"user_username":   user.Username,
+	})
+}
+
+// Move a pokemon from the user's PC box back into their party
+func (cfg *Config) WithdrawPokemonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, inBox)
+	if !ok {
+		return
+	}
+
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		slot, err := reservePartySpace(ctx, q, user.ID, false)
+		if err != nil {
+			return err
+		}
+		// Already withdrawn or released by another request
+		moved, err := q.MoveUserPokemonToParty(ctx, database.MoveUserPokemonToPartyParams{
+			UserID:    user.ID,
+			ID:        userPokemon.ID,
+			PartySlot: slot,
+		})
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errPartyFull):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "You can only have at most six pokemon in your party"})
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found in user's PC box"})
+		return
+	case err != nil:
+		log.Printf("error withdrawing user pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":         "Pokemon withdrawn from PC box successfully",
+		"user_pokemon_id": userPokemon.ID.String(),
+		"pokemon_id":      strconv.Itoa(int(userPokemon.PokemonID.Int32)),
+		"user_username":   user.Username,
+	})
+}
+
+// List the pokemon stored in the user's PC box, oldest first
+func (cfg *Config) GetBoxPokemonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, defaultBoxPageSize, maxBoxPageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	total, err := cfg.DB.CountUserBoxPokemon(ctx, user.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve Pokémon"})
+		return
+	}
+
+	pokemonList, err := cfg.DB.ListUserBoxPokemon(ctx, database.ListUserBoxPokemonParams{
+		UserID: user.ID,
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve Pokémon"})
+		return
+	}
+
+	species := make([]database.Pokedex, 0, len(pokemonList))
+	for _, p := range pokemonList {
+		species = append(species, database.Pokedex{ID: p.ID, Name: p.Name})
+	}
+	loc.loadSpecies(ctx, species...)
+
+	response := struct {
+		Page     int               `json:"page"`
+		PageSize int               `json:"page_size"`
+		Total    int64             `json:"total"`
+		Pokemon  []PokedexResponse `json:"pokemon"`
+	}{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Pokemon:  make([]PokedexResponse, 0, len(pokemonList)),
+	}
+	for i, p := range pokemonList {
+		type2 := ""
+		if p.Type2.Valid {
+			type2 = p.Type2.String
+		}
+		img := ""
+		if p.ImageUrl.Valid {
+			img = p.ImageUrl.String
+		}
+		response.Pokemon = append(response.Pokemon, PokedexResponse{
+			ID:             p.ID,
+			Name:           p.Name,
+			DisplayName:    loc.speciesName(species[i]),
+			Type1:          p.Type1,
+			Type2:          type2,
+			Hp:             p.Hp,
+			Attack:         p.Attack,
+			Defense:        p.Defense,
+			SpecialAttack:  p.SpecialAttack,
+			SpecialDefense: p.SpecialDefense,
+			Speed:          p.Speed,
+			ImageUrl:       img,
+			Nickname:       p.Nickname.String,
+			UserPokemonID:  p.UserPokemonID.String(),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+	"github.com/google/uuid"
+)
+
+const (
+	minCalcLevel = 1
+	maxCalcLevel = 100
+)
+
+// One side of a damage calculation
+type calcSideDTO struct {
+	Name          string `json:"name"`
+	DisplayName   string `json:"display_name"`
+	UserPokemonID string `json:"user_pokemon_id,omitempty"`
+	Level         int    `json:"level"`
+	Ability       string `json:"ability,omitempty"`
+	HeldItem      string `json:"held_item,omitempty"`
+	Stage         int    `json:"stat_stage"`
+	CurrentHP     int    `json:"current_hp"`
+	MaxHP         int    `json:"max_hp"`
+}
+
+// Reads an optional integer query parameter within [lo, hi]
+func queryInt(r *http.Request, name string, def, lo, hi int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%s must be a whole number from %d to %d", name, lo, hi)
+	}
+	return n, nil
+}
+
+// Loads the attacker or defender of a calculation, either one of the user's
+// pokemon by <side>_user_pokemon_id or any species by <side>. Owned pokemon
+// bring their ability, held item, status and, at level 50, their current HP
+func (cfg *Config) calcPokemon(w http.ResponseWriter, r *http.Request, loc *localizer, userID uuid.UUID, side string) (*battle.Pokemon, calcSideDTO, bool) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	level, err := queryInt(r, side+"_level", battle.DefaultLevel, minCalcLevel, maxCalcLevel)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return nil, calcSideDTO{}, false
+	}
+
+	var owned *database.UserPokemon
+	if idStr := query.Get(side + "_user_pokemon_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": side + "_user_pokemon_id must be a valid UUID"})
+			return nil, calcSideDTO{}, false
+		}
+		up, err := cfg.DB.GetUserPokemonByID(ctx, database.GetUserPokemonByIDParams{
+			UserID: userID,
+			ID:     id,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found for user"})
+				return nil, calcSideDTO{}, false
+			}
+			log.Printf("error getting user pokemon: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return nil, calcSideDTO{}, false
+		}
+		owned = &up
+	} else if query.Get(side) == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("%s or %s_user_pokemon_id is required", side, side)})
+		return nil, calcSideDTO{}, false
+	}
+
+	var species *database.Pokedex
+	if owned != nil {
+		p, err := cfg.DB.FetchPokemonDataById(ctx, owned.PokemonID.Int32)
+		if err != nil {
+			log.Printf("error fetching pokemon data: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return nil, calcSideDTO{}, false
+		}
+		species = &p
+	} else {
+		species, err = cfg.GetPokemon(ctx, query.Get(side))
+		if err != nil {
+			log.Printf("error checking for existing pokemon: %s", err)
+			writeLookupError(w, err)
+			return nil, calcSideDTO{}, false
+		}
+	}
+
+	p := battle.NewPokemon(species.Name, pokemonTypes(*species), baseStats(*species), level)
+	if owned != nil {
+		// Stored HP is on the level 50 scale, so only carries over at level 50
+		if level == battle.DefaultLevel {
+			p.HP = int(owned.CurrentHp)
+		}
+		p.Status = owned.Status.String
+		p.Ability = owned.Ability.String
+		if p.HeldItem, err = heldItemName(ctx, cfg.DB, owned.HeldItemID); err != nil {
+			log.Printf("error getting held item: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return nil, calcSideDTO{}, false
+		}
+	}
+
+	loc.loadSpecies(ctx, *species)
+	dto := calcSideDTO{
+		Name:        species.Name,
+		DisplayName: loc.speciesName(*species),
+		Level:       level,
+		Ability:     p.Ability,
+		HeldItem:    p.HeldItem,
+		CurrentHP:   p.HP,
+		MaxHP:       p.Stats.HP,
+	}
+	if owned != nil {
+		dto.UserPokemonID = owned.ID.String()
+	}
+	return p, dto, true
+}
+
+// Works out what a move would do from one pokemon to another without a
+// battle, using the same engine as Fight
+func (cfg *Config) CalculateDamageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	moveIdentifier := query.Get("move")
+	if moveIdentifier == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "move is required"})
+		return
+	}
+	weather := query.Get("weather")
+	switch weather {
+	case "", battle.Rain, battle.Sun, battle.Sandstorm, battle.Hail:
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "weather must be rain, sun, sandstorm or hail"})
+		return
+	}
+	attackStage, err := queryInt(r, "attack_stage", 0, -6, 6)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	defenseStage, err := queryInt(r, "defense_stage", 0, -6, 6)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	attacker, attackerDTO, ok := cfg.calcPokemon(w, r, loc, user.ID, "attacker")
+	if !ok {
+		return
+	}
+	defender, defenderDTO, ok := cfg.calcPokemon(w, r, loc, user.ID, "defender")
+	if !ok {
+		return
+	}
+	attacker.Stages.Attack, attacker.Stages.SpecialAttack = attackStage, attackStage
+	defender.Stages.Defense, defender.Stages.SpecialDefense = defenseStage, defenseStage
+	attackerDTO.Stage, defenderDTO.Stage = attackStage, defenseStage
+
+	move, err := cfg.GetMove(ctx, moveIdentifier)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("No move called %q", moveIdentifier)})
+		return
+	} else if err != nil {
+		log.Printf("error getting move: %s", err)
+		writeLookupError(w, err)
+		return
+	}
+
+	field := &battle.Field{}
+	if weather != "" {
+		field.Weather, field.WeatherTurns = weather, battle.WeatherDuration
+	}
+	calc := battle.Calculate(field, attacker, defender, toBattleMove(move))
+	loc.loadMoves(ctx, move)
+
+	type rangeDTO struct {
+		Min        int     `json:"min"`
+		Max        int     `json:"max"`
+		MinPercent float64 `json:"min_percent"`
+		MaxPercent float64 `json:"max_percent"`
+	}
+	// Percent of the defender's max HP, to one decimal place
+	toRange := func(lo, hi int) rangeDTO {
+		percent := func(d int) float64 {
+			return math.Round(float64(d)*1000/float64(defender.Stats.HP)) / 10
+		}
+		return rangeDTO{Min: lo, Max: hi, MinPercent: percent(lo), MaxPercent: percent(hi)}
+	}
+
+	type moveDTO struct {
+		ID          int32  `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+		Type        string `json:"type"`
+		Power       int32  `json:"power"`
+		DamageClass string `json:"damage_class"`
+	}
+
+	type hitsDTO struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}
+
+	type calcResp struct {
+		Attacker       calcSideDTO `json:"attacker"`
+		Defender       calcSideDTO `json:"defender"`
+		Move           moveDTO     `json:"move"`
+		Weather        string      `json:"weather,omitempty"`
+		Effectiveness  string      `json:"effectiveness,omitempty"`
+		TypeMultiplier float64     `json:"type_multiplier"`
+		STAB           bool        `json:"stab"`
+		Damage         rangeDTO    `json:"damage"`
+		CriticalDamage rangeDTO    `json:"critical_damage"`
+		Hits           *hitsDTO    `json:"hits,omitempty"`
+		KOChance       float64     `json:"ko_chance"`
+	}
+
+	resp := calcResp{
+		Attacker: attackerDTO,
+		Defender: defenderDTO,
+		Move: moveDTO{
+			ID:          move.MoveID,
+			Name:        move.Name,
+			DisplayName: loc.moveName(move.MoveID, move.Name),
+			Type:        move.Type,
+			Power:       move.Power,
+			DamageClass: move.DamageClass,
+		},
+		Weather:        weather,
+		Effectiveness:  battle.EffectivenessLabel(calc.Effectiveness),
+		TypeMultiplier: calc.Effectiveness,
+		STAB:           calc.STAB,
+		Damage:         toRange(calc.Min, calc.Max),
+		CriticalDamage: toRange(calc.CritMin, calc.CritMax),
+		KOChance:       math.Round(calc.KOChance*10000) / 10000,
+	}
+	if calc.MaxHits > 1 {
+		resp.Hits = &hitsDTO{Min: calc.MinHits, Max: calc.MaxHits}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
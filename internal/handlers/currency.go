@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// Money every new trainer starts with
+	startingBalance = 3000
+
+	maxPurchaseQuantity = 99
+
+	defaultLedgerPageSize = 20
+	maxLedgerPageSize     = 100
+)
+
+// Ledger reasons, must match the currency_ledger reason check
+const (
+	reasonStartingBalance = "starting_balance"
+	reasonBattlePrize     = "battle_prize"
+	reasonShopPurchase    = "shop_purchase"
+)
+
+var ErrInsufficientFunds = errors.New("you can't afford that")
+
+// Changes a user's balance and records it in the ledger. Run it inside a
+// transaction with whatever the money is for. Debits fail with
+// ErrInsufficientFunds rather than taking the balance below zero.
+func adjustBalance(ctx context.Context, q *database.Queries, userID uuid.UUID, amount int32, reason, reference string) (int32, error) {
+	var (
+		balance int32
+		err     error
+	)
+	if amount >= 0 {
+		balance, err = q.CreditUserBalance(ctx, database.CreditUserBalanceParams{Amount: amount, UserID: userID})
+	} else {
+		balance, err = q.DebitUserBalance(ctx, database.DebitUserBalanceParams{Amount: -amount, UserID: userID})
+		if err == sql.ErrNoRows {
+			return 0, ErrInsufficientFunds
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := q.InsertLedgerEntry(ctx, database.InsertLedgerEntryParams{
+		ID:           uuid.New(),
+		UserID:       userID,
+		Amount:       amount,
+		BalanceAfter: balance,
+		Reason:       reason,
+		Reference:    sql.NullString{String: reference, Valid: reference != ""},
+	}); err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+type shopItemDTO struct {
+	ID       int32  `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Effect   string `json:"effect,omitempty"`
+	ImageUrl string `json:"image_url,omitempty"`
+	Price    int32  `json:"price"`
+}
+
+// List what the shop sells and the user's balance
+func (cfg *Config) GetShopHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	stock, err := cfg.DB.ListShopItems(ctx)
+	if err != nil {
+		log.Printf("error listing shop items: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve shop"})
+		return
+	}
+
+	items := make([]shopItemDTO, 0, len(stock))
+	for _, s := range stock {
+		// First view of an item caches it from PokéAPI, skip it if that fails
+		item, err := cfg.GetItem(ctx, s.ItemName)
+		if err != nil {
+			log.Printf("error getting shop item %s: %s", s.ItemName, err)
+			continue
+		}
+		items = append(items, shopItemDTO{
+			ID:       item.ID,
+			Name:     item.Name,
+			Category: item.Category,
+			Effect:   item.Effect.String,
+			ImageUrl: item.ImageUrl.String,
+			Price:    s.Price,
+		})
+	}
+
+	balance, err := cfg.DB.GetUserBalance(ctx, user.ID)
+	if err != nil {
+		log.Printf("error getting user balance: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"balance": balance,
+		"items":   items,
+	})
+}
+
+// Buy items from the shop into the user's bag
+func (cfg *Config) BuyItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	identifier := strings.TrimSpace(r.PostForm.Get("item_identifier"))
+	if identifier == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "item_identifier is required"})
+		return
+	}
+
+	quantity := 1
+	if v := r.PostForm.Get("quantity"); v != "" {
+		q, err := strconv.Atoi(v)
+		if err != nil || q < 1 || q > maxPurchaseQuantity {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("quantity must be between 1 and %d", maxPurchaseQuantity)})
+			return
+		}
+		quantity = q
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Names go straight to the shop, ids are resolved through the item catalog
+	name := strings.ToLower(identifier)
+	if _, convErr := strconv.Atoi(identifier); convErr == nil {
+		cached, err := cfg.getCachedItem(ctx, identifier)
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "That item isn't sold in the shop"})
+			return
+		} else if err != nil {
+			log.Printf("error getting item: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return
+		}
+		name = cached.Name
+	}
+
+	shopItem, err := cfg.DB.GetShopItem(ctx, name)
+	if err == sql.ErrNoRows {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "That item isn't sold in the shop"})
+		return
+	} else if err != nil {
+		log.Printf("error getting shop item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	item, err := cfg.GetItem(ctx, shopItem.ItemName)
+	if err != nil {
+		log.Printf("error getting item: %s", err)
+		writeLookupError(w, err)
+		return
+	}
+
+	total := shopItem.Price * int32(quantity)
+	var balance, owned int32
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		balance, err = adjustBalance(ctx, q, user.ID, -total, reasonShopPurchase, fmt.Sprintf("%s x%d", item.Name, quantity))
+		if err != nil {
+			return err
+		}
+		owned, err = q.AddUserItem(ctx, database.AddUserItemParams{
+			UserID:   user.ID,
+			ItemID:   item.ID,
+			Quantity: int32(quantity),
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientFunds) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("You can't afford that, it costs %d", total)})
+			return
+		}
+		log.Printf("error buying item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  fmt.Sprintf("Bought %d %s", quantity, item.Name),
+		"item":     item.Name,
+		"quantity": owned,
+		"spent":    total,
+		"balance":  balance,
+	})
+}
+
+// Show the user's balance and their ledger, newest first
+func (cfg *Config) GetBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, defaultLedgerPageSize, maxLedgerPageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	balance, err := cfg.DB.GetUserBalance(ctx, user.ID)
+	if err != nil {
+		log.Printf("error getting user balance: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	total, err := cfg.DB.CountLedgerEntries(ctx, user.ID)
+	if err != nil {
+		log.Printf("error counting ledger entries: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	entries, err := cfg.DB.ListLedgerEntries(ctx, database.ListLedgerEntriesParams{
+		UserID: user.ID,
+		Limit:  int32(pageSize),
+		Offset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		log.Printf("error listing ledger entries: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	type ledgerDTO struct {
+		ID           string    `json:"id"`
+		Amount       int32     `json:"amount"`
+		BalanceAfter int32     `json:"balance_after"`
+		Reason       string    `json:"reason"`
+		Reference    string    `json:"reference,omitempty"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+	ledger := make([]ledgerDTO, 0, len(entries))
+	for _, e := range entries {
+		ledger = append(ledger, ledgerDTO{
+			ID:           e.ID.String(),
+			Amount:       e.Amount,
+			BalanceAfter: e.BalanceAfter,
+			Reason:       e.Reason,
+			Reference:    e.Reference.String,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"balance":   balance,
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"ledger":    ledger,
+	})
+}
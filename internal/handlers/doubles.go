@@ -0,0 +1,718 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+// Names of the four slots in a double battle, used for targets in requests
+// and for sides in responses
+var doublesSlotNames = map[battle.Slot]string{
+	{Side: battle.UserSide, Position: 0}:       "user",
+	{Side: battle.UserSide, Position: 1}:       "user_partner",
+	{Side: battle.ChallengerSide, Position: 0}: "challenger",
+	{Side: battle.ChallengerSide, Position: 1}: "challenger_partner",
+}
+
+var (
+	userLeadSlot       = battle.Slot{Side: battle.UserSide, Position: 0}
+	userPartnerSlot    = battle.Slot{Side: battle.UserSide, Position: 1}
+	challengerLeadSlot = battle.Slot{Side: battle.ChallengerSide, Position: 0}
+)
+
+// Parses a target slot name, moves aim at the challenger's lead by default
+func parseDoublesTarget(name string) (battle.Slot, bool) {
+	if name == "" {
+		return challengerLeadSlot, true
+	}
+	for slot, n := range doublesSlotNames {
+		if n == name {
+			return slot, true
+		}
+	}
+	return battle.Slot{}, false
+}
+
+// The user's battle against their current challenger, ok is false when there
+// isn't one yet
+func currentBattle(ctx context.Context, q *database.Queries, user *database.User) (database.Battle, bool, error) {
+	if !user.ChallengePokemonID.Valid {
+		return database.Battle{}, false, nil
+	}
+	b, err := q.GetBattle(ctx, user.ChallengePokemonID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Battle{}, false, nil
+	}
+	if err != nil {
+		return database.Battle{}, false, err
+	}
+	return b, true, nil
+}
+
+// The user's second pokemon out in a double battle: the one already out if it
+// can still fight, otherwise the first healthy party member that isn't active
+func pickPartner(party []database.UserPokemon, activeID uuid.UUID, current uuid.NullUUID) (database.UserPokemon, bool) {
+	var next *database.UserPokemon
+	for i, p := range party {
+		if p.ID == activeID || p.CurrentHp <= 0 {
+			continue
+		}
+		if current.Valid && p.ID == current.UUID {
+			return p, true
+		}
+		if next == nil {
+			next = &party[i]
+		}
+	}
+	if next == nil {
+		return database.UserPokemon{}, false
+	}
+	return *next, true
+}
+
+// One pokemon in a double battle with everything loaded about it
+type doublesMember struct {
+	species database.Pokedex
+	moves   []database.Move
+	pokemon *battle.Pokemon
+}
+
+func loadDoublesMember(ctx context.Context, q *database.Queries, pokemonID, hp int32, status, ability sql.NullString, heldItemID sql.NullInt32) (*doublesMember, error) {
+	species, err := q.FetchPokemonDataById(ctx, pokemonID)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := q.GetPokemonMoves(ctx, pokemonID)
+	if err != nil {
+		return nil, err
+	}
+	p := toBattlePokemon(species, moves, hp, status)
+	p.Ability = ability.String
+	if p.HeldItem, err = heldItemName(ctx, q, heldItemID); err != nil {
+		return nil, err
+	}
+	return &doublesMember{species: species, moves: moves, pokemon: p}, nil
+}
+
+// A move the user picked for one of their pokemon
+type doublesChoice struct {
+	moveID string
+	target battle.Slot
+}
+
+var errInvalidChoice = errors.New("invalid move or target")
+
+// Plays one turn of a double battle. Each of the user's pokemon picks a move
+// and a target, the challengers pick theirs at random. Items can't be used
+// yet, and running always forfeits since double battles are trainer battles
+func (cfg *Config) playDoublesTurn(w http.ResponseWriter, r *http.Request, user *database.User, run bool, loc *localizer) {
+	var choices [2]doublesChoice
+	if !run {
+		if r.PostForm.Get("item_identifier") != "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Items can't be used in double battles"})
+			return
+		}
+		for i, field := range [2][2]string{{"move_id", "target"}, {"partner_move_id", "partner_target"}} {
+			target, ok := parseDoublesTarget(r.PostForm.Get(field[1]))
+			if !ok {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": field[1] + " must be one of challenger, challenger_partner, user or user_partner"})
+				return
+			}
+			if target == (battle.Slot{Side: battle.UserSide, Position: i}) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "A pokemon can't target itself"})
+				return
+			}
+			choices[i] = doublesChoice{moveID: r.PostForm.Get(field[0]), target: target}
+		}
+	}
+
+	ctx := r.Context()
+	activePokemon, err := cfg.DB.GetActiveUserPokemon(ctx, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "No active pokemon found"})
+			return
+		}
+		log.Printf("error getting active pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	challengePokemon, err := cfg.DB.GetUserChallengePokemon(ctx, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "No challenge pokemon found"})
+			return
+		}
+		log.Printf("error getting challenge pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var (
+		turn           battle.Turn
+		field          battle.DoubleField
+		members        [2][2]*doublesMember
+		userIDs        [2]uuid.UUID // the user's pokemon in each slot
+		prize, balance int32
+		partyFainted   bool
+		choiceErr      string
+		outcome        string // set when the battle ends without a turn
+		timedOut       bool
+		challengerName string
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		lead, err := q.GetChallengePokemonForUpdate(ctx, challengePokemon.ID)
+		if err != nil {
+			return err
+		}
+		state, err := lockBattle(ctx, q, user.ID, lead)
+		if err != nil {
+			return err
+		}
+		if state.Result.Valid {
+			return errBattleOver
+		}
+		leadSpecies, err := q.FetchPokemonDataById(ctx, lead.PokemonID.Int32)
+		if err != nil {
+			return err
+		}
+		challengerName = leadSpecies.Name
+		if cfg.turnTimedOut(state) {
+			outcome, timedOut = battleForfeited, true
+			return endBattle(ctx, q, user.ID, state.ID, lead.ID, outcome)
+		}
+		if run {
+			outcome = battleForfeited
+			return endBattle(ctx, q, user.ID, state.ID, lead.ID, outcome)
+		}
+
+		// The challenger's side
+		challengers := []database.ChallengerPokemon{lead}
+		if state.ChallengerPartnerID.Valid {
+			partner, err := q.GetChallengePokemonForUpdate(ctx, state.ChallengerPartnerID.UUID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			if err == nil {
+				challengers = append(challengers, partner)
+			}
+		}
+		for pos, c := range challengers {
+			m, err := loadDoublesMember(ctx, q, c.PokemonID.Int32, c.CurrentHp, c.Status, c.Ability, c.HeldItemID)
+			if err != nil {
+				return err
+			}
+			members[battle.ChallengerSide][pos] = m
+			field.Slots[battle.ChallengerSide][pos] = m.pokemon
+		}
+		if field.SideFainted(battle.ChallengerSide) {
+			return errChallengerFainted
+		}
+		field.Slots[battle.ChallengerSide][0].Charging = lockedMove(members[battle.ChallengerSide][0].moves, state.ChallengerChargingMoveID)
+		field.Slots[battle.ChallengerSide][0].Recharging = state.ChallengerRecharging
+		if cp := field.Slots[battle.ChallengerSide][1]; cp != nil {
+			cp.Charging = lockedMove(members[battle.ChallengerSide][1].moves, state.ChallengerPartnerChargingMoveID)
+			cp.Recharging = state.ChallengerPartnerRecharging
+		}
+
+		// The user's side, their active pokemon leads and the next healthy
+		// party member fills in beside it
+		active, err := q.GetUserPokemonForUpdate(ctx, activePokemon.ID)
+		if err != nil {
+			return err
+		}
+		if active.CurrentHp <= 0 {
+			return errActiveFainted
+		}
+		party, err := q.GetUserPartyPokemon(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		users := []database.UserPokemon{active}
+		if p, ok := pickPartner(party, active.ID, state.UserPartnerID); ok {
+			partner, err := q.GetUserPokemonForUpdate(ctx, p.ID)
+			if err != nil {
+				return err
+			}
+			users = append(users, partner)
+		}
+		for pos, u := range users {
+			m, err := loadDoublesMember(ctx, q, u.PokemonID.Int32, u.CurrentHp, u.Status, u.Ability, u.HeldItemID)
+			if err != nil {
+				return err
+			}
+			members[battle.UserSide][pos] = m
+			field.Slots[battle.UserSide][pos] = m.pokemon
+			userIDs[pos] = u.ID
+		}
+		// A charging or recharging pokemon is locked in until it's switched out
+		if state.UserPokemonID.Valid && state.UserPokemonID.UUID == active.ID {
+			field.Slots[battle.UserSide][0].Charging = lockedMove(members[battle.UserSide][0].moves, state.UserChargingMoveID)
+			field.Slots[battle.UserSide][0].Recharging = state.UserRecharging
+		}
+		if p := field.Slots[battle.UserSide][1]; p != nil && state.UserPartnerID.Valid && state.UserPartnerID.UUID == userIDs[1] {
+			p.Charging = lockedMove(members[battle.UserSide][1].moves, state.UserPartnerChargingMoveID)
+			p.Recharging = state.UserPartnerRecharging
+		}
+
+		var actions []battle.Action
+		for pos, p := range field.Slots[battle.UserSide] {
+			if p == nil {
+				continue
+			}
+			slot := battle.Slot{Side: battle.UserSide, Position: pos}
+			name, choice := doublesSlotNames[slot], choices[pos]
+			var move *battle.Move
+			if choice.moveID != "" {
+				for _, m := range members[battle.UserSide][pos].moves {
+					if strconv.Itoa(int(m.MoveID)) == choice.moveID {
+						bm := toBattleMove(m)
+						move = &bm
+						break
+					}
+				}
+				if move == nil {
+					choiceErr = fmt.Sprintf("Invalid move ID for %s", name)
+					return errInvalidChoice
+				}
+			}
+			switch {
+			case p.Charging != nil && move != nil && move.ID != p.Charging.ID:
+				choiceErr = fmt.Sprintf("%s is charging %s and must use it", p.Name, p.Charging.Name)
+				return errLockedIn
+			case p.Recharging && move != nil:
+				choiceErr = fmt.Sprintf("%s must recharge this turn, send no move for it", p.Name)
+				return errLockedIn
+			case p.Charging == nil && !p.Recharging && move == nil:
+				choiceErr = fmt.Sprintf("A move is required for %s", name)
+				return errInvalidChoice
+			}
+			actions = append(actions, battle.Action{Slot: slot, Move: move, Target: choice.target})
+		}
+
+		// Challengers pick a random move and a random one of the user's pokemon
+		var userTargets []battle.Slot
+		for pos, p := range field.Slots[battle.UserSide] {
+			if p != nil && !p.Fainted() {
+				userTargets = append(userTargets, battle.Slot{Side: battle.UserSide, Position: pos})
+			}
+		}
+		for pos, p := range field.Slots[battle.ChallengerSide] {
+			if p == nil || p.Fainted() {
+				continue
+			}
+			actions = append(actions, battle.Action{
+				Slot:   battle.Slot{Side: battle.ChallengerSide, Position: pos},
+				Move:   battle.ChooseMove(rng, p),
+				Target: userTargets[rng.Intn(len(userTargets))],
+			})
+		}
+
+		field.Field = battle.Field{Weather: state.Weather.String, WeatherTurns: int(state.WeatherTurns)}
+
+		// Stat stages last until a pokemon is switched out
+		leadSwitchedIn := !state.UserPokemonID.Valid || state.UserPokemonID.UUID != userIDs[0]
+		partnerSwitchedIn := field.Slots[battle.UserSide][1] != nil && (!state.UserPartnerID.Valid || state.UserPartnerID.UUID != userIDs[1])
+		if !leadSwitchedIn {
+			field.Slots[battle.UserSide][0].Stages.Attack = int(state.UserAttackStage)
+		}
+		if p := field.Slots[battle.UserSide][1]; p != nil && !partnerSwitchedIn {
+			p.Stages.Attack = int(state.UserPartnerAttackStage)
+		}
+		field.Slots[battle.ChallengerSide][0].Stages.Attack = int(state.ChallengerAttackStage)
+		if p := field.Slots[battle.ChallengerSide][1]; p != nil {
+			p.Stages.Attack = int(state.ChallengerPartnerAttackStage)
+		}
+
+		// Entry abilities like intimidate and drizzle trigger when their
+		// pokemon first comes out
+		var entry []battle.Effect
+		entered := func(s battle.Slot) {
+			p := field.At(s)
+			if p == nil {
+				return
+			}
+			entry = append(entry, field.EntryAbility(s)...)
+			if e := battle.EntryWeather(&field.Field, p); e != nil {
+				entry = append(entry, *e)
+			}
+		}
+		if state.Turn == 0 {
+			entered(battle.Slot{Side: battle.ChallengerSide, Position: 0})
+			entered(battle.Slot{Side: battle.ChallengerSide, Position: 1})
+		}
+		if leadSwitchedIn {
+			entered(battle.Slot{Side: battle.UserSide, Position: 0})
+		}
+		if partnerSwitchedIn {
+			entered(battle.Slot{Side: battle.UserSide, Position: 1})
+		}
+
+		turn = battle.ResolveDoublesTurn(rng, &field, actions)
+		turn.Effects = append(entry, turn.Effects...)
+
+		params := database.UpdateBattleStateParams{
+			ID:            state.ID,
+			UserPokemonID: uuid.NullUUID{UUID: userIDs[0], Valid: true},
+			Turn:          state.Turn + 1,
+			Weather:       sql.NullString{String: field.Weather, Valid: field.Weather != ""},
+			WeatherTurns:  int32(field.WeatherTurns),
+			FleeAttempts:  state.FleeAttempts,
+		}
+		lead0, cLead := field.Slots[battle.UserSide][0], field.Slots[battle.ChallengerSide][0]
+		params.UserChargingMoveID = chargingMoveID(lead0)
+		params.UserRecharging = lead0.Recharging && !lead0.Fainted()
+		params.UserAttackStage = int32(lead0.Stages.Attack)
+		params.ChallengerChargingMoveID = chargingMoveID(cLead)
+		params.ChallengerRecharging = cLead.Recharging && !cLead.Fainted()
+		params.ChallengerAttackStage = int32(cLead.Stages.Attack)
+		if p := field.Slots[battle.UserSide][1]; p != nil {
+			params.UserPartnerID = uuid.NullUUID{UUID: userIDs[1], Valid: true}
+			params.UserPartnerChargingMoveID = chargingMoveID(p)
+			params.UserPartnerRecharging = p.Recharging && !p.Fainted()
+			params.UserPartnerAttackStage = int32(p.Stages.Attack)
+		}
+		if p := field.Slots[battle.ChallengerSide][1]; p != nil {
+			params.ChallengerPartnerChargingMoveID = chargingMoveID(p)
+			params.ChallengerPartnerRecharging = p.Recharging && !p.Fainted()
+			params.ChallengerPartnerAttackStage = int32(p.Stages.Attack)
+		}
+		if err := q.UpdateBattleState(ctx, params); err != nil {
+			return err
+		}
+
+		// Save HP and status, and drop single-use held items that were used up
+		for pos, u := range users {
+			p := field.Slots[battle.UserSide][pos]
+			if u.HeldItemID.Valid && p.HeldItem == "" {
+				if err := q.SetUserPokemonHeldItem(ctx, database.SetUserPokemonHeldItemParams{
+					UserID: user.ID,
+					ID:     u.ID,
+				}); err != nil {
+					return err
+				}
+			}
+			if err := q.SetUserPokemonHealth(ctx, database.SetUserPokemonHealthParams{
+				UserID:    user.ID,
+				ID:        u.ID,
+				CurrentHp: int32(p.HP),
+				Status:    battleStatus(p),
+			}); err != nil {
+				return err
+			}
+		}
+		for pos, c := range challengers {
+			p := field.Slots[battle.ChallengerSide][pos]
+			if c.HeldItemID.Valid && p.HeldItem == "" {
+				if err := q.ClearChallengePokemonHeldItem(ctx, c.ID); err != nil {
+					return err
+				}
+			}
+			if err := q.SetChallengePokemonHealth(ctx, database.SetChallengePokemonHealthParams{
+				ID:        c.ID,
+				CurrentHp: int32(p.HP),
+				Status:    battleStatus(p),
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Beating both challengers wins, and pays for each of them
+		if field.SideFainted(battle.ChallengerSide) {
+			if err := q.FinishBattle(ctx, database.FinishBattleParams{
+				ID:     state.ID,
+				Result: sql.NullString{String: battleWon, Valid: true},
+			}); err != nil {
+				return err
+			}
+			for _, m := range members[battle.ChallengerSide] {
+				if m != nil {
+					prize += battlePrize(m.species)
+				}
+			}
+			balance, err = adjustBalance(ctx, q, user.ID, prize, reasonBattlePrize,
+				fmt.Sprintf("defeated %s in a double battle (%s)", challengerName, lead.ID))
+			if err != nil {
+				return err
+			}
+		}
+
+		userFainted := false
+		for _, p := range field.Slots[battle.UserSide] {
+			userFainted = userFainted || (p != nil && p.Fainted())
+		}
+		if userFainted {
+			party, err := q.GetUserPartyPokemon(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			partyFainted = true
+			for _, p := range party {
+				if p.CurrentHp > 0 {
+					partyFainted = false
+					break
+				}
+			}
+			if partyFainted {
+				return q.FinishBattle(ctx, database.FinishBattleParams{
+					ID:     state.ID,
+					Result: sql.NullString{String: battleLost, Valid: true},
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errChallengerFainted):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "The challengers have already fainted, choose a new challenger"})
+		case errors.Is(err, errBattleOver):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "This battle is over, choose a new challenger"})
+		case errors.Is(err, errLockedIn), errors.Is(err, errInvalidChoice):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": choiceErr})
+		case errors.Is(err, errActiveFainted):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Your active pokemon has fainted, heal it or change your active pokemon"})
+		default:
+			log.Printf("error resolving double battle turn: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+		return
+	}
+
+	if outcome == battleForfeited {
+		message := fmt.Sprintf("You forfeited the double battle against %s. It counts as a loss.", challengerName)
+		if timedOut {
+			message = fmt.Sprintf("You took too long to move and forfeited the double battle against %s. It counts as a loss.", challengerName)
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"result":  battleForfeited,
+			"message": message,
+		})
+		return
+	}
+
+	type moveDTO struct {
+		ID          int32   `json:"id"`
+		Name        string  `json:"name"`
+		DisplayName string  `json:"display_name"`
+		Type        string  `json:"type"`
+		Power       int32   `json:"power"`
+		Target      string  `json:"target"`
+		Description *string `json:"description,omitempty"`
+	}
+
+	type targetDTO struct {
+		Side          string `json:"side"`
+		Name          string `json:"name"`
+		DisplayName   string `json:"display_name"`
+		Damage        int    `json:"damage"`
+		Effectiveness string `json:"effectiveness,omitempty"`
+		Critical      bool   `json:"critical,omitempty"`
+		Hits          int    `json:"hits,omitempty"`
+		Fainted       bool   `json:"fainted"`
+	}
+
+	type slotDTO struct {
+		UserPokemonID     string      `json:"user_pokemon_id,omitempty"`
+		Name              string      `json:"name"`
+		DisplayName       string      `json:"display_name"`
+		MoveUsed          *moveDTO    `json:"move_used,omitempty"`
+		Targets           []targetDTO `json:"targets,omitempty"`
+		Spread            bool        `json:"spread,omitempty"`
+		Ability           string      `json:"ability,omitempty"`
+		Status            string      `json:"status,omitempty"`
+		ActionDescription string      `json:"action_description"`
+		Damage            int         `json:"damage"`
+		Charging          string      `json:"charging,omitempty"`
+		Recharging        bool        `json:"recharging,omitempty"`
+		CurrentHP         int32       `json:"current_hp"`
+		MaxHP             int32       `json:"max_hp"`
+		Fainted           bool        `json:"fainted"`
+	}
+
+	type effectDTO struct {
+		Side     string `json:"side"` // a slot name or "field"
+		Source   string `json:"source"`
+		HPChange int    `json:"hp_change"`
+		Message  string `json:"message"`
+	}
+
+	type doublesResp struct {
+		Format            string      `json:"battle_format"`
+		User              *slotDTO    `json:"user"`
+		UserPartner       *slotDTO    `json:"user_partner"`
+		Challenger        *slotDTO    `json:"challenger"`
+		ChallengerPartner *slotDTO    `json:"challenger_partner"`
+		Effects           []effectDTO `json:"effects"`
+		Weather           *weatherDTO `json:"weather"`
+		Result            string      `json:"result"` // "ongoing", "won" or "lost"
+		Kind              string      `json:"battle_type"`
+		Deadline          *time.Time  `json:"turn_deadline,omitempty"`
+		Message           string      `json:"message,omitempty"`
+		Prize             int32       `json:"prize,omitempty"`
+		Balance           *int32      `json:"balance,omitempty"`
+	}
+
+	var (
+		species []database.Pokedex
+		moves   []database.Move
+	)
+	for _, side := range members {
+		for _, m := range side {
+			if m != nil {
+				species = append(species, m.species)
+				moves = append(moves, m.moves...)
+			}
+		}
+	}
+	loc.loadSpecies(ctx, species...)
+	loc.loadMoves(ctx, moves...)
+
+	descCtx, cancel := context.WithTimeout(ctx, 6*time.Second)
+	defer cancel()
+
+	slotName := func(p *battle.Pokemon) string {
+		if slot, ok := field.SlotOf(p); ok {
+			return doublesSlotNames[slot]
+		}
+		return "field"
+	}
+
+	// Fill in a slot's move, targets and narration from the turn's events
+	toSlot := func(side, pos int) *slotDTO {
+		m := members[side][pos]
+		if m == nil {
+			return nil
+		}
+		p := m.pokemon
+		out := &slotDTO{
+			Name:        m.species.Name,
+			DisplayName: loc.speciesName(m.species),
+			Ability:     p.Ability,
+			Status:      p.Status,
+			Recharging:  p.Recharging && !p.Fainted(),
+			CurrentHP:   int32(p.HP),
+			MaxHP:       int32(p.Stats.HP),
+			Fainted:     p.Fainted(),
+		}
+		if side == battle.UserSide {
+			out.UserPokemonID = userIDs[pos].String()
+		}
+		if p.Charging != nil && !p.Fainted() {
+			out.Charging = p.Charging.Name
+		}
+		var lines []string
+		for _, ev := range turn.Events {
+			if ev.Attacker != p {
+				continue
+			}
+			var description string
+			if out.MoveUsed == nil {
+				for _, dm := range m.moves {
+					if dm.MoveID == ev.Move.ID {
+						out.MoveUsed = &moveDTO{
+							ID:          dm.MoveID,
+							Name:        dm.Name,
+							DisplayName: loc.moveName(dm.MoveID, dm.Name),
+							Type:        dm.Type,
+							Power:       dm.Power,
+							Target:      dm.Target,
+						}
+						if desc := loc.moveDescription(dm); desc != "" {
+							out.MoveUsed.Description = &desc
+						}
+						break
+					}
+				}
+			}
+			if out.MoveUsed != nil && out.MoveUsed.Description != nil {
+				description = *out.MoveUsed.Description
+			}
+			t := targetDTO{
+				Side:          slotName(ev.Defender),
+				Name:          ev.Defender.Name,
+				DisplayName:   loc.nameOf(ev.Defender.Name),
+				Damage:        ev.Result.Damage,
+				Effectiveness: battle.EffectivenessLabel(ev.Result.Effectiveness),
+				Critical:      ev.Result.Critical,
+				Fainted:       ev.Fainted,
+			}
+			if ev.Move.MaxHits > 1 {
+				t.Hits = ev.Result.Hits
+			}
+			out.Targets = append(out.Targets, t)
+			out.Damage += ev.Result.Damage
+			out.Spread = out.Spread || ev.Spread
+			lines = append(lines, cfg.describeEvent(descCtx, loc, ev, description))
+		}
+		for i, line := range lines {
+			if i > 0 {
+				out.ActionDescription += " "
+			}
+			out.ActionDescription += line
+		}
+		return out
+	}
+
+	resp := doublesResp{
+		Format:            battleFormatDoubles,
+		User:              toSlot(battle.UserSide, 0),
+		UserPartner:       toSlot(battle.UserSide, 1),
+		Challenger:        toSlot(battle.ChallengerSide, 0),
+		ChallengerPartner: toSlot(battle.ChallengerSide, 1),
+		Kind:              battleKindTrainer,
+		Result:            "ongoing",
+	}
+
+	resp.Effects = make([]effectDTO, 0, len(turn.Effects))
+	for _, e := range turn.Effects {
+		side := "field"
+		if e.Pokemon != nil {
+			side = slotName(e.Pokemon)
+		}
+		resp.Effects = append(resp.Effects, effectDTO{
+			Side:     side,
+			Source:   e.Source,
+			HPChange: e.HPChange,
+			Message:  e.Message,
+		})
+	}
+
+	if field.Weather != "" {
+		resp.Weather = &weatherDTO{Name: field.Weather, TurnsLeft: field.WeatherTurns}
+	}
+
+	lead, partner := field.At(userLeadSlot), field.At(userPartnerSlot)
+	switch {
+	case field.SideFainted(battle.ChallengerSide):
+		resp.Result = battleWon
+		resp.Message = fmt.Sprintf("Both challengers fainted! You won %d.", prize)
+		resp.Prize = prize
+		resp.Balance = &balance
+	case partyFainted:
+		resp.Result = battleLost
+		resp.Message = "All of your party pokemon have fainted. Heal them to battle again."
+	case lead.Fainted():
+		resp.Message = fmt.Sprintf("%s fainted! Change your active pokemon to keep fighting.", loc.nameOf(lead.Name))
+	case partner != nil && partner.Fainted():
+		resp.Message = fmt.Sprintf("%s fainted! Your next healthy party pokemon will take its place.", loc.nameOf(partner.Name))
+	}
+	if resp.Result == "ongoing" && cfg.TurnTimeout > 0 {
+		deadline := time.Now().Add(cfg.TurnTimeout)
+		resp.Deadline = &deadline
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
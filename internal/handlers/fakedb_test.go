@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+)
+
+// fakeDB is an in-memory stand-in for the Postgres tables the PokéAPI fetch
+// and cache lookup paths use, so their handlers can be tested without a
+// database. Queries are told apart by their sqlc "-- name:" line, and one it
+// doesn't know fails. A transaction works on a copy of the tables that
+// replaces them on commit.
+type fakeDB struct {
+	mu     sync.Mutex
+	tables fakeTables
+	failOn map[string]error // queries that fail instead of running, by name
+}
+
+type fakeTables struct {
+	pokedex      map[int32]database.Pokedex
+	pokemonNames map[string]database.PokemonName // by pokemon_id/language
+	moveNames    map[string]database.MoveName    // by move_id/language
+	sprites      map[string]database.PokemonSprite
+	abilities    map[string]database.PokemonAbility // by pokemon_id/ability
+	moves        map[int32]database.Move
+	pokemonMoves map[[2]int32]bool // pokemon_id, move_id
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		tables: fakeTables{
+			pokedex:      map[int32]database.Pokedex{},
+			pokemonNames: map[string]database.PokemonName{},
+			moveNames:    map[string]database.MoveName{},
+			sprites:      map[string]database.PokemonSprite{},
+			abilities:    map[string]database.PokemonAbility{},
+			moves:        map[int32]database.Move{},
+			pokemonMoves: map[[2]int32]bool{},
+		},
+		failOn: map[string]error{},
+	}
+}
+
+func (t fakeTables) clone() fakeTables {
+	return fakeTables{
+		pokedex:      maps.Clone(t.pokedex),
+		pokemonNames: maps.Clone(t.pokemonNames),
+		moveNames:    maps.Clone(t.moveNames),
+		sprites:      maps.Clone(t.sprites),
+		abilities:    maps.Clone(t.abilities),
+		moves:        maps.Clone(t.moves),
+		pokemonMoves: maps.Clone(t.pokemonMoves),
+	}
+}
+
+// open returns a *sql.DB on the fake
+func (db *fakeDB) open() *sql.DB {
+	return sql.OpenDB(fakeConnector{db})
+}
+
+// fail makes every later run of the named query return err, nil stops it
+func (db *fakeDB) fail(query string, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.failOn[query] = err
+}
+
+// snapshot is a copy of the committed tables, for checking what was stored
+func (db *fakeDB) snapshot() fakeTables {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.tables.clone()
+}
+
+// A query against the tables, returning its rows. Execs ignore the rows.
+type fakeQuery func(t *fakeTables, args []driver.Value) ([][]driver.Value, error)
+
+var fakeQueries = map[string]fakeQuery{
+	"FetchPokemonDataById": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		p, ok := t.pokedex[argInt32(args[0])]
+		if !ok {
+			return nil, nil
+		}
+		return [][]driver.Value{pokedexRow(p)}, nil
+	},
+	"FetchPokemonDataByName": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		for _, p := range t.pokedex {
+			if strings.EqualFold(p.Name, args[0].(string)) {
+				return [][]driver.Value{pokedexRow(p)}, nil
+			}
+		}
+		return nil, nil
+	},
+	"UpsertPokedex": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		p := database.Pokedex{
+			ID:             argInt32(args[0]),
+			Name:           args[1].(string),
+			Type1:          args[2].(string),
+			Type2:          argNullString(args[3]),
+			Hp:             argInt32(args[4]),
+			Attack:         argInt32(args[5]),
+			Defense:        argInt32(args[6]),
+			SpecialAttack:  argInt32(args[7]),
+			SpecialDefense: argInt32(args[8]),
+			Speed:          argInt32(args[9]),
+			ImageUrl:       argNullString(args[10]),
+		}
+		t.pokedex[p.ID] = p
+		return nil, nil
+	},
+	"ListPokedexNames": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		var names []string
+		for _, p := range t.pokedex {
+			names = append(names, p.Name)
+		}
+		slices.Sort(names)
+		var rows [][]driver.Value
+		for _, n := range names {
+			rows = append(rows, []driver.Value{n})
+		}
+		return rows, nil
+	},
+	"UpsertPokemonName": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		n := database.PokemonName{
+			PokemonID:  argInt32(args[0]),
+			Language:   args[1].(string),
+			Name:       argNullString(args[2]),
+			FlavorText: argNullString(args[3]),
+		}
+		t.pokemonNames[fmt.Sprintf("%d/%s", n.PokemonID, n.Language)] = n
+		return nil, nil
+	},
+	"ListPokemonNames": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		ids, langs := argArray(args[0]), argArray(args[1])
+		var rows [][]driver.Value
+		for _, n := range t.pokemonNames {
+			if slices.Contains(ids, strconv.Itoa(int(n.PokemonID))) && slices.Contains(langs, n.Language) {
+				rows = append(rows, []driver.Value{int64(n.PokemonID), n.Language, nullValue(n.Name), nullValue(n.FlavorText)})
+			}
+		}
+		return rows, nil
+	},
+	"UpsertMoveName": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		n := database.MoveName{
+			MoveID:     argInt32(args[0]),
+			Language:   args[1].(string),
+			Name:       argNullString(args[2]),
+			FlavorText: argNullString(args[3]),
+		}
+		t.moveNames[fmt.Sprintf("%d/%s", n.MoveID, n.Language)] = n
+		return nil, nil
+	},
+	"ListMoveNames": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		ids, langs := argArray(args[0]), argArray(args[1])
+		var rows [][]driver.Value
+		for _, n := range t.moveNames {
+			if slices.Contains(ids, strconv.Itoa(int(n.MoveID))) && slices.Contains(langs, n.Language) {
+				rows = append(rows, []driver.Value{int64(n.MoveID), n.Language, nullValue(n.Name), nullValue(n.FlavorText)})
+			}
+		}
+		return rows, nil
+	},
+	"UpsertPokemonSprite": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		s := database.PokemonSprite{
+			PokemonID: argInt32(args[0]),
+			Variant:   args[1].(string),
+			Url:       argNullString(args[2]),
+		}
+		t.sprites[fmt.Sprintf("%d/%s", s.PokemonID, s.Variant)] = s
+		return nil, nil
+	},
+	"InsertPokemonAbility": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		a := database.PokemonAbility{
+			PokemonID: argInt32(args[0]),
+			Ability:   args[1].(string),
+			IsHidden:  args[2].(bool),
+			Slot:      argInt32(args[3]),
+		}
+		key := fmt.Sprintf("%d/%s", a.PokemonID, a.Ability)
+		if _, ok := t.abilities[key]; !ok {
+			t.abilities[key] = a
+		}
+		return nil, nil
+	},
+	"GetPokemonAbilities": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		var abilities []database.PokemonAbility
+		for _, a := range t.abilities {
+			if a.PokemonID == argInt32(args[0]) {
+				abilities = append(abilities, a)
+			}
+		}
+		slices.SortFunc(abilities, func(a, b database.PokemonAbility) int { return int(a.Slot - b.Slot) })
+		var rows [][]driver.Value
+		for _, a := range abilities {
+			rows = append(rows, []driver.Value{int64(a.PokemonID), a.Ability, a.IsHidden, int64(a.Slot)})
+		}
+		return rows, nil
+	},
+	"GetMoveByID": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		m, ok := t.moves[argInt32(args[0])]
+		if !ok {
+			return nil, nil
+		}
+		return [][]driver.Value{moveRow(m)}, nil
+	},
+	"GetMoveByName": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		for _, m := range t.moves {
+			if m.Name == args[0].(string) {
+				return [][]driver.Value{moveRow(m)}, nil
+			}
+		}
+		return nil, nil
+	},
+	"UpsertMove": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		m := database.Move{
+			MoveID:      argInt32(args[0]),
+			Name:        args[1].(string),
+			Power:       argInt32(args[2]),
+			Type:        args[3].(string),
+			Description: argNullString(args[4]),
+			DamageClass: args[5].(string),
+			Priority:    argInt32(args[6]),
+			MinHits:     argNullInt32(args[7]),
+			MaxHits:     argNullInt32(args[8]),
+			Target:      args[9].(string),
+		}
+		t.moves[m.MoveID] = m
+		return nil, nil
+	},
+	"InsertPokemonMove": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		t.pokemonMoves[[2]int32{argInt32(args[0]), argInt32(args[1])}] = true
+		return nil, nil
+	},
+	"GetPokemonMoves": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		var rows [][]driver.Value
+		for link := range t.pokemonMoves {
+			if link[0] == argInt32(args[0]) {
+				rows = append(rows, moveRow(t.moves[link[1]]))
+			}
+		}
+		return rows, nil
+	},
+	"ListMoveLearners": func(t *fakeTables, args []driver.Value) ([][]driver.Value, error) {
+		var ids []int32
+		for link := range t.pokemonMoves {
+			if link[1] == argInt32(args[0]) {
+				ids = append(ids, link[0])
+			}
+		}
+		slices.Sort(ids)
+		var rows [][]driver.Value
+		for _, id := range ids {
+			rows = append(rows, pokedexRow(t.pokedex[id]))
+		}
+		return rows, nil
+	},
+}
+
+// Rows in the column order sqlc scans them in
+func pokedexRow(p database.Pokedex) []driver.Value {
+	return []driver.Value{
+		int64(p.ID), p.Name, p.Type1, nullValue(p.Type2), int64(p.Hp), int64(p.Attack), int64(p.Defense),
+		int64(p.SpecialAttack), int64(p.SpecialDefense), int64(p.Speed), nullValue(p.ImageUrl),
+	}
+}
+
+func moveRow(m database.Move) []driver.Value {
+	return []driver.Value{
+		int64(m.MoveID), m.Name, int64(m.Power), m.Type, nullValue(m.Description), m.DamageClass,
+		int64(m.Priority), nullValue(m.MinHits), nullValue(m.MaxHits), m.Target,
+	}
+}
+
+func nullValue(v driver.Valuer) driver.Value {
+	out, _ := v.Value()
+	return out
+}
+
+func argInt32(v driver.Value) int32 {
+	return int32(v.(int64))
+}
+
+func argNullString(v driver.Value) sql.NullString {
+	s, ok := v.(string)
+	return sql.NullString{String: s, Valid: ok}
+}
+
+func argNullInt32(v driver.Value) sql.NullInt32 {
+	n, ok := v.(int64)
+	return sql.NullInt32{Int32: int32(n), Valid: ok}
+}
+
+// The elements of a pq.Array, which arrives as text like {1,2} or {"en","fr"}
+func argArray(v driver.Value) []string {
+	s := strings.Trim(v.(string), "{}")
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i, p := range parts {
+		parts[i] = strings.Trim(p, `"`)
+	}
+	return parts
+}
+
+// The sqlc query name at the top of a query, e.g. "UpsertPokedex"
+func queryName(query string) string {
+	name, _ := strings.CutPrefix(query, "-- name: ")
+	name, _, _ = strings.Cut(name, " ")
+	return name
+}
+
+type fakeConnector struct{ db *fakeDB }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fakeDriver can only be used through sql.OpenDB")
+}
+
+// A connection, holding the copy of the tables its transaction works on
+type fakeConn struct {
+	db *fakeDB
+	tx *fakeTables
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{c, query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	tables := c.db.tables.clone()
+	c.tx = &tables
+	return fakeTx{c}, nil
+}
+
+func (c *fakeConn) run(query string, args []driver.Value) ([][]driver.Value, error) {
+	name := queryName(query)
+	fn, ok := fakeQueries[name]
+	if !ok {
+		return nil, fmt.Errorf("fakeDB doesn't know query %q", name)
+	}
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if err := c.db.failOn[name]; err != nil {
+		return nil, err
+	}
+	tables := &c.db.tables
+	if c.tx != nil {
+		tables = c.tx
+	}
+	return fn(tables, args)
+}
+
+type fakeTx struct{ c *fakeConn }
+
+func (tx fakeTx) Commit() error {
+	tx.c.db.mu.Lock()
+	defer tx.c.db.mu.Unlock()
+	tx.c.db.tables, tx.c.tx = *tx.c.tx, nil
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.c.tx = nil
+	return nil
+}
+
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (s fakeStmt) Close() error { return nil }
+
+// Any number of arguments, the queries index them themselves
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	rows, err := s.c.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(len(rows)), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rows, err := s.c.run(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeRows{rows: rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+// Column names don't matter to sqlc, which scans by position
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
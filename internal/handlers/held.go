@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+var errNotHoldingItem = errors.New("pokemon isn't holding an item")
+
+type heldItemDTO struct {
+	ID     int32  `json:"id"`
+	Name   string `json:"name"`
+	Effect string `json:"effect,omitempty"`
+}
+
+// Looks up a held item for a battle payload, nil when nothing is held
+func heldItem(ctx context.Context, q *database.Queries, id sql.NullInt32) (*heldItemDTO, error) {
+	if !id.Valid {
+		return nil, nil
+	}
+	item, err := q.GetItemByID(ctx, id.Int32)
+	if err != nil {
+		return nil, err
+	}
+	return &heldItemDTO{ID: item.ID, Name: item.Name, Effect: item.Effect.String}, nil
+}
+
+// The PokéAPI name of a held item, which is what the battle engine keys effects on
+func heldItemName(ctx context.Context, q *database.Queries, id sql.NullInt32) (string, error) {
+	item, err := heldItem(ctx, q, id)
+	if err != nil || item == nil {
+		return "", err
+	}
+	return item.Name, nil
+}
+
+// Puts whatever a pokemon is holding back in its owner's bag
+func returnHeldItem(ctx context.Context, q *database.Queries, p database.UserPokemon) error {
+	if !p.HeldItemID.Valid {
+		return nil
+	}
+	_, err := q.AddUserItem(ctx, database.AddUserItemParams{
+		UserID:   p.UserID,
+		ItemID:   p.HeldItemID.Int32,
+		Quantity: 1,
+	})
+	return err
+}
+
+// Locks one of the user's pokemon for a held item change
+func lockUserPokemon(ctx context.Context, q *database.Queries, userID, id uuid.UUID) (database.UserPokemon, error) {
+	p, err := q.GetUserPokemonForUpdate(ctx, id)
+	if err != nil {
+		return database.UserPokemon{}, err
+	}
+	if p.UserID != userID {
+		return database.UserPokemon{}, sql.ErrNoRows
+	}
+	return p, nil
+}
+
+// Give one of the user's pokemon an item from the bag to hold. Anything it was
+// already holding goes back in the bag
+func (cfg *Config) EquipItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	item, ok := cfg.lookupBagItem(w, r)
+	if !ok {
+		return
+	}
+
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, anyLocation)
+	if !ok {
+		return
+	}
+
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		p, err := lockUserPokemon(ctx, q, user.ID, userPokemon.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := q.ConsumeUserItem(ctx, database.ConsumeUserItemParams{
+			UserID: user.ID,
+			ItemID: item.ID,
+		}); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrItemNotInBag
+			}
+			return err
+		}
+		if err := returnHeldItem(ctx, q, p); err != nil {
+			return err
+		}
+		return q.SetUserPokemonHeldItem(ctx, database.SetUserPokemonHeldItemParams{
+			UserID:     user.ID,
+			ID:         p.ID,
+			HeldItemID: sql.NullInt32{Int32: item.ID, Valid: true},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrItemNotInBag) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found for user"})
+			return
+		}
+		log.Printf("error equipping held item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":         "Item equipped successfully",
+		"user_pokemon_id": userPokemon.ID.String(),
+		"held_item":       item.Name,
+		"user_username":   user.Username,
+	})
+}
+
+// Take a pokemon's held item and put it back in the bag
+func (cfg *Config) UnequipItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, anyLocation)
+	if !ok {
+		return
+	}
+
+	var itemName string
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		p, err := lockUserPokemon(ctx, q, user.ID, userPokemon.ID)
+		if err != nil {
+			return err
+		}
+		if !p.HeldItemID.Valid {
+			return errNotHoldingItem
+		}
+		if itemName, err = heldItemName(ctx, q, p.HeldItemID); err != nil {
+			return err
+		}
+		if err := returnHeldItem(ctx, q, p); err != nil {
+			return err
+		}
+		return q.SetUserPokemonHeldItem(ctx, database.SetUserPokemonHeldItemParams{
+			UserID: user.ID,
+			ID:     p.ID,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, errNotHoldingItem) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "That pokemon isn't holding an item"})
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found for user"})
+			return
+		}
+		log.Printf("error unequipping held item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":         "Item returned to bag",
+		"user_pokemon_id": userPokemon.ID.String(),
+		"item":            itemName,
+		"user_username":   user.Username,
+	})
+}
@@ -0,0 +1,443 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+	"github.com/google/uuid"
+)
+
+// Check if item exists in db, if not get it, then return item data
+func (cfg *Config) GetItem(ctx context.Context, identifier string) (*database.Item, error) {
+	item, err := cfg.getCachedItem(ctx, identifier)
+	if err == nil {
+		return &item, nil
+	} else if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// If not found, fetch from API and insert
+	if fetchErr := cfg.FetchItemData(ctx, identifier); fetchErr != nil {
+		log.Printf("error fetching item data: %s", fetchErr)
+		return nil, fetchErr
+	}
+
+	item, err = cfg.getCachedItem(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Look up an item in the local catalog only, by id or name
+func (cfg *Config) getCachedItem(ctx context.Context, identifier string) (database.Item, error) {
+	if id, err := strconv.Atoi(identifier); err == nil {
+		return cfg.DB.GetItemByID(ctx, int32(id))
+	}
+	return cfg.DB.GetItemByName(ctx, strings.ToLower(identifier))
+}
+
+// Get item from PokeAPI and insert in db
+func (cfg *Config) FetchItemData(ctx context.Context, identifier string) error {
+	data, err := cfg.PokeAPI.Item(ctx, identifier)
+	if err != nil {
+		return fmt.Errorf("failed to fetch data: %w", err)
+	}
+
+	var effect sql.NullString
+	for _, e := range data.EffectEntries {
+		if e.Language.Name == "en" {
+			effect = sql.NullString{String: e.ShortEffect, Valid: true}
+			break
+		}
+	}
+
+	err = cfg.DB.UpsertItem(ctx, database.UpsertItemParams{
+		ID:       int32(data.ID),
+		Name:     strings.ToLower(data.Name),
+		Category: data.Category.Name,
+		Cost:     int32(data.Cost),
+		Effect:   effect,
+		ImageUrl: sql.NullString{String: data.Sprites.Default, Valid: data.Sprites.Default != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting item into db: %w", err)
+	}
+	return nil
+}
+
+// What an item does when used on a pokemon. Items missing from itemEffects
+// (balls, held items, key items...) can't be used on a pokemon
+type itemEffect struct {
+	Heal     int32    // HP restored
+	FullHeal bool     // restores HP to max
+	Revive   bool     // only works on a fainted pokemon, Heal/FullHeal apply after reviving
+	Cures    []string // status conditions cured, "all" cures any
+}
+
+// Keyed by PokéAPI item name
+var itemEffects = map[string]itemEffect{
+	"potion":        {Heal: 20},
+	"super-potion":  {Heal: 60},
+	"hyper-potion":  {Heal: 120},
+	"max-potion":    {FullHeal: true},
+	"full-restore":  {FullHeal: true, Cures: []string{"all"}},
+	"fresh-water":   {Heal: 30},
+	"soda-pop":      {Heal: 50},
+	"lemonade":      {Heal: 70},
+	"moomoo-milk":   {Heal: 100},
+	"revive":        {Revive: true},
+	"max-revive":    {Revive: true, FullHeal: true},
+	"antidote":      {Cures: []string{"poison"}},
+	"paralyze-heal": {Cures: []string{"paralysis"}},
+	"burn-heal":     {Cures: []string{"burn"}},
+	"ice-heal":      {Cures: []string{"freeze"}},
+	"awakening":     {Cures: []string{"sleep"}},
+	"full-heal":     {Cures: []string{"all"}},
+}
+
+// Thrown to catch a pokemon when the request doesn't pick a ball
+const defaultBall = "poke-ball"
+
+// PokéAPI item categories that catch pokemon
+var ballCategories = map[string]bool{
+	"standard-balls": true,
+	"special-balls":  true,
+	"apricorn-balls": true,
+}
+
+var (
+	ErrItemNotInBag  = errors.New("you don't have that item")
+	ErrItemNotUsable = errors.New("that item can't be used on a pokemon")
+	ErrItemNoEffect  = errors.New("it won't have any effect")
+)
+
+// Work out a pokemon's HP and status after using an item on it
+func (e itemEffect) apply(hp, maxHP int32, status sql.NullString) (int32, sql.NullString, error) {
+	fainted := hp <= 0
+	if e.Revive != fainted {
+		// Revives only work on fainted pokemon and nothing else does
+		return hp, status, ErrItemNoEffect
+	}
+
+	newHP, newStatus := hp, status
+	if e.Revive {
+		newHP = maxHP / 2
+		// Fainting clears status conditions
+		newStatus = sql.NullString{}
+	}
+	if e.FullHeal {
+		newHP = maxHP
+	} else if e.Heal > 0 {
+		newHP = min(newHP+e.Heal, maxHP)
+	}
+	for _, c := range e.Cures {
+		if status.Valid && (c == "all" || c == status.String) {
+			newStatus = sql.NullString{}
+		}
+	}
+
+	if newHP == hp && newStatus == status {
+		return hp, status, ErrItemNoEffect
+	}
+	return newHP, newStatus, nil
+}
+
+type itemUseResult struct {
+	Item      database.Item
+	Target    database.UserPokemon
+	HPBefore  int32
+	MaxHP     int32
+	Remaining int32
+}
+
+// Use one of the user's items on one of their pokemon, consuming it from the bag.
+// Returns ErrItemNotInBag, ErrItemNotUsable or ErrItemNoEffect when the item can't be used
+func (cfg *Config) useItem(ctx context.Context, userID uuid.UUID, item database.Item, targetID uuid.UUID) (itemUseResult, error) {
+	var result itemUseResult
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		result, err = useItemTx(ctx, q, userID, item, targetID)
+		return err
+	})
+	return result, err
+}
+
+// Does the work of useItem inside the caller's transaction
+func useItemTx(ctx context.Context, q *database.Queries, userID uuid.UUID, item database.Item, targetID uuid.UUID) (itemUseResult, error) {
+	effect, ok := itemEffects[item.Name]
+	if !ok {
+		return itemUseResult{}, ErrItemNotUsable
+	}
+
+	// Lock the target so concurrent heals and battle damage don't race
+	target, err := q.GetUserPokemonForUpdate(ctx, targetID)
+	if err != nil {
+		return itemUseResult{}, err
+	}
+	if target.UserID != userID {
+		return itemUseResult{}, sql.ErrNoRows
+	}
+	species, err := q.FetchPokemonDataById(ctx, target.PokemonID.Int32)
+	if err != nil {
+		return itemUseResult{}, err
+	}
+
+	fullHP := maxHP(species)
+	newHP, newStatus, err := effect.apply(target.CurrentHp, fullHP, target.Status)
+	if err != nil {
+		return itemUseResult{}, err
+	}
+
+	remaining, err := q.ConsumeUserItem(ctx, database.ConsumeUserItemParams{
+		UserID: userID,
+		ItemID: item.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return itemUseResult{}, ErrItemNotInBag
+		}
+		return itemUseResult{}, err
+	}
+
+	if err := q.SetUserPokemonHealth(ctx, database.SetUserPokemonHealthParams{
+		UserID:    userID,
+		ID:        target.ID,
+		CurrentHp: newHP,
+		Status:    newStatus,
+	}); err != nil {
+		return itemUseResult{}, err
+	}
+
+	result := itemUseResult{
+		Item:      item,
+		HPBefore:  target.CurrentHp,
+		MaxHP:     fullHP,
+		Remaining: remaining,
+	}
+	target.CurrentHp = newHP
+	target.Status = newStatus
+	result.Target = target
+	return result, nil
+}
+
+// Writes the error response for a failed useItem call
+func writeItemUseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrItemNotInBag), errors.Is(err, ErrItemNotUsable), errors.Is(err, ErrItemNoEffect):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found"})
+	default:
+		log.Printf("error using item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+}
+
+// Resolves the item_identifier form field against the catalog. Anything in a
+// bag is already cached, so an unknown item can't be one the user owns
+func (cfg *Config) lookupBagItem(w http.ResponseWriter, r *http.Request) (database.Item, bool) {
+	identifier := strings.TrimSpace(r.PostForm.Get("item_identifier"))
+	if identifier == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "item_identifier is required"})
+		return database.Item{}, false
+	}
+
+	item, err := cfg.getCachedItem(r.Context(), identifier)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": ErrItemNotInBag.Error()})
+			return database.Item{}, false
+		}
+		log.Printf("error getting item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return database.Item{}, false
+	}
+	return item, true
+}
+
+type itemDTO struct {
+	ID       int32  `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	Effect   string `json:"effect,omitempty"`
+	ImageUrl string `json:"image_url,omitempty"`
+	Quantity int32  `json:"quantity"`
+}
+
+type itemUseDTO struct {
+	Item          string `json:"item"`
+	UserPokemonID string `json:"user_pokemon_id"`
+	HPBefore      int32  `json:"hp_before"`
+	CurrentHP     int32  `json:"current_hp"`
+	MaxHP         int32  `json:"max_hp"`
+	Status        string `json:"status,omitempty"`
+	Remaining     int32  `json:"remaining"`
+}
+
+func toItemUseDTO(res itemUseResult) itemUseDTO {
+	return itemUseDTO{
+		Item:          res.Item.Name,
+		UserPokemonID: res.Target.ID.String(),
+		HPBefore:      res.HPBefore,
+		CurrentHP:     res.Target.CurrentHp,
+		MaxHP:         res.MaxHP,
+		Status:        res.Target.Status.String,
+		Remaining:     res.Remaining,
+	}
+}
+
+// List the items in the user's bag
+func (cfg *Config) GetBagHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	bag, err := cfg.DB.GetUserBag(ctx, user.ID)
+	if err != nil {
+		log.Printf("error getting user bag: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve bag"})
+		return
+	}
+
+	items := make([]itemDTO, 0, len(bag))
+	for _, i := range bag {
+		items = append(items, itemDTO{
+			ID:       i.ID,
+			Name:     i.Name,
+			Category: i.Category,
+			Effect:   i.Effect.String,
+			ImageUrl: i.ImageUrl.String,
+			Quantity: i.Quantity,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+}
+
+// Look up one item in the catalog, with how many the user has. An item that
+// isn't cached yet is fetched from PokéAPI.
+func (cfg *Config) GetItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+	identifier := strings.TrimSpace(r.URL.Query().Get("item_identifier"))
+	if identifier == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "item_identifier is required"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	item, err := cfg.GetItem(ctx, identifier)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("No item called %q", identifier)})
+		return
+	} else if err != nil {
+		log.Printf("error getting item: %s", err)
+		writeLookupError(w, err)
+		return
+	}
+	quantity, err := cfg.DB.GetUserItemQuantity(ctx, database.GetUserItemQuantityParams{UserID: user.ID, ItemID: item.ID})
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("error getting item quantity: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	_, usable := itemEffects[item.Name]
+	writeJSON(w, http.StatusOK, struct {
+		itemDTO
+		Usable bool `json:"usable"`
+	}{
+		itemDTO: itemDTO{
+			ID:       item.ID,
+			Name:     item.Name,
+			Category: item.Category,
+			Effect:   item.Effect.String,
+			ImageUrl: item.ImageUrl.String,
+			Quantity: quantity,
+		},
+		Usable: usable,
+	})
+}
+
+// Use an item from the bag on one of the user's pokemon outside of battle. A
+// user with a battle in progress gets a 409, they use items through Fight
+func (cfg *Config) UseItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	// Inside a battle an item takes the place of a move, so it goes through Fight
+	current, inBattle, err := currentBattle(ctx, cfg.DB, user)
+	if err != nil {
+		log.Printf("error getting battle: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	if inBattle && !current.Result.Valid {
+		msg := "You're in a battle, use the item as your turn with /Fight and item_identifier instead"
+		if current.Format == battleFormatDoubles {
+			msg = "Items can't be used during a double battle"
+		}
+		writeJSON(w, http.StatusConflict, map[string]string{"error": msg})
+		return
+	}
+
+	item, ok := cfg.lookupBagItem(w, r)
+	if !ok {
+		return
+	}
+
+	target, ok := cfg.lookupUserPokemon(w, r, user.ID, anyLocation)
+	if !ok {
+		return
+	}
+
+	res, err := cfg.useItem(ctx, user.ID, item, target.ID)
+	if err != nil {
+		writeItemUseError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  fmt.Sprintf("Used %s", item.Name),
+		"item_use": toItemUseDTO(res),
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+)
+
+const (
+	// How often the janitor sweeps for expired battles
+	JanitorInterval = 15 * time.Minute
+
+	// Default for how long a battle can sit idle before the janitor closes it
+	DefaultBattleExpiry = 24 * time.Hour
+
+	// Challengers no user points at are only deleted once they're this old,
+	// so one being set up right now isn't caught mid-creation
+	orphanChallengerGrace = time.Hour
+)
+
+// RunBattleJanitor sweeps for expired battles every interval until ctx is
+// cancelled. Run it in its own goroutine.
+func (cfg *Config) RunBattleJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := cfg.sweepBattles(ctx); err != nil {
+			log.Printf("error sweeping battles: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Closes battles that ran out of time, frees their challengers and deletes
+// challengers nothing points at anymore
+func (cfg *Config) sweepBattles(ctx context.Context) error {
+	expiry := cfg.BattleExpiry
+	if expiry <= 0 {
+		expiry = DefaultBattleExpiry
+	}
+
+	var forfeited, expired, orphans int64
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		var err error
+		if cfg.TurnTimeout > 0 {
+			if forfeited, err = q.ForfeitTimedOutBattles(ctx, time.Now().Add(-cfg.TurnTimeout)); err != nil {
+				return err
+			}
+		}
+		if expired, err = q.ExpireInactiveBattles(ctx, time.Now().Add(-expiry)); err != nil {
+			return err
+		}
+		if _, err = q.UnlinkEndedChallengers(ctx); err != nil {
+			return err
+		}
+		orphans, err = q.DeleteOrphanedChallengePokemon(ctx, sql.NullTime{Time: time.Now().Add(-orphanChallengerGrace), Valid: true})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if forfeited+expired+orphans > 0 {
+		log.Printf("battle janitor: %d timed out, %d expired, %d orphaned challengers deleted", forfeited, expired, orphans)
+	}
+	return nil
+}
+
+// Whether the user took longer than the turn time limit to make their next
+// move. Only trainer battles are timed, they're where PvP will slot in.
+func (cfg *Config) turnTimedOut(state database.Battle) bool {
+	return cfg.TurnTimeout > 0 && state.Kind == battleKindTrainer && state.Turn > 0 &&
+		time.Since(state.UpdatedAt) > cfg.TurnTimeout
+}
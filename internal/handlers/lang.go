@@ -0,0 +1,327 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+)
+
+// The language used when a request doesn't ask for one, and the last resort
+// for names missing in the one it asked for
+const defaultLanguage = "en"
+
+// Languages PokéAPI has names in, spelled the way it spells them
+var languages = []string{"cs", "de", "en", "es", "fr", "it", "ja", "ja-Hrkt", "ko", "pt-BR", "roomaji", "zh-Hans", "zh-Hant"}
+
+// Languages tried before English when a name is missing in the requested one
+var relatedLanguages = map[string]string{
+	"ja":      "ja-Hrkt",
+	"ja-Hrkt": "ja",
+	"zh-Hans": "zh-Hant",
+	"zh-Hant": "zh-Hans",
+}
+
+// Maps a language tag like es-MX or zh-TW to the PokéAPI language with its
+// names, false when there's none
+func matchLanguage(tag string) (string, bool) {
+	tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+	for _, l := range languages {
+		if strings.EqualFold(tag, l) {
+			return l, true
+		}
+	}
+	base, region, _ := strings.Cut(strings.ToLower(tag), "-")
+	switch base {
+	case "zh":
+		switch region {
+		case "tw", "hk", "mo", "hant":
+			return "zh-Hant", true
+		}
+		return "zh-Hans", true
+	case "pt":
+		return "pt-BR", true
+	}
+	if slices.Contains(languages, base) {
+		return base, true
+	}
+	return "", false
+}
+
+// The language a request wants names in, from the lang parameter or else
+// the Accept-Language header. An unknown lang parameter is an error, unknown
+// Accept-Language tags are skipped.
+func requestLanguage(r *http.Request) (string, error) {
+	if v := r.FormValue("lang"); v != "" {
+		lang, ok := matchLanguage(v)
+		if !ok {
+			return "", errors.New("lang must be one of " + strings.Join(languages, ", "))
+		}
+		return lang, nil
+	}
+
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if tag != "" && tag != "*" && q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	for _, t := range tags {
+		if lang, ok := matchLanguage(t.tag); ok {
+			return lang, nil
+		}
+	}
+	return defaultLanguage, nil
+}
+
+// A species' or move's name and flavor text in one language
+type localText struct {
+	name       string
+	flavorText string
+}
+
+// Localizes the species and moves in one response. Load what's needed, then
+// look names up; anything without a name in the language, or a related one
+// or English, keeps its PokéAPI slug.
+type localizer struct {
+	db       *database.Queries
+	lang     string
+	fallback []string // lang, then the languages tried when it has no name
+
+	species map[int32]localText
+	slugs   map[string]string // species slug to localized name, for battle events
+	moves   map[int32]localText
+}
+
+// The request's localizer, answering 400 for an unknown lang. Responses say
+// which language they're in.
+func (cfg *Config) requestLocalizer(w http.ResponseWriter, r *http.Request) (*localizer, bool) {
+	lang, err := requestLanguage(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return nil, false
+	}
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	return newLocalizer(cfg.DB, lang), true
+}
+
+func newLocalizer(db *database.Queries, lang string) *localizer {
+	fallback := []string{lang}
+	if related, ok := relatedLanguages[lang]; ok {
+		fallback = append(fallback, related)
+	}
+	if lang != defaultLanguage {
+		fallback = append(fallback, defaultLanguage)
+	}
+	return &localizer{
+		db:       db,
+		lang:     lang,
+		fallback: fallback,
+		species:  map[int32]localText{},
+		slugs:    map[string]string{},
+		moves:    map[int32]localText{},
+	}
+}
+
+// Picks the first name and flavor text along the fallback languages
+func (l *localizer) pick(byLanguage map[string]localText) localText {
+	var t localText
+	for _, lang := range l.fallback {
+		if t.name == "" {
+			t.name = byLanguage[lang].name
+		}
+		if t.flavorText == "" {
+			t.flavorText = byLanguage[lang].flavorText
+		}
+	}
+	return t
+}
+
+// Loads the names of species not loaded yet. Names are cosmetic, so failing
+// to load them is logged and the slugs are used.
+func (l *localizer) loadSpecies(ctx context.Context, species ...database.Pokedex) {
+	var ids []int32
+	for _, p := range species {
+		if _, ok := l.species[p.ID]; !ok && !slices.Contains(ids, p.ID) {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	rows, err := l.db.ListPokemonNames(ctx, database.ListPokemonNamesParams{PokemonIds: ids, Languages: l.fallback})
+	if err != nil {
+		log.Printf("error listing pokemon names: %s", err)
+	}
+	byID := map[int32]map[string]localText{}
+	for _, row := range rows {
+		if byID[row.PokemonID] == nil {
+			byID[row.PokemonID] = map[string]localText{}
+		}
+		byID[row.PokemonID][row.Language] = localText{row.Name.String, row.FlavorText.String}
+	}
+	for _, p := range species {
+		if !slices.Contains(ids, p.ID) {
+			continue
+		}
+		t := l.pick(byID[p.ID])
+		l.species[p.ID] = t
+		if t.name != "" {
+			l.slugs[p.Name] = t.name
+		}
+	}
+}
+
+// Loads the names of moves not loaded yet, like loadSpecies
+func (l *localizer) loadMoves(ctx context.Context, moves ...database.Move) {
+	var ids []int32
+	for _, m := range moves {
+		if _, ok := l.moves[m.MoveID]; !ok && !slices.Contains(ids, m.MoveID) {
+			ids = append(ids, m.MoveID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	rows, err := l.db.ListMoveNames(ctx, database.ListMoveNamesParams{MoveIds: ids, Languages: l.fallback})
+	if err != nil {
+		log.Printf("error listing move names: %s", err)
+	}
+	byID := map[int32]map[string]localText{}
+	for _, row := range rows {
+		if byID[row.MoveID] == nil {
+			byID[row.MoveID] = map[string]localText{}
+		}
+		byID[row.MoveID][row.Language] = localText{row.Name.String, row.FlavorText.String}
+	}
+	for _, id := range ids {
+		l.moves[id] = l.pick(byID[id])
+	}
+}
+
+// A loaded species' name, its slug if it has none
+func (l *localizer) speciesName(p database.Pokedex) string {
+	if name := l.species[p.ID].name; name != "" {
+		return name
+	}
+	return p.Name
+}
+
+// A loaded species' flavor text, empty if it has none
+func (l *localizer) speciesFlavorText(p database.Pokedex) string {
+	return l.species[p.ID].flavorText
+}
+
+// The name of a loaded species from its slug, which is all battle events
+// know it by
+func (l *localizer) nameOf(slug string) string {
+	if name, ok := l.slugs[slug]; ok {
+		return name
+	}
+	return slug
+}
+
+// A loaded move's name, its slug if it has none
+func (l *localizer) moveName(id int32, slug string) string {
+	if name := l.moves[id].name; name != "" {
+		return name
+	}
+	return slug
+}
+
+// A loaded move's flavor text, the English description cached with the move
+// if it has none
+func (l *localizer) moveDescription(m database.Move) string {
+	if text := l.moves[m.MoveID].flavorText; text != "" {
+		return text
+	}
+	return m.Description.String
+}
+
+// Names and flavor text by language from PokéAPI, using the latest flavor
+// text in each language
+func localizedTexts(names []pokeapi.Name, entries []pokeapi.FlavorText) map[string]localText {
+	texts := map[string]localText{}
+	for _, n := range names {
+		t := texts[n.Language.Name]
+		t.name = strings.TrimSpace(n.Name)
+		texts[n.Language.Name] = t
+	}
+	// Entries are oldest first
+	for _, e := range entries {
+		t := texts[e.Language.Name]
+		t.flavorText = strings.Join(strings.Fields(e.FlavorText), " ")
+		texts[e.Language.Name] = t
+	}
+	return texts
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Fetches a species' names and flavor text in every language PokéAPI has.
+// Alternate forms share their species' names, so they have none and keep
+// their slugs.
+func (cfg *Config) fetchSpeciesTexts(ctx context.Context, data *pokeapi.Pokemon) (map[string]localText, error) {
+	if id, ok := data.Species.ID(); ok && id != data.ID {
+		return nil, nil
+	}
+	species, err := cfg.PokeAPI.Species(ctx, strconv.Itoa(data.ID))
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return localizedTexts(species.Names, species.FlavorTextEntries), nil
+}
+
+func storePokemonNames(ctx context.Context, q *database.Queries, pokemonID int32, texts map[string]localText) error {
+	for lang, t := range texts {
+		if err := q.UpsertPokemonName(ctx, database.UpsertPokemonNameParams{
+			PokemonID:  pokemonID,
+			Language:   lang,
+			Name:       nullString(t.name),
+			FlavorText: nullString(t.flavorText),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stores a move's names and flavor text in every language PokéAPI has
+func (cfg *Config) storeMoveNames(ctx context.Context, move *pokeapi.Move) error {
+	for lang, t := range localizedTexts(move.Names, move.FlavorTextEntries) {
+		if err := cfg.DB.UpsertMoveName(ctx, database.UpsertMoveNameParams{
+			MoveID:     int32(move.ID),
+			Language:   lang,
+			Name:       nullString(t.name),
+			FlavorText: nullString(t.flavorText),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+)
+
+const (
+	defaultMovePageSize = 50
+	maxMovePageSize     = 200
+)
+
+// Lists and searches cached moves, no login needed. Nothing is fetched from
+// PokéAPI.
+func (cfg *Config) GetMovesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+	page, pageSize, err := parsePagination(r, defaultMovePageSize, maxMovePageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	filter, err := parseMoveFilter(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	total, err := cfg.DB.CountMovesSearch(ctx, filter)
+	if err != nil {
+		log.Printf("error counting moves: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	rows, err := cfg.DB.SearchMoves(ctx, database.SearchMovesParams{
+		Name:        filter.Name,
+		Type:        filter.Type,
+		DamageClass: filter.DamageClass,
+		MinPower:    filter.MinPower,
+		MaxPower:    filter.MaxPower,
+		RowLimit:    int32(pageSize),
+		RowOffset:   int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		log.Printf("error listing moves: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	loc.loadMoves(ctx, rows...)
+	moves := make([]moveInfoDTO, 0, len(rows))
+	for _, m := range rows {
+		moves = append(moves, toMoveInfoDTO(m, loc))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"moves":     moves,
+	})
+}
+
+// Reads the name, type, damage class and power filters of /GetMoves
+func parseMoveFilter(r *http.Request) (database.CountMovesSearchParams, error) {
+	query := r.URL.Query()
+	filter := database.CountMovesSearchParams{MaxPower: math.MaxInt32}
+
+	if name := strings.ToLower(strings.TrimSpace(query.Get("name"))); name != "" {
+		filter.Name = sql.NullString{String: name, Valid: true}
+	}
+	if t := strings.ToLower(query.Get("type")); t != "" {
+		if !battle.IsType(t) {
+			return filter, errors.New("type must be one of the 18 types")
+		}
+		filter.Type = sql.NullString{String: t, Valid: true}
+	}
+	if dc := strings.ToLower(query.Get("damage_class")); dc != "" {
+		if dc != battle.Physical && dc != battle.Special && dc != battle.Status {
+			return filter, errors.New("damage_class must be physical, special or status")
+		}
+		filter.DamageClass = sql.NullString{String: dc, Valid: true}
+	}
+	for _, bound := range []struct {
+		param string
+		dst   *int32
+	}{{"min_power", &filter.MinPower}, {"max_power", &filter.MaxPower}} {
+		if v := query.Get(bound.param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > math.MaxInt32 {
+				return filter, errors.New(bound.param + " must be a non-negative integer")
+			}
+			*bound.dst = int32(n)
+		}
+	}
+	if filter.MinPower > filter.MaxPower {
+		return filter, errors.New("min_power can't be more than max_power")
+	}
+	return filter, nil
+}
+
+// Gets one cached move and the cached species that know it, no login needed.
+// Like the pokedex it only answers from the cache.
+func (cfg *Config) GetMoveHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+	identifier := strings.TrimSpace(r.URL.Query().Get("move_identifier"))
+	if identifier == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "move_identifier is required"})
+		return
+	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	move, err := cfg.getCachedMove(ctx, identifier)
+	if err == sql.ErrNoRows {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("No move called %q", identifier)})
+		return
+	} else if err != nil {
+		log.Printf("error getting move: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	learners, err := cfg.DB.ListMoveLearners(ctx, move.MoveID)
+	if err != nil {
+		log.Printf("error listing move learners: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	loc.loadMoves(ctx, move)
+	loc.loadSpecies(ctx, learners...)
+	resp := struct {
+		moveInfoDTO
+		Pokemon []pokedexDTO `json:"pokemon"`
+	}{
+		moveInfoDTO: toMoveInfoDTO(move, loc),
+		Pokemon:     make([]pokedexDTO, 0, len(learners)),
+	}
+	for _, p := range learners {
+		resp.Pokemon = append(resp.Pokemon, toPokedexDTO(p, loc))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSpeciesNotFound is returned by GetPokemon when neither the cache nor
+// PokéAPI has a species by that name or ID. Errors wrapping it are
+// *SpeciesNotFoundError.
+var ErrSpeciesNotFound = errors.New("species not found")
+
+type SpeciesNotFoundError struct {
+	Identifier  string
+	Suggestions []string // Cached species with similar names, closest first
+}
+
+func (e *SpeciesNotFoundError) Error() string {
+	return fmt.Sprintf("no pokemon %q", e.Identifier)
+}
+
+func (e *SpeciesNotFoundError) Is(target error) bool { return target == ErrSpeciesNotFound }
+
+const (
+	missTTL        = 10 * time.Minute // How long PokéAPI isn't asked again about a miss
+	maxMisses      = 1000
+	maxSuggestions = 3
+)
+
+// Identifiers PokéAPI recently had no species for. The zero value is ready
+// to use.
+type missCache struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+func (m *missCache) has(identifier string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return time.Now().Before(m.until[strings.ToLower(identifier)])
+}
+
+func (m *missCache) add(identifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.until == nil {
+		m.until = map[string]time.Time{}
+	}
+	now := time.Now()
+	if len(m.until) >= maxMisses {
+		for k, t := range m.until {
+			if now.After(t) {
+				delete(m.until, k)
+			}
+		}
+	}
+	// Still full of live misses, make room with any of them
+	for k := range m.until {
+		if len(m.until) < maxMisses {
+			break
+		}
+		delete(m.until, k)
+	}
+	m.until[strings.ToLower(identifier)] = now.Add(missTTL)
+}
+
+// Builds the not found error for an identifier, with suggestions when it's
+// a name
+func (cfg *Config) speciesNotFound(ctx context.Context, identifier string) error {
+	e := &SpeciesNotFoundError{Identifier: identifier}
+	if _, err := strconv.Atoi(identifier); err == nil {
+		return e
+	}
+	names, err := cfg.DB.ListPokedexNames(ctx)
+	if err != nil {
+		log.Printf("error listing pokedex names for suggestions: %s", err)
+		return e
+	}
+	e.Suggestions = closestNames(strings.ToLower(identifier), names)
+	return e
+}
+
+// The names within a few typos of name, closest first. Longer names allow
+// more typos, a third of their length up to 3.
+func closestNames(name string, names []string) []string {
+	limit := min(max(len(name)/3, 1), 3)
+	type match struct {
+		name string
+		dist int
+	}
+	var matches []match
+	for _, n := range names {
+		if d := editDistance(name, n); d <= limit {
+			matches = append(matches, match{n, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].dist < matches[j].dist })
+
+	var out []string
+	for _, m := range matches[:min(len(matches), maxSuggestions)] {
+		out = append(out, m.name)
+	}
+	return out
+}
+
+// Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
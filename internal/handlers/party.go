@@ -0,0 +1,363 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+// Same limit as the games since Gen VI
+const maxNicknameLength = 12
+
+// Where an owned pokemon has to be for an endpoint to act on it
+type pokemonLocation int
+
+const (
+	anyLocation pokemonLocation = iota
+	inParty
+	inBox
+)
+
+// Finds the owned pokemon a request refers to, writing the error response and returning false if it can't.
+// user_pokemon_id (the instance UUID) is preferred. pokemon_identifier (the species ID) is deprecated since
+// a user can own several of the same species, it only picks the first match.
+func (cfg *Config) lookupUserPokemon(w http.ResponseWriter, r *http.Request, userID uuid.UUID, location pokemonLocation) (database.UserPokemon, bool) {
+	ctx := r.Context()
+	notFound := "Pokemon not found for user"
+	switch location {
+	case inParty:
+		notFound = "Pokemon not found in user's party"
+	case inBox:
+		notFound = "Pokemon not found in user's PC box"
+	}
+
+	var (
+		userPokemon database.UserPokemon
+		err         error
+	)
+	if idStr := r.PostForm.Get("user_pokemon_id"); idStr != "" {
+		id, parseErr := uuid.Parse(idStr)
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_pokemon_id must be a valid UUID"})
+			return database.UserPokemon{}, false
+		}
+		userPokemon, err = cfg.DB.GetUserPokemonByID(ctx, database.GetUserPokemonByIDParams{
+			UserID: userID,
+			ID:     id,
+		})
+		if err == nil && ((location == inParty && userPokemon.InBox) || (location == inBox && !userPokemon.InBox)) {
+			err = sql.ErrNoRows
+		}
+	} else if pokemonIDStr := r.PostForm.Get("pokemon_identifier"); pokemonIDStr != "" {
+		pokemonIDInt, parseErr := strconv.Atoi(pokemonIDStr)
+		if parseErr != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier must be a valid integer"})
+			return database.UserPokemon{}, false
+		}
+		log.Printf("deprecated pokemon_identifier lookup on %s", r.URL.Path)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Warning", `299 - "pokemon_identifier is deprecated, use user_pokemon_id"`)
+
+		pokemonID := sql.NullInt32{Valid: true, Int32: int32(pokemonIDInt)}
+		if location == anyLocation {
+			userPokemon, err = cfg.DB.GetOneUserPokemon(ctx, database.GetOneUserPokemonParams{
+				UserID:    userID,
+				PokemonID: pokemonID,
+			})
+		} else {
+			userPokemon, err = cfg.DB.GetOneUserPokemonByLocation(ctx, database.GetOneUserPokemonByLocationParams{
+				UserID:    userID,
+				PokemonID: pokemonID,
+				InBox:     location == inBox,
+			})
+		}
+	} else {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "user_pokemon_id is required"})
+		return database.UserPokemon{}, false
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": notFound})
+			return database.UserPokemon{}, false
+		}
+		log.Printf("error getting user pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return database.UserPokemon{}, false
+	}
+	return userPokemon, true
+}
+
+// Trims the nickname and checks it's short and printable. An empty nickname clears it.
+func validateNickname(nickname string) (string, error) {
+	nickname = strings.TrimSpace(nickname)
+	if utf8.RuneCountInString(nickname) > maxNicknameLength {
+		return "", fmt.Errorf("nickname can be at most %d characters", maxNicknameLength)
+	}
+	for _, ch := range nickname {
+		if !unicode.IsPrint(ch) {
+			return "", errors.New("nickname can only contain printable characters")
+		}
+	}
+	return nickname, nil
+}
+
+// Give an owned pokemon a nickname, or clear it with an empty nickname
+func (cfg *Config) NicknamePokemonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	nickname, err := validateNickname(r.PostForm.Get("nickname"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, anyLocation)
+	if !ok {
+		return
+	}
+
+	if err := cfg.DB.SetUserPokemonNickname(ctx, database.SetUserPokemonNicknameParams{
+		UserID:   user.ID,
+		ID:       userPokemon.ID,
+		Nickname: sql.NullString{String: nickname, Valid: nickname != ""},
+	}); err != nil {
+		log.Printf("error setting nickname: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	message := "Nickname set successfully"
+	if nickname == "" {
+		message = "Nickname cleared successfully"
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":         message,
+		"user_pokemon_id": userPokemon.ID.String(),
+		"pokemon_id":      strconv.Itoa(int(userPokemon.PokemonID.Int32)),
+		"nickname":        nickname,
+		"user_username":   user.Username,
+	})
+}
+
+// Release an owned pokemon for good. Requires confirm=true and the last party pokemon can't be released.
+func (cfg *Config) ReleasePokemonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	if confirm, _ := strconv.ParseBool(r.PostForm.Get("confirm")); !confirm {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Releasing a pokemon can't be undone, send confirm=true to release it"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, anyLocation)
+	if !ok {
+		return
+	}
+
+	err := cfg.withTx(ctx, func(q *database.Queries) error {
+		// Locked first so concurrent releases can't both leave the party empty
+		if err := q.LockUser(ctx, user.ID); err != nil {
+			return err
+		}
+		// Already released or traded away by another request
+		p, err := lockUserPokemon(ctx, q, user.ID, userPokemon.ID)
+		if err != nil {
+			return err
+		}
+		if !p.InBox {
+			partysize, err := q.CountUserPartyPokemon(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			if partysize <= 1 {
+				return errLastPartyPokemon
+			}
+		}
+
+		// Whatever it was holding stays with the trainer
+		if err := returnHeldItem(ctx, q, p); err != nil {
+			return err
+		}
+		if err := q.DeleteUserPokemon(ctx, database.DeleteUserPokemonParams{
+			UserID: user.ID,
+			ID:     p.ID,
+		}); err != nil {
+			return err
+		}
+		if p.InBox {
+			return nil
+		}
+		if err := q.CompactUserPartySlots(ctx, user.ID); err != nil {
+			return err
+		}
+		// The lead of the party takes over if the active pokemon was released
+		if !p.IsActive {
+			return nil
+		}
+		party, err := q.GetUserPartyPokemon(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(party) == 0 {
+			return nil
+		}
+		_, err = q.ActivateUserPokemon(ctx, database.ActivateUserPokemonParams{
+			UserID: user.ID,
+			ID:     party[0].ID,
+		})
+		return err
+	})
+	switch {
+	case errors.Is(err, errLastPartyPokemon):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "You can't release the last pokemon in your party"})
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found for user"})
+		return
+	case err != nil:
+		log.Printf("error releasing user pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":         "Pokemon released successfully",
+		"user_pokemon_id": userPokemon.ID.String(),
+		"pokemon_id":      strconv.Itoa(int(userPokemon.PokemonID.Int32)),
+		"user_username":   user.Username,
+	})
+}
+
+// Reorder the party. order is a comma separated list of user_pokemon_ids (or current slot numbers)
+// in their new order, e.g. order=3,1,2 moves the third pokemon to the front.
+func (cfg *Config) ReorderPartyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	orderStr := r.PostForm.Get("order")
+	if orderStr == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "order is required"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	party, err := cfg.DB.GetUserPartyPokemon(ctx, user.ID)
+	if err != nil {
+		log.Printf("error getting user party: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	bySlot := make(map[int32]database.UserPokemon, len(party))
+	byID := make(map[uuid.UUID]database.UserPokemon, len(party))
+	for _, p := range party {
+		bySlot[p.PartySlot.Int32] = p
+		byID[p.ID] = p
+	}
+
+	// The new order has to name every party pokemon exactly once
+	parts := strings.Split(orderStr, ",")
+	if len(parts) != len(party) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("order must list all %d party pokemon", len(party))})
+		return
+	}
+	newOrder := make([]database.UserPokemon, 0, len(parts))
+	seen := make(map[uuid.UUID]bool, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var (
+			p     database.UserPokemon
+			found bool
+		)
+		if id, err := uuid.Parse(part); err == nil {
+			p, found = byID[id]
+		} else if slot, err := strconv.Atoi(part); err == nil {
+			p, found = bySlot[int32(slot)]
+		} else {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "order must be a comma separated list of user_pokemon_ids or slot numbers"})
+			return
+		}
+		if !found || seen[p.ID] {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("order must list all %d party pokemon exactly once", len(party))})
+			return
+		}
+		seen[p.ID] = true
+		newOrder = append(newOrder, p)
+	}
+
+	// unique_party_slot_per_user is deferred, so slots can be swapped freely until commit
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		for i, p := range newOrder {
+			if err := q.SetUserPokemonPartySlot(ctx, database.SetUserPokemonPartySlotParams{
+				UserID:    user.ID,
+				ID:        p.ID,
+				PartySlot: sql.NullInt32{Valid: true, Int32: int32(i + 1)},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("error reordering party: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":       "Party reordered successfully",
+		"user_username": user.Username,
+	})
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi/pokeapitest"
+	"github.com/google/uuid"
+)
+
+// A Config on an empty in-memory database, fetching from a Fake holding the
+// pokeapitest fixtures
+func newFixtureConfig(t *testing.T) (*Config, *pokeapitest.Fake, *fakeDB) {
+	t.Helper()
+	api, err := pokeapitest.LoadFake(pokeapitest.Fixtures, "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := newFakeDB()
+	conn := db.open()
+	t.Cleanup(func() { conn.Close() })
+	return &Config{DB: database.New(conn), DBConn: conn, PokeAPI: api}, api, db
+}
+
+// Serves one request with handler and decodes the JSON response into dst
+func serveJSON(t *testing.T, handler http.HandlerFunc, r *http.Request, dst any) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handler(w, r)
+	if err := json.Unmarshal(w.Body.Bytes(), dst); err != nil {
+		t.Fatalf("%s %s: bad JSON %q: %s", r.Method, r.URL, w.Body.String(), err)
+	}
+	return w.Code
+}
+
+func TestFetchPokemonData(t *testing.T) {
+	cfg, api, db := newFixtureConfig(t)
+	ctx := context.Background()
+
+	if err := cfg.FetchPokemonData(ctx, "pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.DB.FetchPokemonDataById(ctx, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "pikachu" || p.Type1 != "electric" || p.Type2.Valid || p.Speed != 90 {
+		t.Errorf("cached pikachu = %+v", p)
+	}
+
+	// Both electric moves, filled up with other ones
+	moves, err := cfg.DB.GetPokemonMoves(ctx, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, m := range moves {
+		names = append(names, m.Name)
+	}
+	if len(names) != 4 || !slices.Contains(names, "thunderbolt") || !slices.Contains(names, "thunder-shock") {
+		t.Errorf("pikachu was given %v, want 4 moves including thunderbolt and thunder-shock", names)
+	}
+	abilities, err := cfg.DB.GetPokemonAbilities(ctx, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(abilities) != 2 || abilities[0].Ability != "static" || !abilities[1].IsHidden {
+		t.Errorf("pikachu abilities = %+v, want static and hidden lightning-rod", abilities)
+	}
+	tables := db.snapshot()
+	if _, ok := tables.pokemonNames["25/fr"]; !ok {
+		t.Error("pikachu's French name wasn't stored")
+	}
+	if _, ok := tables.sprites["25/artwork"]; !ok {
+		t.Error("pikachu's artwork wasn't stored")
+	}
+
+	// Fetching it again doesn't store it again
+	if err := cfg.FetchPokemonData(ctx, "25"); err != nil {
+		t.Fatal(err)
+	}
+	if n := api.Calls("pokemon-species"); n != 1 {
+		t.Errorf("looked pikachu's species up %d times, want 1", n)
+	}
+}
+
+func TestFetchPokemonDataRollsBack(t *testing.T) {
+	cfg, _, db := newFixtureConfig(t)
+	ctx := context.Background()
+
+	// Failing to link a move leaves none of the species behind
+	db.fail("InsertPokemonMove", errors.New("connection reset"))
+	if err := cfg.FetchPokemonData(ctx, "pikachu"); err == nil {
+		t.Fatal("FetchPokemonData succeeded with move links failing")
+	}
+	tables := db.snapshot()
+	if len(tables.pokedex) != 0 || len(tables.pokemonNames) != 0 || len(tables.sprites) != 0 || len(tables.abilities) != 0 {
+		t.Errorf("a failed fetch left %d species, %d names, %d sprites and %d abilities cached",
+			len(tables.pokedex), len(tables.pokemonNames), len(tables.sprites), len(tables.abilities))
+	}
+
+	// So the next fetch stores it whole
+	db.fail("InsertPokemonMove", nil)
+	if err := cfg.FetchPokemonData(ctx, "pikachu"); err != nil {
+		t.Fatal(err)
+	}
+	moves, err := cfg.DB.GetPokemonMoves(ctx, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(moves) != 4 {
+		t.Errorf("pikachu was given %d moves after a retry, want 4", len(moves))
+	}
+}
+
+func TestGetPokemonNotFound(t *testing.T) {
+	cfg, api, _ := newFixtureConfig(t)
+	ctx := context.Background()
+	if _, err := cfg.GetPokemon(ctx, "meowth"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := cfg.GetPokemon(ctx, "meowt")
+	var notFound *SpeciesNotFoundError
+	if !errors.As(err, &notFound) || !slices.Equal(notFound.Suggestions, []string{"meowth"}) {
+		t.Fatalf("GetPokemon(meowt) error = %v, want a SpeciesNotFoundError suggesting meowth", err)
+	}
+
+	// A recent miss isn't asked about again
+	calls := api.Calls("pokemon")
+	if _, err := cfg.GetPokemon(ctx, "meowt"); !errors.Is(err, ErrSpeciesNotFound) {
+		t.Fatalf("GetPokemon(meowt) again error = %v, want ErrSpeciesNotFound", err)
+	}
+	if n := api.Calls("pokemon"); n != calls {
+		t.Errorf("a remembered miss made %d PokéAPI calls, want 0", n-calls)
+	}
+}
+
+func TestGetMove(t *testing.T) {
+	cfg, api, _ := newFixtureConfig(t)
+	ctx := context.Background()
+
+	m, err := cfg.GetMove(ctx, "Thunderbolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "thunderbolt" || m.Type != "electric" || m.Power != 90 || m.DamageClass != "special" {
+		t.Errorf("GetMove(Thunderbolt) = %+v", m)
+	}
+	byID, err := cfg.GetMove(ctx, "85")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byID.MoveID != m.MoveID {
+		t.Errorf("GetMove(85) = %s, want thunderbolt", byID.Name)
+	}
+	if n := api.Calls("move"); n != 1 {
+		t.Errorf("looked thunderbolt up %d times, want 1", n)
+	}
+
+	if _, err := cfg.GetMove(ctx, "not-a-move"); !errors.Is(err, pokeapi.ErrNotFound) {
+		t.Errorf("GetMove(not-a-move) error = %v, want pokeapi.ErrNotFound", err)
+	}
+}
+
+func TestGetPokedexEntryHandler(t *testing.T) {
+	cfg, api, _ := newFixtureConfig(t)
+	if err := cfg.FetchPokemonData(context.Background(), "meowth"); err != nil {
+		t.Fatal(err)
+	}
+
+	var entry struct {
+		Name        string `json:"name"`
+		DisplayName string `json:"display_name"`
+		Abilities   []struct {
+			Name string `json:"name"`
+		} `json:"abilities"`
+		Moves []struct {
+			Name string `json:"name"`
+		} `json:"moves"`
+	}
+	r := httptest.NewRequest("GET", "/GetPokedexEntry?pokemon_identifier=52&lang=fr", nil)
+	if code := serveJSON(t, cfg.GetPokedexEntryHandler, r, &entry); code != http.StatusOK {
+		t.Fatalf("GetPokedexEntry(52) = %d, want 200", code)
+	}
+	if entry.Name != "meowth" || entry.DisplayName != "Miaouss" || len(entry.Abilities) != 3 || len(entry.Moves) != 4 {
+		t.Errorf("GetPokedexEntry(52) = %+v, want meowth as Miaouss with 3 abilities and 4 moves", entry)
+	}
+
+	// The pokedex only answers from the cache
+	calls := api.Calls("pokemon")
+	var missing struct {
+		Suggestions []string `json:"suggestions"`
+	}
+	r = httptest.NewRequest("GET", "/GetPokedexEntry?pokemon_identifier=pikachu", nil)
+	if code := serveJSON(t, cfg.GetPokedexEntryHandler, r, &missing); code != http.StatusNotFound {
+		t.Errorf("GetPokedexEntry(pikachu) = %d before it was cached, want 404", code)
+	}
+	if n := api.Calls("pokemon"); n != calls {
+		t.Errorf("GetPokedexEntry made %d PokéAPI calls, want 0", n-calls)
+	}
+	r = httptest.NewRequest("GET", "/GetPokedexEntry?pokemon_identifier=meowht", nil)
+	if code := serveJSON(t, cfg.GetPokedexEntryHandler, r, &missing); code != http.StatusNotFound || !slices.Equal(missing.Suggestions, []string{"meowth"}) {
+		t.Errorf("GetPokedexEntry(meowht) = %d suggesting %v, want 404 suggesting meowth", code, missing.Suggestions)
+	}
+}
+
+func TestGetMoveHandler(t *testing.T) {
+	cfg, _, _ := newFixtureConfig(t)
+	if err := cfg.FetchPokemonData(context.Background(), "pikachu"); err != nil {
+		t.Fatal(err)
+	}
+
+	var move struct {
+		Name    string `json:"name"`
+		Power   int    `json:"power"`
+		Pokemon []struct {
+			Name string `json:"name"`
+		} `json:"pokemon"`
+	}
+	r := httptest.NewRequest("GET", "/GetMove?move_identifier=thunderbolt", nil)
+	if code := serveJSON(t, cfg.GetMoveHandler, r, &move); code != http.StatusOK {
+		t.Fatalf("GetMove(thunderbolt) = %d, want 200", code)
+	}
+	if move.Name != "thunderbolt" || move.Power != 90 || len(move.Pokemon) != 1 || move.Pokemon[0].Name != "pikachu" {
+		t.Errorf("GetMove(thunderbolt) = %+v, want thunderbolt learned by pikachu", move)
+	}
+
+	var errResp map[string]string
+	r = httptest.NewRequest("GET", "/GetMove?move_identifier=scratch", nil)
+	if code := serveJSON(t, cfg.GetMoveHandler, r, &errResp); code != http.StatusNotFound {
+		t.Errorf("GetMove(scratch) = %d before it was cached, want 404", code)
+	}
+}
+
+func TestCalculateDamageHandler(t *testing.T) {
+	cfg, api, _ := newFixtureConfig(t)
+	user := &database.User{ID: uuid.New(), Username: "ash"}
+	request := func(query string) *http.Request {
+		r := httptest.NewRequest("GET", "/CalculateDamage?"+query, nil)
+		return r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+	}
+
+	var calc struct {
+		Attacker struct {
+			Name string `json:"name"`
+		} `json:"attacker"`
+		Defender struct {
+			Name  string `json:"name"`
+			MaxHP int    `json:"max_hp"`
+		} `json:"defender"`
+		TypeMultiplier float64 `json:"type_multiplier"`
+		STAB           bool    `json:"stab"`
+		Damage         struct {
+			Min int `json:"min"`
+			Max int `json:"max"`
+		} `json:"damage"`
+	}
+	code := serveJSON(t, cfg.CalculateDamageHandler, request("attacker=pikachu&defender=meowth&move=thunderbolt"), &calc)
+	if code != http.StatusOK {
+		t.Fatalf("CalculateDamage = %d, want 200", code)
+	}
+	if calc.Attacker.Name != "pikachu" || calc.Defender.Name != "meowth" || !calc.STAB || calc.TypeMultiplier != 1 {
+		t.Errorf("CalculateDamage = %+v, want pikachu's thunderbolt with STAB against meowth", calc)
+	}
+	if calc.Damage.Min <= 0 || calc.Damage.Min > calc.Damage.Max || calc.Damage.Max >= calc.Defender.MaxHP {
+		t.Errorf("CalculateDamage damage = %+v against %d HP", calc.Damage, calc.Defender.MaxHP)
+	}
+	// Both species and the move came from PokéAPI and are cached now
+	if api.Calls("pokemon") != 2 || api.Calls("move") == 0 {
+		t.Errorf("PokéAPI was asked for %d pokemon and %d moves", api.Calls("pokemon"), api.Calls("move"))
+	}
+
+	var errResp map[string]any
+	if code := serveJSON(t, cfg.CalculateDamageHandler, request("attacker=pikachu&defender=missingno&move=thunderbolt"), &errResp); code != http.StatusNotFound {
+		t.Errorf("CalculateDamage against missingno = %d, want 404", code)
+	}
+	if code := serveJSON(t, cfg.CalculateDamageHandler, request("attacker=pikachu&defender=meowth&move=not-a-move"), &errResp); code != http.StatusNotFound {
+		t.Errorf("CalculateDamage with not-a-move = %d, want 404", code)
+	}
+}
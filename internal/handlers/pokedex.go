@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+)
+
+const (
+	defaultPokedexPageSize = 50
+	maxPokedexPageSize     = 200
+)
+
+// The pokedex numbers each generation introduced. Alternate forms have IDs
+// past 10000 and no generation.
+var generations = []struct{ first, last int32 }{
+	{1, 151}, {152, 251}, {252, 386}, {387, 493}, {494, 649},
+	{650, 721}, {722, 809}, {810, 905}, {906, 1025},
+}
+
+// Stats the pokedex can be filtered and sorted by, total is their sum
+var pokedexStats = []string{"hp", "attack", "defense", "special_attack", "special_defense", "speed", "total"}
+
+// The generation a species was introduced in, 0 if unknown
+func generationOf(id int32) int {
+	for i, g := range generations {
+		if id >= g.first && id <= g.last {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+type pokedexDTO struct {
+	ID             int32  `json:"id"`
+	Name           string `json:"name"`
+	DisplayName    string `json:"display_name"`
+	Type1          string `json:"type1"`
+	Type2          string `json:"type2,omitempty"`
+	Generation     int    `json:"generation,omitempty"`
+	Hp             int32  `json:"hp"`
+	Attack         int32  `json:"attack"`
+	Defense        int32  `json:"defense"`
+	SpecialAttack  int32  `json:"special_attack"`
+	SpecialDefense int32  `json:"special_defense"`
+	Speed          int32  `json:"speed"`
+	Total          int32  `json:"total"`
+	ImageUrl       string `json:"image_url,omitempty"`
+}
+
+// The species must be loaded into loc
+func toPokedexDTO(p database.Pokedex, loc *localizer) pokedexDTO {
+	return pokedexDTO{
+		ID:             p.ID,
+		Name:           p.Name,
+		DisplayName:    loc.speciesName(p),
+		Type1:          p.Type1,
+		Type2:          p.Type2.String,
+		Generation:     generationOf(p.ID),
+		Hp:             p.Hp,
+		Attack:         p.Attack,
+		Defense:        p.Defense,
+		SpecialAttack:  p.SpecialAttack,
+		SpecialDefense: p.SpecialDefense,
+		Speed:          p.Speed,
+		Total:          p.Hp + p.Attack + p.Defense + p.SpecialAttack + p.SpecialDefense + p.Speed,
+		ImageUrl:       p.ImageUrl.String,
+	}
+}
+
+type moveInfoDTO struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+	Power       int32  `json:"power"`
+	DamageClass string `json:"damage_class"`
+	Priority    int32  `json:"priority"`
+	Target      string `json:"target"`
+	MinHits     int32  `json:"min_hits,omitempty"`
+	MaxHits     int32  `json:"max_hits,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// The move must be loaded into loc
+func toMoveInfoDTO(m database.Move, loc *localizer) moveInfoDTO {
+	return moveInfoDTO{
+		ID:          m.MoveID,
+		Name:        m.Name,
+		DisplayName: loc.moveName(m.MoveID, m.Name),
+		Type:        m.Type,
+		Power:       m.Power,
+		DamageClass: m.DamageClass,
+		Priority:    m.Priority,
+		Target:      m.Target,
+		MinHits:     m.MinHits.Int32,
+		MaxHits:     m.MaxHits.Int32,
+		Description: loc.moveDescription(m),
+	}
+}
+
+// Lists cached species, no login needed. Nothing is fetched from PokéAPI.
+func (cfg *Config) GetPokedexHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+	page, pageSize, err := parsePagination(r, defaultPokedexPageSize, maxPokedexPageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	filter, err := parsePokedexFilter(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	query := r.URL.Query()
+	sort := strings.ToLower(query.Get("sort"))
+	if sort == "" {
+		sort = "id"
+	}
+	if sort != "id" && sort != "name" && !slices.Contains(pokedexStats, sort) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "sort must be id, name, total or a stat"})
+		return
+	}
+	order := strings.ToLower(query.Get("order"))
+	if order != "" && order != "asc" && order != "desc" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "order must be asc or desc"})
+		return
+	}
+
+	ctx := r.Context()
+	total, err := cfg.DB.CountPokedexSearch(ctx, filter)
+	if err != nil {
+		log.Printf("error counting pokedex: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	rows, err := cfg.DB.SearchPokedex(ctx, database.SearchPokedexParams{
+		Type:       filter.Type,
+		MinID:      filter.MinID,
+		MaxID:      filter.MaxID,
+		Stat:       filter.Stat,
+		MinStat:    filter.MinStat,
+		MaxStat:    filter.MaxStat,
+		Descending: order == "desc",
+		Sort:       sort,
+		RowLimit:   int32(pageSize),
+		RowOffset:  int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		log.Printf("error listing pokedex: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	loc.loadSpecies(ctx, rows...)
+	pokemon := make([]pokedexDTO, 0, len(rows))
+	for _, p := range rows {
+		pokemon = append(pokemon, toPokedexDTO(p, loc))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"page":      page,
+		"page_size": pageSize,
+		"total":     total,
+		"pokemon":   pokemon,
+	})
+}
+
+// Reads the type, generation and stat range filters of /GetPokedex
+func parsePokedexFilter(r *http.Request) (database.CountPokedexSearchParams, error) {
+	query := r.URL.Query()
+	filter := database.CountPokedexSearchParams{
+		MinID:   1,
+		MaxID:   math.MaxInt32,
+		Stat:    "total",
+		MaxStat: math.MaxInt32,
+	}
+
+	if t := strings.ToLower(query.Get("type")); t != "" {
+		if !battle.IsType(t) {
+			return filter, errors.New("type must be one of the 18 types")
+		}
+		filter.Type = sql.NullString{String: t, Valid: true}
+	}
+	if v := query.Get("generation"); v != "" {
+		gen, err := strconv.Atoi(v)
+		if err != nil || gen < 1 || gen > len(generations) {
+			return filter, errors.New("generation must be 1 to " + strconv.Itoa(len(generations)))
+		}
+		filter.MinID, filter.MaxID = generations[gen-1].first, generations[gen-1].last
+	}
+	if v := strings.ToLower(query.Get("stat")); v != "" {
+		if !slices.Contains(pokedexStats, v) {
+			return filter, errors.New("stat must be hp, attack, defense, special_attack, special_defense, speed or total")
+		}
+		filter.Stat = v
+	}
+	for _, bound := range []struct {
+		param string
+		dst   *int32
+	}{{"min_stat", &filter.MinStat}, {"max_stat", &filter.MaxStat}} {
+		if v := query.Get(bound.param); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > math.MaxInt32 {
+				return filter, errors.New(bound.param + " must be a non-negative integer")
+			}
+			*bound.dst = int32(n)
+		}
+	}
+	if filter.MinStat > filter.MaxStat {
+		return filter, errors.New("min_stat can't be more than max_stat")
+	}
+	return filter, nil
+}
+
+// Gets one species with its abilities and moves, no login needed. Like the
+// listing it only answers from the cache, so anonymous requests can't make us
+// spend PokéAPI calls or fill the database.
+func (cfg *Config) GetPokedexEntryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+	identifier := strings.TrimSpace(r.URL.Query().Get("pokemon_identifier"))
+	if identifier == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier is required"})
+		return
+	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	species, err := cfg.getCachedPokemon(ctx, identifier)
+	if err == sql.ErrNoRows {
+		writeLookupError(w, cfg.speciesNotFound(ctx, identifier))
+		return
+	} else if err != nil {
+		log.Printf("error getting pokedex entry: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	abilities, err := cfg.DB.GetPokemonAbilities(ctx, species.ID)
+	if err != nil {
+		log.Printf("error getting pokemon abilities: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	moves, err := cfg.DB.GetPokemonMoves(ctx, species.ID)
+	if err != nil {
+		log.Printf("error getting pokemon moves: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	type abilityDTO struct {
+		Name     string `json:"name"`
+		IsHidden bool   `json:"is_hidden"`
+	}
+	loc.loadSpecies(ctx, species)
+	loc.loadMoves(ctx, moves...)
+	resp := struct {
+		pokedexDTO
+		FlavorText string        `json:"flavor_text,omitempty"`
+		Abilities  []abilityDTO  `json:"abilities"`
+		Moves      []moveInfoDTO `json:"moves"`
+	}{
+		pokedexDTO: toPokedexDTO(species, loc),
+		FlavorText: loc.speciesFlavorText(species),
+		Abilities:  make([]abilityDTO, 0, len(abilities)),
+		Moves:      make([]moveInfoDTO, 0, len(moves)),
+	}
+	for _, a := range abilities {
+		resp.Abilities = append(resp.Abilities, abilityDTO{Name: a.Ability, IsHidden: a.IsHidden})
+	}
+	for _, m := range moves {
+		resp.Moves = append(resp.Moves, toMoveInfoDTO(m, loc))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -12,86 +12,78 @@ import (
 	"strings"
 	"time"
 
+	"github.com/JadedPigeon/pokemongolang/internal/battle"
 	"github.com/JadedPigeon/pokemongolang/internal/database"
-	"github.com/JadedPigeon/pokemongolang/internal/describe"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 )
 
-const pokeapi = "https://pokeapi.co/api/v2/pokemon/"
-
-type PokeAPIResponse struct {
-	ID    int    `json:"id"`
-	Name  string `json:"name"`
-	Types []struct {
-		Slot int `json:"slot"`
-		Type struct {
-			Name string `json:"name"`
-		} `json:"type"`
-	} `json:"types"`
-	Stats []struct {
-		BaseStat int `json:"base_stat"`
-		Stat     struct {
-			Name string `json:"name"`
-		} `json:"stat"`
-	} `json:"stats"`
-	Moves []struct {
-		Move struct {
-			Name string `json:"name"`
-			URL  string `json:"url"`
-		} `json:"move"`
-	} `json:"moves"`
-	Sprites struct {
-		Other struct {
-			OfficialArtwork struct {
-				FrontDefault string `json:"front_default"`
-			} `json:"official-artwork"`
-		} `json:"other"`
-	} `json:"sprites"`
-}
-
-// Check if pokemon exists in db, if not get it, then return pokemon data
+// Check if pokemon exists in db, if not get it, then return pokemon data.
+// Species neither has are a *SpeciesNotFoundError
 func (cfg *Config) GetPokemon(ctx context.Context, identifier string) (*database.Pokedex, error) {
-	var (
-		pokedexEntry database.Pokedex
-		err          error
-	)
-
-	if id, parseErr := strconv.Atoi(identifier); parseErr == nil {
-		pokedexEntry, err = cfg.DB.FetchPokemonDataById(ctx, int32(id))
-	} else {
-		pokedexEntry, err = cfg.DB.FetchPokemonDataByName(ctx, strings.ToLower(identifier))
-	}
-
+	pokedexEntry, err := cfg.getCachedPokemon(ctx, identifier)
 	if err == nil {
 		return &pokedexEntry, nil
 	} else if err != sql.ErrNoRows {
 		return nil, err
 	}
 
+	// Don't ask PokeAPI again about a species it recently didn't have
+	if cfg.misses.has(identifier) {
+		return nil, cfg.speciesNotFound(ctx, identifier)
+	}
+
 	// If not found, fetch from API and insert
 	if fetchErr := cfg.FetchPokemonData(ctx, identifier); fetchErr != nil {
+		if errors.Is(fetchErr, pokeapi.ErrNotFound) {
+			cfg.misses.add(identifier)
+			return nil, cfg.speciesNotFound(ctx, identifier)
+		}
 		log.Printf("error fetching pokemon data: %s", fetchErr)
 		return nil, fetchErr
 	}
 
 	// Try fetching again after insert
-	if id, parseErr := strconv.Atoi(identifier); parseErr == nil {
-		pokedexEntry, err = cfg.DB.FetchPokemonDataById(ctx, int32(id))
-	} else {
-		pokedexEntry, err = cfg.DB.FetchPokemonDataByName(ctx, strings.ToLower(identifier))
-	}
+	pokedexEntry, err = cfg.getCachedPokemon(ctx, identifier)
 	if err != nil {
 		return nil, err
 	}
 	return &pokedexEntry, nil
 }
 
-// Get pokemon from PokeAPI and insert in db
+// Look up a species in the local cache only, by id or name
+func (cfg *Config) getCachedPokemon(ctx context.Context, identifier string) (database.Pokedex, error) {
+	if id, err := strconv.Atoi(identifier); err == nil {
+		return cfg.DB.FetchPokemonDataById(ctx, int32(id))
+	}
+	return cfg.DB.FetchPokemonDataByName(ctx, strings.ToLower(identifier))
+}
+
+// Get pokemon from PokeAPI and insert in db. Concurrent fetches of the same
+// species share one PokeAPI call and one insert
 func (cfg *Config) FetchPokemonData(ctx context.Context, identifier string) error {
-	var data PokeAPIResponse
-	if err := getJSON(ctx, fmt.Sprintf("%s%s", pokeapi, identifier), &data); err != nil {
+	data, err := shared(ctx, &cfg.fetches, "pokemon:"+strings.ToLower(identifier), func(ctx context.Context) (*pokeapi.Pokemon, error) {
+		return cfg.PokeAPI.Pokemon(ctx, identifier)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to fetch data: %w", err)
 	}
+	// Keyed by ID too, since the same species can be asked for by name and number
+	_, err = shared(ctx, &cfg.fetches, "pokedex:"+strconv.Itoa(data.ID), func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, cfg.storePokemon(ctx, data)
+	})
+	return err
+}
+
+// Insert a pokemon fetched from PokeAPI with its abilities and up to 4 moves,
+// unless another request already has
+func (cfg *Config) storePokemon(ctx context.Context, data *pokeapi.Pokemon) error {
+	if _, err := cfg.DB.FetchPokemonDataById(ctx, int32(data.ID)); err == nil {
+		return nil
+	} else if err != sql.ErrNoRows {
+		return err
+	}
 
 	// Pokemon may have one or two types, handle accordingly
 	var type2 sql.NullString
@@ -114,21 +106,10 @@ func (cfg *Config) FetchPokemonData(ctx context.Context, identifier string) erro
 		}
 	}
 
-	err := cfg.DB.InsertPokedex(ctx, database.InsertPokedexParams{
-		ID:             int32(data.ID),
-		Name:           strings.ToLower(data.Name),
-		Type1:          strings.ToLower(data.Types[0].Type.Name),
-		Type2:          type2,
-		Hp:             stats["hp"],
-		Attack:         stats["attack"],
-		Defense:        stats["defense"],
-		SpecialAttack:  stats["special-attack"],
-		SpecialDefense: stats["special-defense"],
-		Speed:          stats["speed"],
-		ImageUrl:       sql.NullString{String: data.Sprites.Other.OfficialArtwork.FrontDefault, Valid: true},
-	})
+	// Fetched before anything is stored, so failing leaves nothing half cached
+	texts, err := cfg.fetchSpeciesTexts(ctx, data)
 	if err != nil {
-		return fmt.Errorf("error inserting pokemon into db: %w", err)
+		return fmt.Errorf("failed to fetch species names: %w", err)
 	}
 
 	// Select up to 4 moves, prioritizing same-type moves
@@ -146,36 +127,41 @@ func (cfg *Config) FetchPokemonData(ctx context.Context, identifier string) erro
 	sameType := make([]int, 0, 4)
 	others := make([]int, 0, 4)
 
+	// Sort a move into a preference bucket if it's worth knowing
+	consider := func(moveID int, name, moveType string, damaging bool) {
+		_, stab := pokeTypes[strings.ToLower(moveType)]
+		switch {
+		case battle.IsWeatherMove(name):
+			// Weather moves are the only status moves worth knowing so far
+			if len(others) < 4 {
+				others = append(others, moveID)
+			}
+		case !damaging:
+		case stab:
+			if len(sameType) < 4 {
+				sameType = append(sameType, moveID)
+			}
+		case len(others) < 4:
+			others = append(others, moveID)
+		}
+	}
+
 	const maxAPICalls = 8 // safety valve for slow networks / rate limits
-	apiCalls := 0
+	var toFetch []int
 
 	for _, m := range data.Moves {
 		// Stop once we know we can fill 4 (best case)
 		if len(sameType) == 4 {
 			break
 		}
-		// Parse move ID from URL
-		parts := strings.Split(strings.Trim(m.Move.URL, "/"), "/")
-		if len(parts) == 0 {
-			continue
-		}
-		moveID, err := strconv.Atoi(parts[len(parts)-1])
-		if err != nil {
+		moveID, ok := m.Move.ID()
+		if !ok {
 			continue
 		}
 
 		// 1) Try DB first (zero HTTP). Your moves table has Power and Type.
 		if dbMove, err := cfg.DB.GetMoveByID(ctx, int32(moveID)); err == nil {
-			if dbMove.Power > 0 { // power>0 implies non-status
-				if _, ok := pokeTypes[strings.ToLower(dbMove.Type)]; ok {
-					if len(sameType) < 4 {
-						sameType = append(sameType, moveID)
-					}
-				} else if len(others) < 4 {
-					others = append(others, moveID)
-				}
-			}
-			// Already decided from DB; continue to next move.
+			consider(moveID, dbMove.Name, dbMove.Type, dbMove.Power > 0) // power>0 implies non-status
 			continue
 		} else if err != sql.ErrNoRows {
 			// Unexpected DB error; skip this move gracefully
@@ -183,28 +169,33 @@ func (cfg *Config) FetchPokemonData(ctx context.Context, identifier string) erro
 		}
 
 		// 2) Not in DB: fall back to API, but respect a hard cap to avoid N calls.
-		if apiCalls >= maxAPICalls {
-			continue
+		if len(toFetch) < maxAPICalls {
+			toFetch = append(toFetch, moveID)
 		}
-		md, err := cfg.FetchPokemonMoveData(ctx, moveID)
-		apiCalls++
-		if err != nil || md == nil || md.Power == nil || md.DamageClass.Name == "status" {
+	}
+
+	// 3) Fetch the uncached moves in parallel, then sort them in shuffled order
+	fetched := make([]*pokeapi.Move, len(toFetch))
+	var g errgroup.Group
+	g.SetLimit(moveFetchWorkers)
+	for i, moveID := range toFetch {
+		g.Go(func() error {
+			if md, err := cfg.FetchPokemonMoveData(ctx, strconv.Itoa(moveID)); err == nil {
+				fetched[i] = md
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	for i, md := range fetched {
+		if md == nil {
 			continue
 		}
-
 		// filter out “bad” description moves (see helper functions below)
-		if desc := getLatestEnglishDescription(md.FlavorTextEntries); isBannedDescription(desc) {
+		if desc := getLatestDescription(md.FlavorTextEntries, defaultLanguage); isBannedDescription(desc) {
 			continue
 		}
-
-		moveType := strings.ToLower(md.Type.Name)
-		if _, ok := pokeTypes[moveType]; ok {
-			if len(sameType) < 4 {
-				sameType = append(sameType, moveID)
-			}
-		} else if len(others) < 4 {
-			others = append(others, moveID)
-		}
+		consider(toFetch[i], md.Name, md.Type.Name, md.Power != nil && md.DamageClass.Name != "status")
 	}
 
 	// Merge preference buckets, cap at 4
@@ -216,33 +207,44 @@ func (cfg *Config) FetchPokemonData(ctx context.Context, identifier string) erro
 		selected = selected[:4]
 	}
 
-	// Link moves (use ON CONFLICT DO NOTHING in SQL to avoid dup errors)
-	for _, moveID := range selected {
-		if err := cfg.DB.InsertPokemonMove(ctx, database.InsertPokemonMoveParams{
-			PokemonID: int32(data.ID),
-			MoveID:    int32(moveID),
-		}); err != nil {
-			log.Printf("link move %d -> pokemon %d: %v", moveID, data.ID, err)
+	// Everything about the species goes in at once, so a failure part way
+	// doesn't leave it cached without its names, abilities or moves
+	return cfg.withTx(ctx, func(q *database.Queries) error {
+		err := q.UpsertPokedex(ctx, database.UpsertPokedexParams{
+			ID:             int32(data.ID),
+			Name:           strings.ToLower(data.Name),
+			Type1:          strings.ToLower(data.Types[0].Type.Name),
+			Type2:          type2,
+			Hp:             stats["hp"],
+			Attack:         stats["attack"],
+			Defense:        stats["defense"],
+			SpecialAttack:  stats["special-attack"],
+			SpecialDefense: stats["special-defense"],
+			Speed:          stats["speed"],
+			ImageUrl:       sql.NullString{String: data.Sprites.Other.OfficialArtwork.FrontDefault, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("error inserting pokemon into db: %w", err)
 		}
-	}
-	return nil
-}
-
-type MoveDetail struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Power       *int   `json:"power"`
-	DamageClass struct {
-		Name string `json:"name"`
-	} `json:"damage_class"`
-	Type struct {
-		Name string `json:"name"`
-	} `json:"type"`
-	FlavorTextEntries []struct {
-		FlavorText   string                `json:"flavor_text"`
-		Language     struct{ Name string } `json:"language"`
-		VersionGroup struct{ Name string } `json:"version_group"`
-	} `json:"flavor_text_entries"`
+		if err := storePokemonNames(ctx, q, int32(data.ID), texts); err != nil {
+			return fmt.Errorf("error inserting pokemon names into db: %w", err)
+		}
+		if err := storeSprites(ctx, q, int32(data.ID), data); err != nil {
+			return fmt.Errorf("error inserting pokemon sprites into db: %w", err)
+		}
+		if err := storeAbilities(ctx, q, *data); err != nil {
+			return err
+		}
+		for _, moveID := range selected {
+			if err := q.InsertPokemonMove(ctx, database.InsertPokemonMoveParams{
+				PokemonID: int32(data.ID),
+				MoveID:    int32(moveID),
+			}); err != nil {
+				return fmt.Errorf("error linking move %d to pokemon %d: %w", moveID, data.ID, err)
+			}
+		}
+		return nil
+	})
 }
 
 // helper functions to get the latest English description of a move
@@ -285,50 +287,88 @@ func isBannedDescription(desc string) bool {
 		strings.Contains(d, "once forgotten, this move can't be remembered")
 }
 
-func getLatestEnglishDescription(entries []struct {
-	FlavorText   string                `json:"flavor_text"`
-	Language     struct{ Name string } `json:"language"`
-	VersionGroup struct{ Name string } `json:"version_group"`
-}) string {
+// The latest flavor text in a language, PokéAPI lists them oldest first
+func getLatestDescription(entries []pokeapi.FlavorText, lang string) string {
 	for i := len(entries) - 1; i >= 0; i-- {
-		if entries[i].Language.Name == "en" {
+		if entries[i].Language.Name == lang {
 			return strings.TrimSpace(entries[i].FlavorText)
 		}
 	}
 	return ""
 }
 
-// Fetches move data from the PokeAPI and inserts it into the db if it doesn't already exist
-func (cfg *Config) FetchPokemonMoveData(ctx context.Context, moveID int) (*MoveDetail, error) {
-	moveURL := fmt.Sprintf("https://pokeapi.co/api/v2/move/%d/", moveID)
-	var move MoveDetail
-	if err := getJSON(ctx, moveURL, &move); err != nil {
+// Check if a move exists in db, if not get it, then return move data. The
+// identifier can be a move ID or name
+func (cfg *Config) GetMove(ctx context.Context, identifier string) (database.Move, error) {
+	move, err := cfg.getCachedMove(ctx, identifier)
+	if err != sql.ErrNoRows {
+		return move, err
+	}
+	if _, err := cfg.FetchPokemonMoveData(ctx, strings.ToLower(identifier)); err != nil {
+		return database.Move{}, err
+	}
+	return cfg.getCachedMove(ctx, identifier)
+}
+
+// Look up a move in the local cache only, by id or name
+func (cfg *Config) getCachedMove(ctx context.Context, identifier string) (database.Move, error) {
+	if id, err := strconv.Atoi(identifier); err == nil {
+		return cfg.DB.GetMoveByID(ctx, int32(id))
+	}
+	return cfg.DB.GetMoveByName(ctx, strings.ToLower(identifier))
+}
+
+// Fetches move data from the PokeAPI by ID or name and upserts it into the db.
+// Concurrent fetches of the same move share one call
+func (cfg *Config) FetchPokemonMoveData(ctx context.Context, identifier string) (*pokeapi.Move, error) {
+	return shared(ctx, &cfg.fetches, "move:"+strings.ToLower(identifier), func(ctx context.Context) (*pokeapi.Move, error) {
+		return cfg.fetchMove(ctx, identifier)
+	})
+}
+
+func (cfg *Config) fetchMove(ctx context.Context, identifier string) (*pokeapi.Move, error) {
+	move, err := cfg.PokeAPI.Move(ctx, identifier)
+	if err != nil {
 		return nil, fmt.Errorf("fetch move: %w", err)
 	}
 
-	// Check if move already exists
-	_, err := cfg.DB.GetMoveByID(ctx, int32(move.ID))
-	if err != nil && err != sql.ErrNoRows {
-		return nil, fmt.Errorf("error checking move in db: %w", err)
-	} else if err == sql.ErrNoRows {
-		description := getLatestEnglishDescription(move.FlavorTextEntries)
-		power := int32(0)
-		if move.Power != nil {
-			power = int32(*move.Power)
-		}
-		err = cfg.DB.InsertMove(ctx, database.InsertMoveParams{
-			MoveID:      int32(move.ID),
-			Name:        move.Name,
-			Power:       power,
-			Type:        move.Type.Name,
-			Description: sql.NullString{String: description, Valid: description != ""},
-		})
-		if err != nil {
-			return nil, fmt.Errorf("error inserting move: %w", err)
-		}
+	description := getLatestDescription(move.FlavorTextEntries, defaultLanguage)
+	power := int32(0)
+	if move.Power != nil {
+		power = int32(*move.Power)
+	}
+	damageClass := move.DamageClass.Name
+	if damageClass == "" {
+		damageClass = battle.Physical
+	}
+	target := move.Target.Name
+	if target == "" {
+		target = battle.TargetSelected
+	}
+	var minHits, maxHits sql.NullInt32
+	if move.Meta != nil && move.Meta.MinHits != nil && move.Meta.MaxHits != nil {
+		minHits = sql.NullInt32{Int32: int32(*move.Meta.MinHits), Valid: true}
+		maxHits = sql.NullInt32{Int32: int32(*move.Meta.MaxHits), Valid: true}
+	}
+	if err := cfg.DB.UpsertMove(ctx, database.UpsertMoveParams{
+		MoveID:      int32(move.ID),
+		Name:        move.Name,
+		Power:       power,
+		Type:        move.Type.Name,
+		Description: sql.NullString{String: description, Valid: description != ""},
+		DamageClass: damageClass,
+		Priority:    int32(move.Priority),
+		MinHits:     minHits,
+		MaxHits:     maxHits,
+		Target:      target,
+	}); err != nil {
+		return nil, fmt.Errorf("error inserting move: %w", err)
+	}
+	if err := cfg.storeMoveNames(ctx, move); err != nil {
+		return nil, fmt.Errorf("error inserting move names: %w", err)
 	}
 
-	return &move, nil
+	return move, nil
 }
 
 // Catch pokemon
@@ -351,6 +391,10 @@ func (cfg *Config) CatchPokemonHandler(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier is required"})
 		return
 	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
 
 	ctx := r.Context()
 
@@ -361,68 +405,131 @@ func (cfg *Config) CatchPokemonHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get pokemon ID
-	pokemonEntry, err := cfg.GetPokemon(ctx, pokemon)
-	if err != nil {
-		log.Printf("error checking for existing pokemon: %s", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
-		return
+	// Catching throws a ball from the bag, a poke-ball unless ball picks
+	// another. Anything in a bag is already cached
+	ballName := strings.TrimSpace(r.PostForm.Get("ball"))
+	if ballName == "" {
+		ballName = defaultBall
 	}
-
-	// Add pokemon to the user's collection
-	partysize, err := cfg.DB.CountUserPokemon(ctx, user.ID)
-	if err != nil {
+	ball, err := cfg.getCachedItem(ctx, ballName)
+	if err == sql.ErrNoRows {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("You don't have any %s, buy some from the shop", ballName)})
+		return
+	} else if err != nil {
+		log.Printf("error getting ball: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 		return
 	}
-	if partysize >= 6 {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "You can only have at most six pokemon in your party"})
+	if !ballCategories[ball.Category] {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("%s isn't a ball", ball.Name)})
 		return
 	}
 
-	newUPID := uuid.New()
-	err = cfg.DB.InsertUserPokemon(ctx, database.InsertUserPokemonParams{
-		ID:        newUPID,
-		UserID:    user.ID,
-		PokemonID: sql.NullInt32{Valid: true, Int32: int32(pokemonEntry.ID)},
-		Nickname:  sql.NullString{Valid: false},
-		CurrentHp: int32(pokemonEntry.Hp),
-		IsActive:  false,
-	})
+	// Get pokemon ID
+	pokemonEntry, err := cfg.GetPokemon(ctx, pokemon)
 	if err != nil {
-		log.Printf("error inserting user pokemon: %s", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		log.Printf("error checking for existing pokemon: %s", err)
+		writeLookupError(w, err)
 		return
 	}
 
-	// Set the new pokemon as active
-	err = cfg.DB.DeactivateAllUserPokemon(ctx, user.ID)
+	ability, err := cfg.rollAbility(ctx, pokemonEntry.ID)
 	if err != nil {
-		log.Printf("error deactivating user's pokemon to set new active: %s", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		log.Printf("error rolling ability: %s", err)
+		writeLookupError(w, err)
 		return
 	}
 
-	_, err = cfg.DB.ActivateUserPokemon(ctx, database.ActivateUserPokemonParams{
-		UserID: user.ID,
-		ID:     newUPID,
-	})
-	if err != nil {
+	// Add pokemon to the user's collection, sending it to the PC box if the
+	// party is full. A party member becomes the active pokemon.
+	newUPID := uuid.New()
+	var (
+		toBox     bool
+		ballsLeft int32
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		partySlot, err := reservePartySpace(ctx, q, user.ID, true)
+		if err != nil {
+			return err
+		}
+		ballsLeft, err = q.ConsumeUserItem(ctx, database.ConsumeUserItemParams{
+			UserID: user.ID,
+			ItemID: ball.ID,
+		})
 		if err == sql.ErrNoRows {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "pokemon not owned by user"})
-			return
+			return ErrItemNotInBag
+		} else if err != nil {
+			return err
 		}
-		log.Printf("activate failed: %v", err)
+		toBox = !partySlot.Valid
+		if err := q.InsertUserPokemon(ctx, database.InsertUserPokemonParams{
+			ID:        newUPID,
+			UserID:    user.ID,
+			PokemonID: sql.NullInt32{Valid: true, Int32: int32(pokemonEntry.ID)},
+			Nickname:  sql.NullString{Valid: false},
+			CurrentHp: maxHP(*pokemonEntry),
+			IsActive:  false,
+			InBox:     toBox,
+			PartySlot: partySlot,
+			Ability:   ability,
+		}); err != nil {
+			return fmt.Errorf("error inserting user pokemon: %w", err)
+		}
+		// Boxed pokemon can't be active, leave the current active pokemon alone
+		if toBox {
+			return nil
+		}
+		if err := q.DeactivateAllUserPokemon(ctx, user.ID); err != nil {
+			return fmt.Errorf("error deactivating user's pokemon to set new active: %w", err)
+		}
+		_, err = q.ActivateUserPokemon(ctx, database.ActivateUserPokemonParams{
+			UserID: user.ID,
+			ID:     newUPID,
+		})
+		return err
+	})
+	if errors.Is(err, errPartyAndBoxFull) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Your party and PC box are both full"})
+		return
+	} else if errors.Is(err, ErrItemNotInBag) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("You don't have any %s, buy some from the shop", ball.Name)})
+		return
+	} else if err != nil {
+		log.Printf("error catching pokemon: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 		return
 	}
+	cfg.recordProgress(ctx, user.ID, pokemonEntry.ID, true)
+	loc.loadSpecies(ctx, *pokemonEntry)
+
+	if toBox {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"message":         "Pokemon caught successfully, your party is full so it was sent to your PC box",
+			"user_pokemon_id": newUPID,
+			"pokemon_id":      pokemonEntry.ID,
+			"pokemon_name":    pokemonEntry.Name,
+			"display_name":    loc.speciesName(*pokemonEntry),
+			"ability":         ability.String,
+			"in_box":          true,
+			"ball":            ball.Name,
+			"balls_left":      ballsLeft,
+			"user_username":   user.Username,
+		})
+		return
+	}
 
 	// Return success response
 	response := map[string]interface{}{
-		"message":       "Pokemon caught successfully",
-		"pokemon_id":    pokemonEntry.ID,
-		"pokemon_name":  pokemonEntry.Name,
-		"user_username": user.Username,
+		"message":         "Pokemon caught successfully",
+		"user_pokemon_id": newUPID,
+		"pokemon_id":      pokemonEntry.ID,
+		"pokemon_name":    pokemonEntry.Name,
+		"display_name":    loc.speciesName(*pokemonEntry),
+		"ability":         ability.String,
+		"in_box":          false,
+		"ball":            ball.Name,
+		"balls_left":      ballsLeft,
+		"user_username":   user.Username,
 	}
 	writeJSON(w, http.StatusOK, response)
 }
@@ -445,6 +552,10 @@ func (cfg *Config) ChooseChallengePokemonHandler(w http.ResponseWriter, r *http.
 		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier is required"})
 		return
 	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
 
 	ctx := r.Context()
 	user, ok := ctx.Value(userContextKey).(*database.User)
@@ -457,12 +568,99 @@ func (cfg *Config) ChooseChallengePokemonHandler(w http.ResponseWriter, r *http.
 	pokemonEntry, err := cfg.GetPokemon(ctx, pokemon)
 	if err != nil {
 		log.Printf("error checking for existing pokemon: %s", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		writeLookupError(w, err)
+		return
+	}
+
+	// Trainer battles pay prize money and count towards the leaderboard, wild
+	// pokemon can be fled from
+	kind := r.PostForm.Get("battle_type")
+	if kind == "" {
+		kind = battleKindTrainer
+	}
+	if kind != battleKindTrainer && kind != battleKindWild {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "battle_type must be trainer or wild"})
+		return
+	}
+
+	// Double battles put a second challenger out, the same species unless
+	// partner_identifier picks another. Only trainers battle in pairs
+	format := r.PostForm.Get("battle_format")
+	if format == "" {
+		format = battleFormatSingles
+	}
+	if format != battleFormatSingles && format != battleFormatDoubles {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "battle_format must be singles or doubles"})
+		return
+	}
+	if format == battleFormatDoubles && kind != battleKindTrainer {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Double battles must be trainer battles"})
+		return
+	}
+	var (
+		partnerEntry   *database.Pokedex
+		partnerAbility sql.NullString
+	)
+	if format == battleFormatDoubles {
+		partner := r.PostForm.Get("partner_identifier")
+		if partner == "" {
+			partner = pokemon
+		}
+		partnerEntry, err = cfg.GetPokemon(ctx, partner)
+		if err != nil {
+			log.Printf("error checking for existing partner pokemon: %s", err)
+			writeLookupError(w, err)
+			return
+		}
+		partnerAbility, err = cfg.rollAbility(ctx, partnerEntry.ID)
+		if err != nil {
+			log.Printf("error rolling challenger partner ability: %s", err)
+			writeLookupError(w, err)
+			return
+		}
+	}
+
+	// Challengers can optionally hold an item
+	var heldItemID sql.NullInt32
+	if held := r.PostForm.Get("held_item"); held != "" {
+		item, err := cfg.GetItem(ctx, held)
+		if errors.Is(err, pokeapi.ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("No item called %q", held)})
+			return
+		} else if err != nil {
+			log.Printf("error getting challenger held item: %s", err)
+			writeLookupError(w, err)
+			return
+		}
+		heldItemID = sql.NullInt32{Int32: item.ID, Valid: true}
+	}
+
+	ability, err := cfg.rollAbility(ctx, pokemonEntry.ID)
+	if err != nil {
+		log.Printf("error rolling challenger ability: %s", err)
+		writeLookupError(w, err)
 		return
 	}
 
-	// Remove previous challenge pokemon if exists
+	// Remove previous challenge pokemon if exists, a battle against it that
+	// was already underway counts as fled or forfeited and one that never
+	// started is dropped
 	if user.ChallengePokemonID.Valid {
+		if err := cfg.DB.AbandonBattle(ctx, user.ChallengePokemonID); err != nil {
+			log.Printf("Failed to end previous battle: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return
+		}
+		if err := cfg.DB.DeleteUnstartedBattle(ctx, user.ChallengePokemonID); err != nil {
+			log.Printf("Failed to delete previous battle: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return
+		}
+		if err := cfg.DB.DeleteChallengerPartner(ctx, user.ChallengePokemonID); err != nil {
+			log.Printf("Failed to delete previous challenge partner: %v", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return
+		}
 		if err := cfg.DB.DeleteChallengePokemon(ctx, user.ChallengePokemonID.UUID); err != nil {
 			log.Printf("Failed to delete previous challenge: %v", err)
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
@@ -473,15 +671,46 @@ func (cfg *Config) ChooseChallengePokemonHandler(w http.ResponseWriter, r *http.
 	// Insert new challenge pokemon
 	challengePokemonID := uuid.New()
 	if err := cfg.DB.InsertChallengePokemon(ctx, database.InsertChallengePokemonParams{
-		ID:        challengePokemonID,
-		PokemonID: sql.NullInt32{Valid: true, Int32: int32(pokemonEntry.ID)},
-		CurrentHp: int32(pokemonEntry.Hp),
+		ID:         challengePokemonID,
+		PokemonID:  sql.NullInt32{Valid: true, Int32: int32(pokemonEntry.ID)},
+		CurrentHp:  maxHP(*pokemonEntry),
+		HeldItemID: heldItemID,
+		Ability:    ability,
 	}); err != nil {
 		log.Printf("error inserting challenge pokemon: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 		return
 	}
 
+	var partnerID uuid.NullUUID
+	if partnerEntry != nil {
+		partnerID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		if err := cfg.DB.InsertChallengePokemon(ctx, database.InsertChallengePokemonParams{
+			ID:        partnerID.UUID,
+			PokemonID: sql.NullInt32{Valid: true, Int32: partnerEntry.ID},
+			CurrentHp: maxHP(*partnerEntry),
+			Ability:   partnerAbility,
+		}); err != nil {
+			log.Printf("error inserting challenge partner pokemon: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return
+		}
+	}
+
+	if err := cfg.DB.CreateBattle(ctx, database.CreateBattleParams{
+		ID:                  uuid.New(),
+		UserID:              user.ID,
+		ChallengerPokemonID: uuid.NullUUID{UUID: challengePokemonID, Valid: true},
+		ChallengerSpeciesID: sql.NullInt32{Int32: pokemonEntry.ID, Valid: true},
+		Kind:                kind,
+		Format:              format,
+		ChallengerPartnerID: partnerID,
+	}); err != nil {
+		log.Printf("error creating battle: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
 	// Link challenge pokemon to user
 	if err := cfg.DB.SetUserChallengePokemon(ctx, database.SetUserChallengePokemonParams{
 		ChallengePokemonID: uuid.NullUUID{UUID: challengePokemonID, Valid: true},
@@ -491,20 +720,38 @@ func (cfg *Config) ChooseChallengePokemonHandler(w http.ResponseWriter, r *http.
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 		return
 	}
+	cfg.recordProgress(ctx, user.ID, pokemonEntry.ID, false)
+	if partnerEntry != nil {
+		cfg.recordProgress(ctx, user.ID, partnerEntry.ID, false)
+	}
 
 	// Success response
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	loc.loadSpecies(ctx, *pokemonEntry)
+	resp := map[string]interface{}{
 		"message":       "Challenge initiated successfully",
 		"pokemon_id":    pokemonEntry.ID,
 		"pokemon_name":  pokemonEntry.Name,
+		"display_name":  loc.speciesName(*pokemonEntry),
+		"ability":       ability.String,
+		"battle_type":   kind,
+		"battle_format": format,
 		"user_username": user.Username,
-	})
+	}
+	if partnerEntry != nil {
+		resp["partner_id"] = partnerEntry.ID
+		loc.loadSpecies(ctx, *partnerEntry)
+		resp["partner_name"] = partnerEntry.Name
+		resp["partner_display_name"] = loc.speciesName(*partnerEntry)
+		resp["partner_ability"] = partnerAbility.String
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // Needed Response struct for cleaner JSON response, ie issues with displaying type 2 since they are sql.NullString
 type PokedexResponse struct {
 	ID             int32  `json:"id"`
 	Name           string `json:"name"`
+	DisplayName    string `json:"display_name"`
 	Type1          string `json:"type1"`
 	Type2          string `json:"type2,omitempty"`
 	Hp             int32  `json:"hp"`
@@ -515,6 +762,9 @@ type PokedexResponse struct {
 	Speed          int32  `json:"speed"`
 	Active         bool   `json:"active"`
 	ImageUrl       string `json:"image_url,omitempty"`
+	Nickname       string `json:"nickname,omitempty"`
+	Slot           int32  `json:"slot,omitempty"`
+	UserPokemonID  string `json:"user_pokemon_id,omitempty"`
 }
 
 func (cfg *Config) GetUserPokemonHandler(w http.ResponseWriter, r *http.Request) {
@@ -530,14 +780,25 @@ func (cfg *Config) GetUserPokemonHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
 	pokemonList, err := cfg.DB.GetAllUserPokemon(r.Context(), user.ID)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve Pokémon"})
 		return
 	}
 
-	var response []PokedexResponse
+	species := make([]database.Pokedex, 0, len(pokemonList))
 	for _, p := range pokemonList {
+		species = append(species, database.Pokedex{ID: p.ID, Name: p.Name})
+	}
+	loc.loadSpecies(ctx, species...)
+
+	var response []PokedexResponse
+	for i, p := range pokemonList {
 		type2 := ""
 		if p.Type2.Valid {
 			type2 = p.Type2.String
@@ -549,6 +810,7 @@ func (cfg *Config) GetUserPokemonHandler(w http.ResponseWriter, r *http.Request)
 		response = append(response, PokedexResponse{
 			ID:             p.ID,
 			Name:           p.Name,
+			DisplayName:    loc.speciesName(species[i]),
 			Type1:          p.Type1,
 			Type2:          type2,
 			Hp:             p.Hp,
@@ -559,6 +821,9 @@ func (cfg *Config) GetUserPokemonHandler(w http.ResponseWriter, r *http.Request)
 			Speed:          p.Speed,
 			Active:         p.IsActive,
 			ImageUrl:       img,
+			Nickname:       p.Nickname.String,
+			Slot:           p.PartySlot.Int32,
+			UserPokemonID:  p.UserPokemonID.String(),
 		})
 	}
 
@@ -576,20 +841,6 @@ func (cfg *Config) ChangeActivePokemonHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// pokemon_identifier is the ID of the pokemon
-	pokemonIDStr := r.PostForm.Get("pokemon_identifier")
-	if pokemonIDStr == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier is required"})
-		return
-	}
-
-	pokemonIDInt, err := strconv.Atoi(pokemonIDStr)
-	if err != nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier must be a valid integer"})
-		return
-	}
-	pokemonID := int32(pokemonIDInt)
-
 	ctx := r.Context()
 	user, ok := ctx.Value(userContextKey).(*database.User)
 	if !ok || user == nil {
@@ -597,23 +848,19 @@ func (cfg *Config) ChangeActivePokemonHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	// Get user_pokemon id
-	userPokemon, err := cfg.DB.GetOneUserPokemon(ctx, database.GetOneUserPokemonParams{
-		UserID:    user.ID,
-		PokemonID: sql.NullInt32{Valid: true, Int32: pokemonID},
-	})
-	if err != nil {
-		if err == sql.ErrNoRows {
-			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Pokemon not found for user"})
-			return
-		}
-		log.Printf("error getting user pokemon: %s", err)
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	// Only pokemon in the party can be active
+	userPokemon, ok := cfg.lookupUserPokemon(w, r, user.ID, inParty)
+	if !ok {
+		return
+	}
+
+	if userPokemon.CurrentHp <= 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "That pokemon has fainted, heal it first"})
 		return
 	}
 
 	// deactivate all user pokemon
-	err = cfg.DB.DeactivateAllUserPokemon(ctx, user.ID)
+	err := cfg.DB.DeactivateAllUserPokemon(ctx, user.ID)
 	if err != nil {
 		log.Printf("error deactivating user's pokemon to set new active: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
@@ -637,9 +884,10 @@ func (cfg *Config) ChangeActivePokemonHandler(w http.ResponseWriter, r *http.Req
 
 	// success response
 	writeJSON(w, http.StatusOK, map[string]string{
-		"message":       "Active pokemon changed successfully",
-		"pokemon_id":    pokemonIDStr,
-		"user_username": user.Username,
+		"message":         "Active pokemon changed successfully",
+		"user_pokemon_id": userPokemon.ID.String(),
+		"pokemon_id":      strconv.Itoa(int(userPokemon.PokemonID.Int32)),
+		"user_username":   user.Username,
 	})
 }
 
@@ -654,6 +902,10 @@ func (cfg *Config) StartBattleHandler(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 		return
 	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
 
 	// Get user's active pokemon
 	activePokemon, err := cfg.DB.GetActiveUserPokemon(ctx, user.ID)
@@ -684,6 +936,7 @@ func (cfg *Config) StartBattleHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("error fetching user pokemon data: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
 	}
 
 	// Get user's pokmon moves
@@ -703,6 +956,7 @@ func (cfg *Config) StartBattleHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("error fetching challenge pokemon data: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
 	}
 
 	// Get challenge pokemon moves
@@ -721,8 +975,13 @@ func (cfg *Config) StartBattleHandler(w http.ResponseWriter, r *http.Request) {
 	type moveDTO struct {
 		ID          int32   `json:"id"`
 		Name        string  `json:"name"`
+		DisplayName string  `json:"display_name"`
 		Power       int32   `json:"power"`
 		Type        string  `json:"type"`
+		Priority    int32   `json:"priority"`
+		Target      string  `json:"target"`
+		MinHits     *int32  `json:"min_hits,omitempty"`
+		MaxHits     *int32  `json:"max_hits,omitempty"`
 		Description *string `json:"description,omitempty"`
 	}
 
@@ -730,25 +989,33 @@ func (cfg *Config) StartBattleHandler(w http.ResponseWriter, r *http.Request) {
 		out := make([]moveDTO, 0, len(ms))
 		for _, m := range ms {
 			var desc *string
-			if m.Description.Valid {
-				desc = &m.Description.String
+			if d := loc.moveDescription(m); d != "" {
+				desc = &d
 			}
-			out = append(out, moveDTO{
+			dto := moveDTO{
 				ID:          m.MoveID,
 				Name:        m.Name,
+				DisplayName: loc.moveName(m.MoveID, m.Name),
 				Power:       m.Power,
 				Type:        m.Type,
+				Priority:    m.Priority,
+				Target:      m.Target,
 				Description: desc,
-			})
+			}
+			if m.MinHits.Valid && m.MaxHits.Valid {
+				dto.MinHits, dto.MaxHits = &m.MinHits.Int32, &m.MaxHits.Int32
+			}
+			out = append(out, dto)
 		}
 		return out
 	}
 
 	type pokemonDTO struct {
-		ID    int32    `json:"id"`
-		Name  string   `json:"name"`
-		Types []string `json:"types"`
-		Stats struct {
+		ID          int32    `json:"id"`
+		Name        string   `json:"name"`
+		DisplayName string   `json:"display_name"`
+		Types       []string `json:"types"`
+		Stats       struct {
 			HP             int32 `json:"hp"`
 			Attack         int32 `json:"attack"`
 			Defense        int32 `json:"defense"`
@@ -760,85 +1027,169 @@ func (cfg *Config) StartBattleHandler(w http.ResponseWriter, r *http.Request) {
 		Moves    []moveDTO `json:"moves"`
 	}
 
+	toPokemon := func(p database.Pokedex, moves []database.Move) pokemonDTO {
+		loc.loadSpecies(ctx, p)
+		loc.loadMoves(ctx, moves...)
+		dto := pokemonDTO{
+			ID:          p.ID,
+			Name:        p.Name,
+			DisplayName: loc.speciesName(p),
+			Types:       pokemonTypes(p),
+			ImageURL:    p.ImageUrl.String,
+			Moves:       toMoves(moves),
+		}
+		dto.Stats.HP = p.Hp
+		dto.Stats.Attack = p.Attack
+		dto.Stats.Defense = p.Defense
+		dto.Stats.SpecialAttack = p.SpecialAttack
+		dto.Stats.SpecialDefense = p.SpecialDefense
+		dto.Stats.Speed = p.Speed
+		return dto
+	}
+
+	type userSlotDTO struct {
+		UserPokemonID string       `json:"user_pokemon_id"`
+		Nickname      *string      `json:"nickname,omitempty"`
+		CurrentHP     int32        `json:"current_hp"`
+		MaxHP         int32        `json:"max_hp"`
+		IsActive      bool         `json:"is_active"`
+		Ability       string       `json:"ability,omitempty"`
+		Status        string       `json:"status,omitempty"`
+		HeldItem      *heldItemDTO `json:"held_item"`
+		Pokemon       pokemonDTO   `json:"pokemon"`
+	}
+
+	type challengerSlotDTO struct {
+		CurrentHP int32        `json:"current_hp"`
+		MaxHP     int32        `json:"max_hp"`
+		Ability   string       `json:"ability,omitempty"`
+		Status    string       `json:"status,omitempty"`
+		HeldItem  *heldItemDTO `json:"held_item"`
+		Pokemon   pokemonDTO   `json:"pokemon"`
+	}
+
 	type fightResponse struct {
-		User struct {
-			Nickname  *string    `json:"nickname,omitempty"`
-			CurrentHP int32      `json:"current_hp"`
-			IsActive  bool       `json:"is_active"`
-			Pokemon   pokemonDTO `json:"pokemon"`
-		} `json:"user"`
-		Challenger struct {
-			CurrentHP int32      `json:"current_hp"`
-			Pokemon   pokemonDTO `json:"pokemon"`
-		} `json:"challenger"`
-	}
-
-	// Build user pokemon payload
-	userPoke := pokemonDTO{
-		ID:   userPokemon.ID,
-		Name: userPokemon.Name,
-		Types: func() []string {
-			if userPokemon.Type2.Valid {
-				return []string{userPokemon.Type1, userPokemon.Type2.String}
+		Format            string             `json:"battle_format"`
+		User              userSlotDTO        `json:"user"`
+		UserPartner       *userSlotDTO       `json:"user_partner,omitempty"`
+		Challenger        challengerSlotDTO  `json:"challenger"`
+		ChallengerPartner *challengerSlotDTO `json:"challenger_partner,omitempty"`
+	}
+
+	toUserSlot := func(up database.UserPokemon, p database.Pokedex, moves []database.Move) (userSlotDTO, error) {
+		held, err := heldItem(ctx, cfg.DB, up.HeldItemID)
+		if err != nil {
+			return userSlotDTO{}, err
+		}
+		slot := userSlotDTO{
+			UserPokemonID: up.ID.String(),
+			CurrentHP:     up.CurrentHp,
+			MaxHP:         maxHP(p),
+			IsActive:      up.IsActive,
+			Ability:       up.Ability.String,
+			Status:        up.Status.String,
+			HeldItem:      held,
+			Pokemon:       toPokemon(p, moves),
+		}
+		if up.Nickname.Valid {
+			slot.Nickname = &up.Nickname.String
+		}
+		return slot, nil
+	}
+
+	toChallengerSlot := func(cp database.ChallengerPokemon, p database.Pokedex, moves []database.Move) (challengerSlotDTO, error) {
+		held, err := heldItem(ctx, cfg.DB, cp.HeldItemID)
+		if err != nil {
+			return challengerSlotDTO{}, err
+		}
+		return challengerSlotDTO{
+			CurrentHP: cp.CurrentHp,
+			MaxHP:     maxHP(p),
+			Ability:   cp.Ability.String,
+			Status:    cp.Status.String,
+			HeldItem:  held,
+			Pokemon:   toPokemon(p, moves),
+		}, nil
+	}
+
+	resp := fightResponse{Format: battleFormatSingles}
+	resp.User, err = toUserSlot(activePokemon, userPokemon, userMoves)
+	if err != nil {
+		log.Printf("error getting user held item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	resp.Challenger, err = toChallengerSlot(challengePokemon, challengePokemonDetails, challengerMoves)
+	if err != nil {
+		log.Printf("error getting challenger held item: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	// Double battles also show the second pokemon on each side. The user's
+	// partner is the one that will come out next turn
+	current, ok, err := currentBattle(ctx, cfg.DB, user)
+	if err != nil {
+		log.Printf("error getting battle: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	if ok && current.Format == battleFormatDoubles {
+		resp.Format = battleFormatDoubles
+		err := func() error {
+			party, err := cfg.DB.GetUserPartyPokemon(ctx, user.ID)
+			if err != nil {
+				return err
 			}
-			return []string{userPokemon.Type1}
-		}(),
-		ImageURL: func() string {
-			if userPokemon.ImageUrl.Valid {
-				return userPokemon.ImageUrl.String
+			if partner, ok := pickPartner(party, activePokemon.ID, current.UserPartnerID); ok {
+				species, err := cfg.DB.FetchPokemonDataById(ctx, partner.PokemonID.Int32)
+				if err != nil {
+					return err
+				}
+				moves, err := cfg.DB.GetPokemonMoves(ctx, partner.PokemonID.Int32)
+				if err != nil {
+					return err
+				}
+				slot, err := toUserSlot(partner, species, moves)
+				if err != nil {
+					return err
+				}
+				resp.UserPartner = &slot
 			}
-			return ""
-		}(),
-		Moves: toMoves(userMoves),
-	}
-	userPoke.Stats.HP = userPokemon.Hp
-	userPoke.Stats.Attack = userPokemon.Attack
-	userPoke.Stats.Defense = userPokemon.Defense
-	userPoke.Stats.SpecialAttack = userPokemon.SpecialAttack
-	userPoke.Stats.SpecialDefense = userPokemon.SpecialDefense
-	userPoke.Stats.Speed = userPokemon.Speed
-
-	// Build challenger pokemon payload
-	challengerPoke := pokemonDTO{
-		ID:   challengePokemonDetails.ID,
-		Name: challengePokemonDetails.Name,
-		Types: func() []string {
-			if challengePokemonDetails.Type2.Valid {
-				return []string{challengePokemonDetails.Type1, challengePokemonDetails.Type2.String}
+			if !current.ChallengerPartnerID.Valid {
+				return nil
 			}
-			return []string{challengePokemonDetails.Type1}
-		}(),
-		ImageURL: func() string {
-			if challengePokemonDetails.ImageUrl.Valid {
-				return challengePokemonDetails.ImageUrl.String
+			partner, err := cfg.DB.GetChallengePokemon(ctx, current.ChallengerPartnerID.UUID)
+			if err != nil {
+				return err
 			}
-			return ""
-		}(),
-		Moves: toMoves(challengerMoves),
-	}
-	challengerPoke.Stats.HP = challengePokemonDetails.Hp
-	challengerPoke.Stats.Attack = challengePokemonDetails.Attack
-	challengerPoke.Stats.Defense = challengePokemonDetails.Defense
-	challengerPoke.Stats.SpecialAttack = challengePokemonDetails.SpecialAttack
-	challengerPoke.Stats.SpecialDefense = challengePokemonDetails.SpecialDefense
-	challengerPoke.Stats.Speed = challengePokemonDetails.Speed
-
-	resp := fightResponse{}
-	if activePokemon.Nickname.Valid {
-		resp.User.Nickname = &activePokemon.Nickname.String
+			species, err := cfg.DB.FetchPokemonDataById(ctx, partner.PokemonID.Int32)
+			if err != nil {
+				return err
+			}
+			moves, err := cfg.DB.GetPokemonMoves(ctx, partner.PokemonID.Int32)
+			if err != nil {
+				return err
+			}
+			slot, err := toChallengerSlot(partner, species, moves)
+			if err != nil {
+				return err
+			}
+			resp.ChallengerPartner = &slot
+			return nil
+		}()
+		if err != nil {
+			log.Printf("error getting double battle partners: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			return
+		}
 	}
-	resp.User.CurrentHP = activePokemon.CurrentHp
-	resp.User.IsActive = activePokemon.IsActive
-	resp.User.Pokemon = userPoke
-
-	resp.Challenger.CurrentHP = challengePokemon.CurrentHp
-	resp.Challenger.Pokemon = challengerPoke
 
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// User makes a move followed by the challenger making a move
-// This function will just return a description response, no actual game logic. No pokemon are defeated
+// User makes a move, or uses an item from their bag, and the challenger makes a move
+// Moves resolve in speed order through the battle engine, HP is saved, and beating the challenger pays prize money
 func (cfg *Config) FightHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
@@ -851,19 +1202,57 @@ func (cfg *Config) FightHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// move used by user
-	moveID := r.PostForm.Get("move_id")
-	if moveID == "" {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pokemon_identifier is required"})
+	cfg.playTurn(w, r, false)
+}
+
+// Run from a wild battle or forfeit a trainer battle. Fleeing can fail, in
+// which case the challenger gets a free move
+func (cfg *Config) RunHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
 		return
 	}
 
+	cfg.playTurn(w, r, true)
+}
+
+// Plays one turn of the user's battle, with the user either fighting (a move
+// or an item) or trying to run
+func (cfg *Config) playTurn(w http.ResponseWriter, r *http.Request, run bool) {
+	// move used by user, using an item takes up the user's turn instead. A
+	// pokemon locked into charging or recharging can send neither
+	var moveID, itemIdentifier string
+	if !run {
+		moveID = r.PostForm.Get("move_id")
+		itemIdentifier = r.PostForm.Get("item_identifier")
+		if moveID != "" && itemIdentifier != "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Only one of move_id or item_identifier can be used"})
+			return
+		}
+	}
+
 	ctx := r.Context()
 	user, ok := ctx.Value(userContextKey).(*database.User)
 	if !ok || user == nil {
 		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
 		return
 	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	// Double battles have their own turn, with a move and target per pokemon
+	current, ok, err := currentBattle(ctx, cfg.DB, user)
+	if err != nil {
+		log.Printf("error getting battle: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	if ok && current.Format == battleFormatDoubles {
+		cfg.playDoublesTurn(w, r, user, run, loc)
+		return
+	}
 
 	// Get user's active pokemon
 	activePokemon, err := cfg.DB.GetActiveUserPokemon(ctx, user.ID)
@@ -894,6 +1283,7 @@ func (cfg *Config) FightHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("error fetching user pokemon data: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
 	}
 
 	// Get user's pokmon moves
@@ -913,6 +1303,7 @@ func (cfg *Config) FightHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("error fetching challenge pokemon data: %s", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
 	}
 
 	// Get challenge pokemon moves
@@ -929,98 +1320,401 @@ func (cfg *Config) FightHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Use move by User
 	var userMove *database.Move
-	for _, m := range userMoves {
-		if strconv.Itoa(int(m.MoveID)) == moveID {
-			userMove = &m
-			break
+	if moveID != "" {
+		for _, m := range userMoves {
+			if strconv.Itoa(int(m.MoveID)) == moveID {
+				userMove = &m
+				break
+			}
+		}
+		if userMove == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid move ID"})
+			return
 		}
 	}
-	if userMove == nil {
-		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid move ID"})
+
+	if len(challengerMoves) == 0 {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "No moves available for challenger"})
 		return
 	}
 
-	// Challenger move chosen randomly
-	var challengerMove *database.Move
-	if len(challengerMoves) > 0 {
-		challengerMove = &challengerMoves[rand.Intn(len(challengerMoves))]
-	} else {
-		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "No moves available for challenger"})
+	// Or use an item by User, on the active pokemon unless another party member is picked
+	var item database.Item
+	itemTarget := activePokemon.ID
+	if itemIdentifier != "" {
+		item, ok = cfg.lookupBagItem(w, r)
+		if !ok {
+			return
+		}
+		if r.PostForm.Get("user_pokemon_id") != "" {
+			target, ok := cfg.lookupUserPokemon(w, r, user.ID, inParty)
+			if !ok {
+				return
+			}
+			itemTarget = target.ID
+		}
+	}
+
+	// Resolve the whole turn in one transaction, with both pokemon locked, so
+	// concurrent Fight calls can't hit a fainted pokemon or pay out twice
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var (
+		itemUse        *itemUseResult
+		turn           battle.Turn
+		field          battle.Field
+		userSide       *battle.Pokemon
+		challengerSide *battle.Pokemon
+		prize, balance int32
+		partyFainted   bool
+		lockErr        string
+		kind           string
+		outcome        string // set when running ends the battle
+		fleeFailed     bool
+		timedOut       bool
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		challenger, err := q.GetChallengePokemonForUpdate(ctx, challengePokemon.ID)
+		if err != nil {
+			return err
+		}
+		if challenger.CurrentHp <= 0 {
+			return errChallengerFainted
+		}
+		state, err := lockBattle(ctx, q, user.ID, challenger)
+		if err != nil {
+			return err
+		}
+		if state.Result.Valid {
+			return errBattleOver
+		}
+		if cfg.turnTimedOut(state) {
+			outcome, timedOut = battleForfeited, true
+			return endBattle(ctx, q, user.ID, state.ID, challenger.ID, outcome)
+		}
+		kind = state.Kind
+		fleeAttempts := state.FleeAttempts
+		field = battle.Field{Weather: state.Weather.String, WeatherTurns: int(state.WeatherTurns)}
+
+		// A charging or recharging pokemon is locked in until it's switched out
+		var userCharging *battle.Move
+		userRecharging := false
+		if state.UserPokemonID.Valid && state.UserPokemonID.UUID == activePokemon.ID {
+			userCharging = lockedMove(userMoves, state.UserChargingMoveID)
+			userRecharging = state.UserRecharging
+		}
+		acting := itemIdentifier != "" || run
+		switch {
+		case userCharging != nil && (acting || (userMove != nil && userMove.MoveID != userCharging.ID)):
+			lockErr = fmt.Sprintf("%s is charging %s and must use it", userPokemon.Name, userCharging.Name)
+			return errLockedIn
+		case userRecharging && (acting || userMove != nil):
+			lockErr = fmt.Sprintf("%s must recharge this turn, send neither move_id nor item_identifier", userPokemon.Name)
+			return errLockedIn
+		case userCharging == nil && !userRecharging && userMove == nil && !acting:
+			return errNoAction
+		}
+
+		// Forfeiting a trainer battle always works and counts as a loss
+		if run && kind == battleKindTrainer {
+			outcome = battleForfeited
+			return endBattle(ctx, q, user.ID, state.ID, challenger.ID, outcome)
+		}
+
+		if itemIdentifier != "" {
+			res, err := useItemTx(ctx, q, user.ID, item, itemTarget)
+			if err != nil {
+				return err
+			}
+			itemUse = &res
+		}
+
+		active, err := q.GetUserPokemonForUpdate(ctx, activePokemon.ID)
+		if err != nil {
+			return err
+		}
+		if active.CurrentHp <= 0 && (userMove != nil || run) {
+			return errActiveFainted
+		}
+
+		userSide = toBattlePokemon(userPokemon, userMoves, active.CurrentHp, active.Status)
+		userSide.Ability = active.Ability.String
+		challengerSide = toBattlePokemon(challengePokemonDetails, challengerMoves, challenger.CurrentHp, challenger.Status)
+		challengerSide.Ability = challenger.Ability.String
+		userSide.Charging, userSide.Recharging = userCharging, userRecharging
+		challengerSide.Charging = lockedMove(challengerMoves, state.ChallengerChargingMoveID)
+		challengerSide.Recharging = state.ChallengerRecharging
+		// Stat stages last until a pokemon is switched out
+		userSwitchedIn := !state.UserPokemonID.Valid || state.UserPokemonID.UUID != active.ID
+		if !userSwitchedIn {
+			userSide.Stages.Attack = int(state.UserAttackStage)
+		}
+		challengerSide.Stages.Attack = int(state.ChallengerAttackStage)
+
+		// Fleeing a wild battle depends on speed and how often the user has
+		// tried. Failing wastes the turn
+		if run {
+			fleeAttempts++
+			if battle.CanFlee(rng, userSide, challengerSide, int(fleeAttempts)) {
+				outcome = battleFled
+				return endBattle(ctx, q, user.ID, state.ID, challenger.ID, outcome)
+			}
+			fleeFailed = true
+		}
+
+		// Entry abilities like intimidate and drizzle trigger when their
+		// pokemon first comes out
+		var entry []battle.Effect
+		if state.Turn == 0 {
+			entry = append(entry, battle.EntryAbility(challengerSide, userSide)...)
+			if e := battle.EntryWeather(&field, challengerSide); e != nil {
+				entry = append(entry, *e)
+			}
+		}
+		if userSwitchedIn {
+			entry = append(entry, battle.EntryAbility(userSide, challengerSide)...)
+			if e := battle.EntryWeather(&field, userSide); e != nil {
+				entry = append(entry, *e)
+			}
+		}
+		if userSide.HeldItem, err = heldItemName(ctx, q, active.HeldItemID); err != nil {
+			return err
+		}
+		if challengerSide.HeldItem, err = heldItemName(ctx, q, challenger.HeldItemID); err != nil {
+			return err
+		}
+		var userBattleMove *battle.Move
+		if userMove != nil {
+			m := toBattleMove(*userMove)
+			userBattleMove = &m
+		}
+		// Challenger move chosen randomly
+		turn = battle.ResolveTurn(rng, &field, userSide, challengerSide, userBattleMove, battle.ChooseMove(rng, challengerSide))
+		turn.Effects = append(entry, turn.Effects...)
+
+		if err := q.UpdateBattleState(ctx, database.UpdateBattleStateParams{
+			ID:            state.ID,
+			UserPokemonID: uuid.NullUUID{UUID: active.ID, Valid: true},
+			Turn:          state.Turn + 1,
+			Weather:       sql.NullString{String: field.Weather, Valid: field.Weather != ""},
+			WeatherTurns:  int32(field.WeatherTurns),
+
+			UserChargingMoveID:       chargingMoveID(userSide),
+			UserRecharging:           userSide.Recharging && !userSide.Fainted(),
+			ChallengerChargingMoveID: chargingMoveID(challengerSide),
+			ChallengerRecharging:     challengerSide.Recharging && !challengerSide.Fainted(),
+			FleeAttempts:             fleeAttempts,
+			UserAttackStage:          int32(userSide.Stages.Attack),
+			ChallengerAttackStage:    int32(challengerSide.Stages.Attack),
+		}); err != nil {
+			return err
+		}
+
+		// Single-use held items like a focus-sash are gone once they've worked
+		if active.HeldItemID.Valid && userSide.HeldItem == "" {
+			if err := q.SetUserPokemonHeldItem(ctx, database.SetUserPokemonHeldItemParams{
+				UserID: user.ID,
+				ID:     active.ID,
+			}); err != nil {
+				return err
+			}
+		}
+		if challenger.HeldItemID.Valid && challengerSide.HeldItem == "" {
+			if err := q.ClearChallengePokemonHeldItem(ctx, challenger.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := q.SetUserPokemonHealth(ctx, database.SetUserPokemonHealthParams{
+			UserID:    user.ID,
+			ID:        active.ID,
+			CurrentHp: int32(userSide.HP),
+			Status:    battleStatus(userSide),
+		}); err != nil {
+			return err
+		}
+		if err := q.SetChallengePokemonHealth(ctx, database.SetChallengePokemonHealthParams{
+			ID:        challenger.ID,
+			CurrentHp: int32(challengerSide.HP),
+			Status:    battleStatus(challengerSide),
+		}); err != nil {
+			return err
+		}
+
+		if challengerSide.Fainted() {
+			if err := q.FinishBattle(ctx, database.FinishBattleParams{
+				ID:     state.ID,
+				Result: sql.NullString{String: battleWon, Valid: true},
+			}); err != nil {
+				return err
+			}
+			// Only trainers pay out, wild pokemon don't carry money
+			if kind == battleKindTrainer {
+				prize = battlePrize(challengePokemonDetails)
+				balance, err = adjustBalance(ctx, q, user.ID, prize, reasonBattlePrize,
+					fmt.Sprintf("defeated %s (%s)", challengePokemonDetails.Name, challenger.ID))
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if userSide.Fainted() {
+			party, err := q.GetUserPartyPokemon(ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			partyFainted = true
+			for _, p := range party {
+				if p.CurrentHp > 0 {
+					partyFainted = false
+					break
+				}
+			}
+			if partyFainted {
+				return q.FinishBattle(ctx, database.FinishBattleParams{
+					ID:     state.ID,
+					Result: sql.NullString{String: battleLost, Valid: true},
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errChallengerFainted):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "The challenger has already fainted, choose a new challenger"})
+		case errors.Is(err, errBattleOver):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "This battle is over, choose a new challenger"})
+		case errors.Is(err, errLockedIn):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": lockErr})
+		case errors.Is(err, errNoAction):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "One of move_id or item_identifier is required"})
+		case errors.Is(err, errActiveFainted):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Your active pokemon has fainted, heal it or change your active pokemon"})
+		case errors.Is(err, ErrItemNotInBag), errors.Is(err, ErrItemNotUsable), errors.Is(err, ErrItemNoEffect):
+			writeItemUseError(w, err)
+		default:
+			log.Printf("error resolving fight turn: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+		return
+	}
+
+	switch outcome {
+	case battleFled:
+		writeJSON(w, http.StatusOK, map[string]string{
+			"result":  battleFled,
+			"message": fmt.Sprintf("Got away safely from the wild %s!", challengePokemonDetails.Name),
+		})
+		return
+	case battleForfeited:
+		message := fmt.Sprintf("You forfeited the battle against %s. It counts as a loss.", challengePokemonDetails.Name)
+		if timedOut {
+			message = fmt.Sprintf("You took too long to move and forfeited the battle against %s. It counts as a loss.", challengePokemonDetails.Name)
+		}
+		writeJSON(w, http.StatusOK, map[string]string{
+			"result":  battleForfeited,
+			"message": message,
+		})
 		return
 	}
 
-	// JSON reponse for the fight description - no game logic
 	type moveDTO struct {
 		ID          int32   `json:"id"`
 		Name        string  `json:"name"`
+		DisplayName string  `json:"display_name"`
 		Type        string  `json:"type"`
 		Power       int32   `json:"power"`
 		Description *string `json:"description,omitempty"`
 	}
 
+	type fightSideDTO struct {
+		Name              string      `json:"name"`
+		DisplayName       string      `json:"display_name"`
+		MoveUsed          *moveDTO    `json:"move_used,omitempty"`
+		ItemUsed          *itemUseDTO `json:"item_used,omitempty"`
+		Ability           string      `json:"ability,omitempty"`
+		Status            string      `json:"status,omitempty"`
+		ActionDescription string      `json:"action_description"`
+		Damage            int         `json:"damage"`
+		Effectiveness     string      `json:"effectiveness,omitempty"`
+		Critical          bool        `json:"critical,omitempty"`
+		Hits              int         `json:"hits,omitempty"`
+		Charging          string      `json:"charging,omitempty"`
+		Recharging        bool        `json:"recharging,omitempty"`
+		CurrentHP         int32       `json:"current_hp"`
+		MaxHP             int32       `json:"max_hp"`
+		Fainted           bool        `json:"fainted"`
+	}
+
+	type effectDTO struct {
+		Side     string `json:"side"` // "user", "challenger" or "field"
+		Source   string `json:"source"`
+		HPChange int    `json:"hp_change"`
+		Message  string `json:"message"`
+	}
+
 	type fightDescResp struct {
-		User struct {
-			Name              string  `json:"name"`
-			MoveUsed          moveDTO `json:"move_used"`
-			ActionDescription string  `json:"action_description"`
-		} `json:"user"`
-		Challenger struct {
-			Name              string  `json:"name"`
-			MoveUsed          moveDTO `json:"move_used"`
-			ActionDescription string  `json:"action_description"`
-		} `json:"challenger"`
-	}
-
-	// helper: sql.NullString -> *string
-	descPtr := func(ns sql.NullString) *string {
-		if ns.Valid {
-			return &ns.String
+		User       fightSideDTO `json:"user"`
+		Challenger fightSideDTO `json:"challenger"`
+		Effects    []effectDTO  `json:"effects"`
+		Weather    *weatherDTO  `json:"weather"`
+		Result     string       `json:"result"` // "ongoing", "won" or "lost"
+		Kind       string       `json:"battle_type"`
+		Deadline   *time.Time   `json:"turn_deadline,omitempty"`
+		Message    string       `json:"message,omitempty"`
+		Prize      int32        `json:"prize,omitempty"`
+		Balance    *int32       `json:"balance,omitempty"`
+	}
+
+	// helper: empty string -> nil
+	descPtr := func(s string) *string {
+		if s != "" {
+			return &s
 		}
 		return nil
 	}
 
-	// Build user context for describer)
-	userAction := describe.ActionContext{}
-	userAction.Source.Name = userPokemon.Name
-	userAction.Target.Name = challengePokemonDetails.Name
-	userAction.Move.ID = userMove.MoveID
-	userAction.Move.Name = userMove.Name
-	userAction.Move.Type = userMove.Type
-	userAction.Move.Power = userMove.Power
-	if userMove.Description.Valid {
-		userAction.Move.Description = userMove.Description.String
-	}
-
-	// Build challenger context for describer
-	challengerAction := describe.ActionContext{}
-	challengerAction.Source.Name = challengePokemonDetails.Name
-	challengerAction.Target.Name = userPokemon.Name
-	challengerAction.Move.ID = challengerMove.MoveID
-	challengerAction.Move.Name = challengerMove.Name
-	challengerAction.Move.Type = challengerMove.Type
-	challengerAction.Move.Power = challengerMove.Power
-	if challengerMove.Description.Valid {
-		challengerAction.Move.Description = challengerMove.Description.String
-	}
+	loc.loadSpecies(ctx, userPokemon, challengePokemonDetails)
+	loc.loadMoves(ctx, userMoves...)
+	loc.loadMoves(ctx, challengerMoves...)
 
 	// Try AI (or Plain, depending on cfg.Describer). Always fallback to Plain.
 	descCtx, cancel := context.WithTimeout(ctx, 6*time.Second)
 	defer cancel()
 
-	userLine, uErr := cfg.Describer.DescribeAction(descCtx, userAction)
-	if uErr != nil || userLine == "" {
-		if uErr != nil {
-			log.Printf("AI user err: %v", uErr)
-		}
-		userLine, _ = (describe.Plain{}).DescribeAction(descCtx, userAction)
-	}
+	// Fill in a side's move, damage and narration from the turn's events
+	describeSide := func(side *fightSideDTO, attacker *battle.Pokemon, moves []database.Move) {
+		for _, ev := range turn.Events {
+			if ev.Attacker != attacker {
+				continue
+			}
+			for _, m := range moves {
+				if m.MoveID == ev.Move.ID {
+					side.MoveUsed = &moveDTO{
+						ID:          m.MoveID,
+						Name:        m.Name,
+						DisplayName: loc.moveName(m.MoveID, m.Name),
+						Type:        m.Type,
+						Power:       m.Power,
+						Description: descPtr(loc.moveDescription(m)),
+					}
+					break
+				}
+			}
+			side.Damage = ev.Result.Damage
+			side.Effectiveness = battle.EffectivenessLabel(ev.Result.Effectiveness)
+			side.Critical = ev.Result.Critical
+			if ev.Move.MaxHits > 1 {
+				side.Hits = ev.Result.Hits
+			}
 
-	challLine, cErr := cfg.Describer.DescribeAction(descCtx, challengerAction)
-	if cErr != nil || challLine == "" {
-		if cErr != nil {
-			log.Printf("AI chall err: %v", cErr)
+			var description string
+			if side.MoveUsed != nil && side.MoveUsed.Description != nil {
+				description = *side.MoveUsed.Description
+			}
+			side.ActionDescription = cfg.describeEvent(descCtx, loc, ev, description)
 		}
-		challLine, _ = (describe.Plain{}).DescribeAction(descCtx, challengerAction)
 	}
 
 	// ===== Build final response
@@ -1028,26 +1722,90 @@ func (cfg *Config) FightHandler(w http.ResponseWriter, r *http.Request) {
 
 	// user section
 	resp.User.Name = userPokemon.Name
-	resp.User.MoveUsed = moveDTO{
-		ID:          userMove.MoveID,
-		Name:        userMove.Name,
-		Type:        userMove.Type,
-		Power:       userMove.Power,
-		Description: descPtr(userMove.Description),
-	}
-	resp.User.ActionDescription = userLine
+	resp.User.DisplayName = loc.speciesName(userPokemon)
+	describeSide(&resp.User, userSide, userMoves)
+	if itemUse != nil {
+		used := toItemUseDTO(*itemUse)
+		resp.User.ItemUsed = &used
+		resp.User.ActionDescription = fmt.Sprintf("%s used a %s.", user.Username, itemUse.Item.Name)
+	}
+	if fleeFailed {
+		resp.User.ActionDescription = "Couldn't get away!"
+	}
+	resp.User.Ability = userSide.Ability
+	resp.User.Status = userSide.Status
+	if userSide.Charging != nil && !userSide.Fainted() {
+		resp.User.Charging = userSide.Charging.Name
+	}
+	resp.User.Recharging = userSide.Recharging && !userSide.Fainted()
+	resp.User.CurrentHP = int32(userSide.HP)
+	resp.User.MaxHP = int32(userSide.Stats.HP)
+	resp.User.Fainted = userSide.Fainted()
 
 	// challenger section
 	resp.Challenger.Name = challengePokemonDetails.Name
-	resp.Challenger.MoveUsed = moveDTO{
-		ID:          challengerMove.MoveID,
-		Name:        challengerMove.Name,
-		Type:        challengerMove.Type,
-		Power:       challengerMove.Power,
-		Description: descPtr(challengerMove.Description),
+	resp.Challenger.DisplayName = loc.speciesName(challengePokemonDetails)
+	describeSide(&resp.Challenger, challengerSide, challengerMoves)
+	resp.Challenger.Ability = challengerSide.Ability
+	resp.Challenger.Status = challengerSide.Status
+	if challengerSide.Charging != nil && !challengerSide.Fainted() {
+		resp.Challenger.Charging = challengerSide.Charging.Name
+	}
+	resp.Challenger.Recharging = challengerSide.Recharging && !challengerSide.Fainted()
+	resp.Challenger.CurrentHP = int32(challengerSide.HP)
+	resp.Challenger.MaxHP = int32(challengerSide.Stats.HP)
+	resp.Challenger.Fainted = challengerSide.Fainted()
+
+	resp.Effects = make([]effectDTO, 0, len(turn.Effects))
+	for _, e := range turn.Effects {
+		side := "challenger"
+		switch e.Pokemon {
+		case nil:
+			side = "field"
+		case userSide:
+			side = "user"
+		}
+		resp.Effects = append(resp.Effects, effectDTO{
+			Side:     side,
+			Source:   e.Source,
+			HPChange: e.HPChange,
+			Message:  e.Message,
+		})
 	}
-	resp.Challenger.ActionDescription = challLine
 
-	writeJSON(w, http.StatusOK, resp)
+	if field.Weather != "" {
+		resp.Weather = &weatherDTO{Name: field.Weather, TurnsLeft: field.WeatherTurns}
+	}
 
+	resp.Kind = kind
+	resp.Result = "ongoing"
+	switch {
+	case challengerSide.Fainted():
+		resp.Result = battleWon
+		resp.Message = fmt.Sprintf("%s fainted!", resp.Challenger.DisplayName)
+		if kind == battleKindTrainer {
+			resp.Message = fmt.Sprintf("%s fainted! You won %d.", resp.Challenger.DisplayName, prize)
+			resp.Prize = prize
+			resp.Balance = &balance
+		}
+	case partyFainted:
+		resp.Result = battleLost
+		resp.Message = "All of your party pokemon have fainted. Heal them to battle again."
+	case userSide.Fainted():
+		resp.Message = fmt.Sprintf("%s fainted! Change your active pokemon to keep fighting.", resp.User.DisplayName)
+	}
+	if resp.Result == "ongoing" && kind == battleKindTrainer && cfg.TurnTimeout > 0 {
+		deadline := time.Now().Add(cfg.TurnTimeout)
+		resp.Deadline = &deadline
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
+
+var (
+	errChallengerFainted = errors.New("challenger has fainted")
+	errActiveFainted     = errors.New("active pokemon has fainted")
+	errLockedIn          = errors.New("pokemon is locked into its move")
+	errNoAction          = errors.New("no move or item chosen")
+	errBattleOver        = errors.New("battle is over")
+)
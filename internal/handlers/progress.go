@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+// Records that a user has seen or caught a species. Progress is a side
+// effect of catching and challenging, so failing to record it is logged
+// rather than failing the request.
+func (cfg *Config) recordProgress(ctx context.Context, userID uuid.UUID, pokemonID int32, caught bool) {
+	var err error
+	if caught {
+		err = cfg.DB.MarkPokemonCaught(ctx, database.MarkPokemonCaughtParams{UserID: userID, PokemonID: pokemonID})
+	} else {
+		err = cfg.DB.MarkPokemonSeen(ctx, database.MarkPokemonSeenParams{UserID: userID, PokemonID: pokemonID})
+	}
+	if err != nil {
+		log.Printf("error recording pokedex progress: %s", err)
+	}
+}
+
+// A percentage rounded to one decimal place
+func percentOf(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*1000/float64(total)) / 10
+}
+
+// Summarises how much of the national pokedex the user has seen and caught,
+// overall, per generation and per type
+func (cfg *Config) GetPokedexProgressHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	rows, err := cfg.DB.ListPokedexProgress(ctx, user.ID)
+	if err != nil {
+		log.Printf("error listing pokedex progress: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	type generationDTO struct {
+		Generation    int     `json:"generation"`
+		Seen          int     `json:"seen"`
+		Caught        int     `json:"caught"`
+		Total         int     `json:"total"`
+		CaughtPercent float64 `json:"caught_percent"`
+	}
+	type typeDTO struct {
+		Type   string `json:"type"`
+		Seen   int    `json:"seen"`
+		Caught int    `json:"caught"`
+	}
+
+	gens := make([]generationDTO, len(generations))
+	nationalTotal := 0
+	for i, g := range generations {
+		gens[i] = generationDTO{Generation: i + 1, Total: int(g.last - g.first + 1)}
+		nationalTotal += gens[i].Total
+	}
+	var types []typeDTO
+	typeIndex := map[string]int{}
+	seen, caught := 0, 0
+	for _, row := range rows {
+		// Alternate forms aren't in the national pokedex
+		gen := generationOf(row.PokemonID)
+		if gen == 0 {
+			continue
+		}
+		seen++
+		gens[gen-1].Seen++
+		if row.Caught {
+			caught++
+			gens[gen-1].Caught++
+		}
+		speciesTypes := []string{row.Type1}
+		if row.Type2.Valid {
+			speciesTypes = append(speciesTypes, row.Type2.String)
+		}
+		for _, t := range speciesTypes {
+			i, ok := typeIndex[t]
+			if !ok {
+				i = len(types)
+				typeIndex[t] = i
+				types = append(types, typeDTO{Type: t})
+			}
+			types[i].Seen++
+			if row.Caught {
+				types[i].Caught++
+			}
+		}
+	}
+	for i := range gens {
+		gens[i].CaughtPercent = percentOf(gens[i].Caught, gens[i].Total)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Type < types[j].Type })
+	if types == nil {
+		types = []typeDTO{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"seen":           seen,
+		"caught":         caught,
+		"total":          nationalTotal,
+		"seen_percent":   percentOf(seen, nationalTotal),
+		"caught_percent": percentOf(caught, nationalTotal),
+		"generations":    gens,
+		"types":          types,
+	})
+}
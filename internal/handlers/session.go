@@ -11,12 +11,23 @@ import (
 
 	"github.com/JadedPigeon/pokemongolang/internal/database"
 	"github.com/JadedPigeon/pokemongolang/internal/describe"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
 type Config struct {
 	DB        *database.Queries
+	DBConn    *sql.DB            // Used to open transactions for multi-step writes
 	Describer describe.Describer // Optional, can be nil for plain text fallback
+	PokeAPI   pokeapi.Client     // Where uncached species, moves and items are fetched from
+
+	TurnTimeout  time.Duration // Trainer battles are forfeited after this long without a move, 0 turns the timer off. Meant for PvP, which doesn't exist yet
+	BattleExpiry time.Duration // Battles idle this long are closed by the janitor, 0 uses DefaultBattleExpiry
+	SpriteDir    string        // Where the sprite job downloads sprites to, empty uses DefaultSpriteDir
+
+	fetches singleflight.Group // Dedupes concurrent PokeAPI fetches and cache fills
+	misses  missCache          // Species PokeAPI recently didn't have
 }
 
 type Login struct {
@@ -96,11 +107,20 @@ func (cfg *Config) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := cfg.DB.CreateUser(r.Context(), database.CreateUserParams{
-		ID:           uuid.New(),
-		Username:     username,
-		PasswordHash: hashedPassword,
-	}); err != nil {
+	// New trainers start with some money, recorded in the ledger like any other balance change
+	err = cfg.withTx(r.Context(), func(q *database.Queries) error {
+		userID := uuid.New()
+		if err := q.CreateUser(r.Context(), database.CreateUserParams{
+			ID:           userID,
+			Username:     username,
+			PasswordHash: hashedPassword,
+		}); err != nil {
+			return err
+		}
+		_, err := adjustBalance(r.Context(), q, userID, startingBalance, reasonStartingBalance, "")
+		return err
+	})
+	if err != nil {
 		log.Printf("DB error: %v", err)
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Error creating user"})
 		return
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+)
+
+const (
+	// How often the sprite job looks for sprites it hasn't downloaded
+	SpriteInterval = time.Hour
+
+	// Where sprites are kept when SPRITE_DIR isn't set
+	DefaultSpriteDir = "sprites"
+
+	// Sprites never change once downloaded, clients can hold on to them
+	spriteMaxAge = 7 * 24 * time.Hour
+
+	// Anything bigger than this isn't a sprite
+	maxSpriteSize = 5 << 20
+)
+
+// Sprite variants, named the way their files are
+const (
+	spriteFront      = "front"
+	spriteBack       = "back"
+	spriteFrontShiny = "front-shiny"
+	spriteBackShiny  = "back-shiny"
+	spriteArtwork    = "artwork"
+)
+
+// Downloads sprites from PokéAPI's sprite host, which isn't PokéAPI itself
+// so doesn't go through its rate limiter
+var spriteHTTP = &http.Client{Timeout: 30 * time.Second}
+
+// A species' sprite URLs by variant, empty where PokéAPI has none
+func spriteURLs(data *pokeapi.Pokemon) map[string]string {
+	return map[string]string{
+		spriteFront:      data.Sprites.FrontDefault,
+		spriteBack:       data.Sprites.BackDefault,
+		spriteFrontShiny: data.Sprites.FrontShiny,
+		spriteBackShiny:  data.Sprites.BackShiny,
+		spriteArtwork:    data.Sprites.Other.OfficialArtwork.FrontDefault,
+	}
+}
+
+// Stores where a species' sprites are for the sprite job to download. Every
+// variant gets a row, so a species without sprites isn't asked about again.
+func storeSprites(ctx context.Context, q *database.Queries, pokemonID int32, data *pokeapi.Pokemon) error {
+	for variant, url := range spriteURLs(data) {
+		if err := q.UpsertPokemonSprite(ctx, database.UpsertPokemonSpriteParams{
+			PokemonID: pokemonID,
+			Variant:   variant,
+			Url:       nullString(url),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Where a downloaded sprite is kept
+func (cfg *Config) spritePath(pokemonID int32, variant string) string {
+	dir := cfg.SpriteDir
+	if dir == "" {
+		dir = DefaultSpriteDir
+	}
+	return filepath.Join(dir, strconv.Itoa(int(pokemonID)), variant+".png")
+}
+
+// RunSpriteJob downloads the sprites of cached species every interval until
+// ctx is cancelled. Run it in its own goroutine.
+func (cfg *Config) RunSpriteJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := cfg.syncSprites(ctx); err != nil {
+			log.Printf("error syncing sprites: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stores the sprite URLs of species cached before they were kept, then
+// downloads every sprite that isn't on disk yet. A sprite that fails to
+// download is tried again next sweep.
+func (cfg *Config) syncSprites(ctx context.Context) error {
+	ids, err := cfg.DB.ListPokedexWithoutSprites(ctx)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		data, err := cfg.PokeAPI.Pokemon(ctx, strconv.Itoa(int(id)))
+		if errors.Is(err, pokeapi.ErrNotFound) {
+			data = &pokeapi.Pokemon{}
+		} else if err != nil {
+			// Most likely PokéAPI is down, the rest can wait for next sweep
+			return fmt.Errorf("error fetching sprites for pokemon %d: %w", id, err)
+		}
+		if err := storeSprites(ctx, cfg.DB, id, data); err != nil {
+			return err
+		}
+	}
+
+	sprites, err := cfg.DB.ListPokemonSprites(ctx)
+	if err != nil {
+		return err
+	}
+	var downloaded, failed int
+	for _, s := range sprites {
+		path := cfg.spritePath(s.PokemonID, s.Variant)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := downloadSprite(ctx, s.Url.String, path); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			log.Printf("error downloading sprite %s: %s", s.Url.String, err)
+			failed++
+			continue
+		}
+		downloaded++
+	}
+	if downloaded+failed > 0 {
+		log.Printf("sprite job: %d downloaded, %d failed", downloaded, failed)
+	}
+	return nil
+}
+
+// Downloads url to path. It's written to a temporary file first, so a
+// half downloaded sprite is never served.
+func downloadSprite(ctx context.Context, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := spriteHTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".sprite-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxSpriteSize+1))
+	if err == nil && n > maxSpriteSize {
+		err = fmt.Errorf("sprite is over %d bytes", maxSpriteSize)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Serves a downloaded sprite. variant is front, back or artwork, shiny=true
+// picks the shiny front or back sprite.
+func (cfg *Config) GetSpriteHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id must be a pokedex number"})
+		return
+	}
+	query := r.URL.Query()
+	variant := strings.ToLower(query.Get("variant"))
+	if variant == "" {
+		variant = spriteFront
+	}
+	if variant != spriteFront && variant != spriteBack && variant != spriteArtwork {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "variant must be front, back or artwork"})
+		return
+	}
+	if v := query.Get("shiny"); v != "" {
+		shiny, err := strconv.ParseBool(v)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "shiny must be true or false"})
+			return
+		}
+		if shiny && variant == spriteArtwork {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "artwork has no shiny variant"})
+			return
+		}
+		if shiny {
+			variant += "-shiny"
+		}
+	}
+
+	f, err := os.Open(cfg.spritePath(int32(id), variant))
+	if errors.Is(err, os.ErrNotExist) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Sprite not downloaded yet"})
+		return
+	} else if err != nil {
+		log.Printf("error opening sprite: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		log.Printf("error reading sprite: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	// A sprite only changes if its file is deleted and downloaded again,
+	// which changes its modification time. ServeContent answers a matching
+	// If-None-Match with a 304.
+	w.Header().Set("ETag", fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()))
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(spriteMaxAge.Seconds())))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
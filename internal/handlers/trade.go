@@ -0,0 +1,583 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTradePageSize = 20
+	maxTradePageSize     = 100
+)
+
+var (
+	ErrTradeNotFound        = errors.New("trade not found")
+	ErrTradeNotPending      = errors.New("trade has already been resolved")
+	ErrTradeForbidden       = errors.New("you can't do that with this trade")
+	ErrTradePokemonNotOwned = errors.New("both pokemon must belong to the traders")
+)
+
+type tradePokemonDTO struct {
+	UserPokemonID string `json:"user_pokemon_id"`
+	PokemonID     int32  `json:"pokemon_id"`
+	Name          string `json:"name"`
+	DisplayName   string `json:"display_name"`
+}
+
+type tradeDTO struct {
+	ID           string          `json:"id"`
+	Status       string          `json:"status"`
+	Direction    string          `json:"direction"` // "incoming" or "outgoing" from the caller's point of view
+	FromUsername string          `json:"from_username"`
+	ToUsername   string          `json:"to_username"`
+	Offered      tradePokemonDTO `json:"offered"`
+	Requested    tradePokemonDTO `json:"requested"`
+	CounterOf    *string         `json:"counter_of,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ResolvedAt   *time.Time      `json:"resolved_at,omitempty"`
+}
+
+// The species changing hands in some trades, for loading their names
+func tradedSpecies(trades []database.ListPendingTradesForUserRow) []database.Pokedex {
+	species := make([]database.Pokedex, 0, 2*len(trades))
+	for _, t := range trades {
+		species = append(species,
+			database.Pokedex{ID: t.OfferedSpeciesID, Name: t.OfferedSpeciesName},
+			database.Pokedex{ID: t.RequestedSpeciesID, Name: t.RequestedSpeciesName},
+		)
+	}
+	return species
+}
+
+// The trade's species must be loaded into loc
+func toTradeDTO(t database.ListPendingTradesForUserRow, userID uuid.UUID, loc *localizer) tradeDTO {
+	dto := tradeDTO{
+		ID:           t.ID.String(),
+		Status:       t.Status,
+		Direction:    "outgoing",
+		FromUsername: t.FromUsername,
+		ToUsername:   t.ToUsername,
+		Offered: tradePokemonDTO{
+			UserPokemonID: t.OfferedPokemonID.String(),
+			PokemonID:     t.OfferedSpeciesID,
+			Name:          t.OfferedSpeciesName,
+			DisplayName:   loc.nameOf(t.OfferedSpeciesName),
+		},
+		Requested: tradePokemonDTO{
+			UserPokemonID: t.RequestedPokemonID.String(),
+			PokemonID:     t.RequestedSpeciesID,
+			Name:          t.RequestedSpeciesName,
+			DisplayName:   loc.nameOf(t.RequestedSpeciesName),
+		},
+		CreatedAt: t.CreatedAt,
+	}
+	if t.ToUserID == userID {
+		dto.Direction = "incoming"
+	}
+	if t.CounterOf.Valid {
+		counterOf := t.CounterOf.UUID.String()
+		dto.CounterOf = &counterOf
+	}
+	if t.ResolvedAt.Valid {
+		dto.ResolvedAt = &t.ResolvedAt.Time
+	}
+	return dto
+}
+
+// Checks offered belongs to fromUserID and requested belongs to toUserID
+func findTradePokemon(ctx context.Context, q *database.Queries, fromUserID, toUserID, offeredID, requestedID uuid.UUID) (offered, requested database.UserPokemon, err error) {
+	offered, err = q.GetUserPokemonByID(ctx, database.GetUserPokemonByIDParams{UserID: fromUserID, ID: offeredID})
+	if err == sql.ErrNoRows {
+		return offered, requested, ErrTradePokemonNotOwned
+	} else if err != nil {
+		return offered, requested, err
+	}
+	requested, err = q.GetUserPokemonByID(ctx, database.GetUserPokemonByIDParams{UserID: toUserID, ID: requestedID})
+	if err == sql.ErrNoRows {
+		return offered, requested, ErrTradePokemonNotOwned
+	}
+	return offered, requested, err
+}
+
+// Reads offered_pokemon_id and requested_pokemon_id from the form
+func parseTradePokemonIDs(r *http.Request) (offeredID, requestedID uuid.UUID, err error) {
+	offeredID, err = uuid.Parse(r.PostForm.Get("offered_pokemon_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("offered_pokemon_id must be a valid UUID")
+	}
+	requestedID, err = uuid.Parse(r.PostForm.Get("requested_pokemon_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("requested_pokemon_id must be a valid UUID")
+	}
+	return offeredID, requestedID, nil
+}
+
+// Swaps ownership of the two pokemon in a trade. Each pokemon takes the party slot (or box spot)
+// and active flag of the one it was traded for, so neither user's party or box can overflow.
+func swapTradePokemon(ctx context.Context, q *database.Queries, trade database.Trade) (stale bool, err error) {
+	// Lock in a consistent order so two trades touching the same pokemon can't deadlock
+	firstID, secondID := trade.OfferedPokemonID, trade.RequestedPokemonID
+	if secondID.String() < firstID.String() {
+		firstID, secondID = secondID, firstID
+	}
+	locked := make(map[uuid.UUID]database.UserPokemon, 2)
+	for _, id := range []uuid.UUID{firstID, secondID} {
+		p, err := q.GetUserPokemonForUpdate(ctx, id)
+		if err == sql.ErrNoRows {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		locked[id] = p
+	}
+	offered, requested := locked[trade.OfferedPokemonID], locked[trade.RequestedPokemonID]
+
+	// Released or traded away since the offer was made
+	if offered.UserID != trade.FromUserID || requested.UserID != trade.ToUserID {
+		return true, nil
+	}
+
+	if err := q.TransferUserPokemon(ctx, database.TransferUserPokemonParams{
+		ID:        offered.ID,
+		UserID:    trade.ToUserID,
+		InBox:     requested.InBox,
+		PartySlot: requested.PartySlot,
+	}); err != nil {
+		return false, err
+	}
+	if err := q.TransferUserPokemon(ctx, database.TransferUserPokemonParams{
+		ID:        requested.ID,
+		UserID:    trade.FromUserID,
+		InBox:     offered.InBox,
+		PartySlot: offered.PartySlot,
+	}); err != nil {
+		return false, err
+	}
+
+	if offered.IsActive {
+		if _, err := q.ActivateUserPokemon(ctx, database.ActivateUserPokemonParams{UserID: trade.FromUserID, ID: requested.ID}); err != nil {
+			return false, err
+		}
+	}
+	if requested.IsActive {
+		if _, err := q.ActivateUserPokemon(ctx, database.ActivateUserPokemonParams{UserID: trade.ToUserID, ID: offered.ID}); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// List another user's pokemon so a trade can be offered for one of them
+func (cfg *Config) GetTradeablePokemonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "username is required"})
+		return
+	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	trainer, err := cfg.DB.GetUserByUsername(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+			return
+		}
+		log.Printf("error getting user by username: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	pokemonList, err := cfg.DB.ListUserPokemonForTrade(ctx, trainer.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve Pokémon"})
+		return
+	}
+	species := make([]database.Pokedex, 0, len(pokemonList))
+	for _, p := range pokemonList {
+		species = append(species, database.Pokedex{ID: p.PokemonID, Name: p.Name})
+	}
+	loc.loadSpecies(ctx, species...)
+
+	type tradeablePokemonDTO struct {
+		UserPokemonID string   `json:"user_pokemon_id"`
+		PokemonID     int32    `json:"pokemon_id"`
+		Name          string   `json:"name"`
+		DisplayName   string   `json:"display_name"`
+		Nickname      string   `json:"nickname,omitempty"`
+		Types         []string `json:"types"`
+		InBox         bool     `json:"in_box"`
+		ImageURL      string   `json:"image_url,omitempty"`
+	}
+
+	response := make([]tradeablePokemonDTO, 0, len(pokemonList))
+	for _, p := range pokemonList {
+		types := []string{p.Type1}
+		if p.Type2.Valid {
+			types = append(types, p.Type2.String)
+		}
+		response = append(response, tradeablePokemonDTO{
+			UserPokemonID: p.ID.String(),
+			PokemonID:     p.PokemonID,
+			Name:          p.Name,
+			DisplayName:   loc.nameOf(p.Name),
+			Nickname:      p.Nickname.String,
+			Types:         types,
+			InBox:         p.InBox,
+			ImageURL:      p.ImageUrl.String,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"username": trainer.Username,
+		"pokemon":  response,
+	})
+}
+
+// Offer one of your pokemon for one of another user's
+func (cfg *Config) OfferTradeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	toUsername := r.PostForm.Get("to_username")
+	if toUsername == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "to_username is required"})
+		return
+	}
+	offeredID, requestedID, err := parseTradePokemonIDs(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	toUser, err := cfg.DB.GetUserByUsername(ctx, toUsername)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "User not found"})
+			return
+		}
+		log.Printf("error getting user by username: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+	if toUser.ID == user.ID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "You can't trade with yourself"})
+		return
+	}
+
+	offered, requested, err := findTradePokemon(ctx, cfg.DB, user.ID, toUser.ID, offeredID, requestedID)
+	if err != nil {
+		if err == ErrTradePokemonNotOwned {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "offered_pokemon_id must be yours and requested_pokemon_id must belong to to_username"})
+			return
+		}
+		log.Printf("error getting trade pokemon: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	tradeID := uuid.New()
+	if err := cfg.DB.CreateTrade(ctx, database.CreateTradeParams{
+		ID:                 tradeID,
+		FromUserID:         user.ID,
+		ToUserID:           toUser.ID,
+		OfferedPokemonID:   offered.ID,
+		RequestedPokemonID: requested.ID,
+		OfferedSpeciesID:   offered.PokemonID.Int32,
+		RequestedSpeciesID: requested.PokemonID.Int32,
+	}); err != nil {
+		log.Printf("error creating trade: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":       "Trade offered successfully",
+		"trade_id":      tradeID.String(),
+		"to_username":   toUser.Username,
+		"user_username": user.Username,
+	})
+}
+
+// Accept, reject or counter a trade offered to you, or cancel one you offered.
+// Accepting swaps ownership of both pokemon in a single transaction.
+func (cfg *Config) RespondTradeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad form data"})
+		return
+	}
+
+	tradeID, err := uuid.Parse(r.PostForm.Get("trade_id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "trade_id must be a valid UUID"})
+		return
+	}
+
+	action := r.PostForm.Get("action")
+	var counterOfferedID, counterRequestedID uuid.UUID
+	switch action {
+	case "accept", "reject", "cancel":
+	case "counter":
+		// The counter offer comes from the user responding, so offered is theirs and requested is the original offerer's
+		counterOfferedID, counterRequestedID, err = parseTradePokemonIDs(r)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "action must be one of accept, reject, counter or cancel"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	var (
+		stale        bool
+		accepted     *database.Trade
+		counterTrade uuid.UUID
+	)
+	err = cfg.withTx(ctx, func(q *database.Queries) error {
+		trade, err := q.GetTradeForUpdate(ctx, tradeID)
+		if err == sql.ErrNoRows || (err == nil && trade.FromUserID != user.ID && trade.ToUserID != user.ID) {
+			return ErrTradeNotFound
+		} else if err != nil {
+			return err
+		}
+		if trade.Status != "pending" {
+			return ErrTradeNotPending
+		}
+
+		// Only the offerer can cancel, only the recipient can do anything else
+		if (action == "cancel") != (trade.FromUserID == user.ID) {
+			return ErrTradeForbidden
+		}
+
+		switch action {
+		case "accept":
+			stale, err = swapTradePokemon(ctx, q, trade)
+			if err != nil {
+				return err
+			}
+			if stale {
+				// Still commit so the dead offer doesn't linger
+				return q.SetTradeStatus(ctx, database.SetTradeStatusParams{ID: trade.ID, Status: "cancelled"})
+			}
+			if err := q.SetTradeStatus(ctx, database.SetTradeStatusParams{ID: trade.ID, Status: "accepted"}); err != nil {
+				return err
+			}
+			accepted = &trade
+			return q.CancelPendingTradesForPokemon(ctx, database.CancelPendingTradesForPokemonParams{
+				PokemonA: trade.OfferedPokemonID,
+				PokemonB: trade.RequestedPokemonID,
+			})
+		case "reject":
+			return q.SetTradeStatus(ctx, database.SetTradeStatusParams{ID: trade.ID, Status: "rejected"})
+		case "cancel":
+			return q.SetTradeStatus(ctx, database.SetTradeStatusParams{ID: trade.ID, Status: "cancelled"})
+		default: // counter
+			offered, requested, err := findTradePokemon(ctx, q, user.ID, trade.FromUserID, counterOfferedID, counterRequestedID)
+			if err != nil {
+				return err
+			}
+			if err := q.SetTradeStatus(ctx, database.SetTradeStatusParams{ID: trade.ID, Status: "countered"}); err != nil {
+				return err
+			}
+			counterTrade = uuid.New()
+			return q.CreateTrade(ctx, database.CreateTradeParams{
+				ID:                 counterTrade,
+				FromUserID:         user.ID,
+				ToUserID:           trade.FromUserID,
+				OfferedPokemonID:   offered.ID,
+				RequestedPokemonID: requested.ID,
+				OfferedSpeciesID:   offered.PokemonID.Int32,
+				RequestedSpeciesID: requested.PokemonID.Int32,
+				CounterOf:          uuid.NullUUID{UUID: trade.ID, Valid: true},
+			})
+		}
+	})
+	if err != nil {
+		switch err {
+		case ErrTradeNotFound:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Trade not found"})
+		case ErrTradeNotPending:
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "Trade has already been resolved"})
+		case ErrTradeForbidden:
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "Only the recipient can accept, reject or counter a trade and only the offerer can cancel it"})
+		case ErrTradePokemonNotOwned:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "offered_pokemon_id must be yours and requested_pokemon_id must belong to the original offerer"})
+		default:
+			log.Printf("error responding to trade: %s", err)
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+		return
+	}
+	if stale {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "One of the pokemon in this trade is no longer available, the trade has been cancelled"})
+		return
+	}
+	if accepted != nil {
+		// Both species count as caught for whoever receives them
+		cfg.recordProgress(ctx, accepted.ToUserID, accepted.OfferedSpeciesID, true)
+		cfg.recordProgress(ctx, accepted.FromUserID, accepted.RequestedSpeciesID, true)
+	}
+
+	response := map[string]string{
+		"trade_id":      tradeID.String(),
+		"user_username": user.Username,
+	}
+	switch action {
+	case "accept":
+		response["message"] = "Trade completed successfully"
+	case "reject":
+		response["message"] = "Trade rejected"
+	case "cancel":
+		response["message"] = "Trade cancelled"
+	case "counter":
+		response["message"] = "Counter offer sent successfully"
+		response["counter_trade_id"] = counterTrade.String()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// List pending trades the user has offered or received
+func (cfg *Config) GetTradeOffersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	trades, err := cfg.DB.ListPendingTradesForUser(ctx, user.ID)
+	if err != nil {
+		log.Printf("error listing pending trades: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve trades"})
+		return
+	}
+
+	loc.loadSpecies(ctx, tradedSpecies(trades)...)
+	response := make([]tradeDTO, 0, len(trades))
+	for _, t := range trades {
+		response = append(response, toTradeDTO(t, user.ID, loc))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// List the user's resolved trades, most recent first
+func (cfg *Config) GetTradeHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "Invalid method"})
+		return
+	}
+
+	page, pageSize, err := parsePagination(r, defaultTradePageSize, maxTradePageSize)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	loc, ok := cfg.requestLocalizer(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	user, ok := ctx.Value(userContextKey).(*database.User)
+	if !ok || user == nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Unauthorized"})
+		return
+	}
+
+	total, err := cfg.DB.CountTradeHistoryForUser(ctx, user.ID)
+	if err != nil {
+		log.Printf("error counting trade history: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve trades"})
+		return
+	}
+
+	trades, err := cfg.DB.ListTradeHistoryForUser(ctx, database.ListTradeHistoryForUserParams{
+		UserID:    user.ID,
+		RowLimit:  int32(pageSize),
+		RowOffset: int32((page - 1) * pageSize),
+	})
+	if err != nil {
+		log.Printf("error listing trade history: %s", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Failed to retrieve trades"})
+		return
+	}
+
+	rows := make([]database.ListPendingTradesForUserRow, 0, len(trades))
+	for _, t := range trades {
+		rows = append(rows, database.ListPendingTradesForUserRow(t))
+	}
+	loc.loadSpecies(ctx, tradedSpecies(rows)...)
+
+	response := struct {
+		Page     int        `json:"page"`
+		PageSize int        `json:"page_size"`
+		Total    int64      `json:"total"`
+		Trades   []tradeDTO `json:"trades"`
+	}{
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+		Trades:   make([]tradeDTO, 0, len(trades)),
+	}
+	for _, t := range rows {
+		response.Trades = append(response.Trades, toTradeDTO(t, user.ID, loc))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
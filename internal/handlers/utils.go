@@ -5,14 +5,23 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/singleflight"
 )
 
+// Uncached moves are fetched from PokeAPI this many at a time
+const moveFetchWorkers = 4
+
 func hashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 10)
 	return string(bytes), err
@@ -37,20 +46,86 @@ func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 	json.NewEncoder(w).Encode(payload)
 }
 
-var httpc = &http.Client{Timeout: 10 * time.Second}
+// Responds to a failed species or item lookup. An unknown species is a 404
+// with suggestions, PokéAPI being down or rate limiting us is a 503 saying
+// when to try again, a species missing its abilities a 503 until cmd/seed
+// backfills them, anything else a 500.
+func writeLookupError(w http.ResponseWriter, err error) {
+	var notFound *SpeciesNotFoundError
+	if errors.As(err, &notFound) {
+		msg := fmt.Sprintf("No Pokémon called %q", notFound.Identifier)
+		if len(notFound.Suggestions) > 0 {
+			msg += ", did you mean " + strings.Join(notFound.Suggestions, " or ") + "?"
+		}
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error":       msg,
+			"suggestions": append([]string{}, notFound.Suggestions...),
+		})
+		return
+	}
+	var unavailable *pokeapi.UnavailableError
+	if errors.As(err, &unavailable) {
+		secs := int(math.Ceil(unavailable.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(secs, 1)))
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "PokéAPI is unavailable, try again shortly"})
+		return
+	}
+	if errors.Is(err, ErrAbilitiesNotCached) {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "That Pokémon's abilities haven't been loaded yet"})
+		return
+	}
+	writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+}
+
+// Reads page and page_size from the query string, falling back to defaultSize
+// and capping page_size at maxSize. Pages starting past what an int32 OFFSET
+// can hold are rejected.
+func parsePagination(r *http.Request, defaultSize, maxSize int) (page, pageSize int, err error) {
+	page, pageSize = 1, defaultSize
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, errors.New("page must be a positive integer")
+		}
+	}
+	if v := r.URL.Query().Get("page_size"); v != "" {
+		pageSize, err = strconv.Atoi(v)
+		if err != nil || pageSize < 1 {
+			return 0, 0, errors.New("page_size must be a positive integer")
+		}
+	}
+	if pageSize > maxSize {
+		pageSize = maxSize
+	}
+	if page-1 > math.MaxInt32/pageSize {
+		return 0, 0, errors.New("page is too large")
+	}
+	return page, pageSize, nil
+}
 
-func getJSON[T any](ctx context.Context, url string, dst *T) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// Runs fn inside a transaction, committing if it returns nil and rolling back otherwise
+func (cfg *Config) withTx(ctx context.Context, fn func(q *database.Queries) error) error {
+	tx, err := cfg.DBConn.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	resp, err := httpc.Do(req)
-	if err != nil {
+	defer tx.Rollback()
+
+	if err := fn(cfg.DB.WithTx(tx)); err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	return tx.Commit()
+}
+
+// Runs fn once for concurrent callers with the same key, sharing its result.
+// fn outlives any one caller's cancellation, since others may be waiting on it.
+func shared[T any](ctx context.Context, g *singleflight.Group, key string, fn func(context.Context) (T, error)) (T, error) {
+	v, err, _ := g.Do(key, func() (any, error) {
+		return fn(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		var zero T
+		return zero, err
 	}
-	return json.NewDecoder(resp.Body).Decode(dst)
+	return v.(T), nil
 }
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query      string
+		page, size int
+		wantErr    bool
+	}{
+		{query: "", page: 1, size: 20},
+		{query: "page=3&page_size=50", page: 3, size: 50},
+		{query: "page_size=500", page: 1, size: 100},
+		{query: "page=0", wantErr: true},
+		{query: "page=abc", wantErr: true},
+		{query: "page_size=-1", wantErr: true},
+		// The offset would overflow an int32
+		{query: "page=9223372036854775807", wantErr: true},
+		{query: "page=21474838&page_size=100", wantErr: true},
+		{query: "page=21474837&page_size=1", page: 21474837, size: 1},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/?"+tt.query, nil)
+		page, size, err := parsePagination(r, 20, 100)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parsePagination(%q) = %d, %d, want an error", tt.query, page, size)
+			}
+			continue
+		}
+		if err != nil || page != tt.page || size != tt.size {
+			t.Errorf("parsePagination(%q) = %d, %d, %v, want %d, %d", tt.query, page, size, err, tt.page, tt.size)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Dir is a Client reading a local PokéAPI mirror laid out like the
+// PokeAPI/api-data repository's data/api/v2 directory, with
+// <resource>/<id>/index.json for each resource and <resource>/index.json
+// listing them. Names are looked up through the listing.
+type Dir struct {
+	Root string
+
+	mu    sync.Mutex
+	names map[string]map[string]int // resource, then name to ID
+}
+
+var _ Client = (*Dir)(nil)
+
+func NewDir(root string) *Dir {
+	return &Dir{Root: root, names: map[string]map[string]int{}}
+}
+
+func (d *Dir) Pokemon(ctx context.Context, identifier string) (*Pokemon, error) {
+	var p Pokemon
+	if err := d.read("pokemon", identifier, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (d *Dir) Species(ctx context.Context, identifier string) (*Species, error) {
+	var s Species
+	if err := d.read("pokemon-species", identifier, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (d *Dir) Move(ctx context.Context, identifier string) (*Move, error) {
+	var m Move
+	if err := d.read("move", identifier, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (d *Dir) EvolutionChain(ctx context.Context, id int) (*EvolutionChain, error) {
+	var e EvolutionChain
+	if err := d.read("evolution-chain", strconv.Itoa(id), &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (d *Dir) Item(ctx context.Context, identifier string) (*Item, error) {
+	var i Item
+	if err := d.read("item", identifier, &i); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// IDs lists the IDs the mirror has for a resource, in order
+func (d *Dir) IDs(resource string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(d.Root, resource))
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for _, e := range entries {
+		if id, err := strconv.Atoi(e.Name()); err == nil && e.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// Reads <resource>/<id>/index.json into dst
+func (d *Dir) read(resource, identifier string, dst any) error {
+	identifier = strings.ToLower(identifier)
+	if _, err := strconv.Atoi(identifier); err != nil {
+		id, err := d.lookup(resource, identifier)
+		if err != nil {
+			return err
+		}
+		identifier = strconv.Itoa(id)
+	}
+	file := filepath.Join(d.Root, resource, identifier, "index.json")
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: %w", file, ErrNotFound)
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return fmt.Errorf("error parsing %s: %w", file, err)
+	}
+	return nil
+}
+
+// Finds a name's ID in <resource>/index.json, read once per resource
+func (d *Dir) lookup(resource, name string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	names, ok := d.names[resource]
+	if !ok {
+		file := filepath.Join(d.Root, resource, "index.json")
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return 0, err
+		}
+		var list struct {
+			Results []NamedResource `json:"results"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return 0, fmt.Errorf("error parsing %s: %w", file, err)
+		}
+		names = map[string]int{}
+		for _, r := range list.Results {
+			if id, ok := r.ID(); ok {
+				names[r.Name] = id
+			}
+		}
+		d.names[resource] = names
+	}
+	id, ok := names[name]
+	if !ok {
+		return 0, fmt.Errorf("%s %s: %w", resource, name, ErrNotFound)
+	}
+	return id, nil
+}
@@ -0,0 +1,174 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "https://pokeapi.co/api/v2"
+
+// Defaults for New
+const (
+	DefaultRate             = 10 // Requests a second
+	DefaultRetries          = 3
+	DefaultBreakerThreshold = 5 // Failed attempts in a row
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// HTTPClient is a Client for PokéAPI or anything serving the same paths, like
+// a mirror or a pokeapitest server. Requests that get a 429, a 5xx or no
+// response are retried with backoff, waiting at least as long as any
+// Retry-After asks.
+type HTTPClient struct {
+	BaseURL string
+	HTTP    *http.Client
+
+	Limiter   *Limiter      // Optional, nil sends requests as fast as they come
+	Breaker   *Breaker      // Optional, nil never fails fast
+	Retries   int           // Attempts after the first
+	BaseDelay time.Duration // First backoff, doubling each retry
+	MaxDelay  time.Duration // Longest wait between attempts, a longer Retry-After gives up
+}
+
+// New makes a client for the API at baseURL, DefaultBaseURL when empty, with
+// the default rate limit, retries and breaker
+func New(baseURL string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &HTTPClient{
+		BaseURL:   strings.TrimRight(baseURL, "/"),
+		HTTP:      &http.Client{Timeout: 10 * time.Second},
+		Limiter:   NewLimiter(DefaultRate, DefaultRate),
+		Breaker:   NewBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+		Retries:   DefaultRetries,
+		BaseDelay: 250 * time.Millisecond,
+		MaxDelay:  5 * time.Second,
+	}
+}
+
+func (c *HTTPClient) Pokemon(ctx context.Context, identifier string) (*Pokemon, error) {
+	var p Pokemon
+	if err := c.get(ctx, "pokemon", identifier, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (c *HTTPClient) Species(ctx context.Context, identifier string) (*Species, error) {
+	var s Species
+	if err := c.get(ctx, "pokemon-species", identifier, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (c *HTTPClient) Move(ctx context.Context, identifier string) (*Move, error) {
+	var m Move
+	if err := c.get(ctx, "move", identifier, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (c *HTTPClient) EvolutionChain(ctx context.Context, id int) (*EvolutionChain, error) {
+	var e EvolutionChain
+	if err := c.get(ctx, "evolution-chain", strconv.Itoa(id), &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (c *HTTPClient) Item(ctx context.Context, identifier string) (*Item, error) {
+	var i Item
+	if err := c.get(ctx, "item", identifier, &i); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// Fetches /{resource}/{identifier}/ into dst, retrying what's worth retrying
+func (c *HTTPClient) get(ctx context.Context, resource, identifier string, dst any) error {
+	u := fmt.Sprintf("%s/%s/%s/", c.BaseURL, resource, url.PathEscape(strings.ToLower(identifier)))
+	for attempt := 0; ; attempt++ {
+		if c.Breaker != nil {
+			if ok, wait := c.Breaker.Allow(); !ok {
+				Metrics.Add(metricBreakerOpen, 1)
+				return &UnavailableError{RetryAfter: wait}
+			}
+		}
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		if attempt > 0 {
+			Metrics.Add(metricRetries, 1)
+		}
+		Metrics.Add(metricRequests, 1)
+
+		wait, err := c.do(ctx, u, dst)
+		if wait < 0 {
+			// Done, one way or another
+			if c.Breaker != nil {
+				c.Breaker.Success()
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			// Our caller gave up, that says nothing about PokéAPI
+			return err
+		}
+		if c.Breaker != nil {
+			c.Breaker.Failure()
+		}
+		if wait == 0 {
+			wait = backoff(attempt, c.BaseDelay, c.MaxDelay)
+		}
+		if attempt >= c.Retries || wait > c.MaxDelay {
+			Metrics.Add(metricUnavailable, 1)
+			return &UnavailableError{RetryAfter: wait, Err: err}
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// Sends one GET. A retryable failure returns how long the server asked us to
+// wait, 0 if it didn't say, anything else returns -1.
+func (c *HTTPClient) do(ctx context.Context, u string, dst any) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return -1, err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		Metrics.Add(metricNetworkErrors, 1)
+		return 0, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		Metrics.Add(metricOK, 1)
+		return -1, json.NewDecoder(resp.Body).Decode(dst)
+	case resp.StatusCode == http.StatusNotFound:
+		Metrics.Add(metricNotFound, 1)
+		return -1, fmt.Errorf("GET %s: %w", u, ErrNotFound)
+	case !retryable(resp.StatusCode):
+		return -1, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		Metrics.Add(metricRateLimited, 1)
+	} else {
+		Metrics.Add(metricServerErrors, 1)
+	}
+	wait, _ := retryAfter(resp.Header)
+	return wait, fmt.Errorf("GET %s: %s", u, resp.Status)
+}
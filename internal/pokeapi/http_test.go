@@ -0,0 +1,58 @@
+package pokeapi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi/pokeapitest"
+)
+
+func newFixtureClient(t *testing.T) *pokeapi.HTTPClient {
+	t.Helper()
+	srv, err := pokeapitest.NewServer(pokeapitest.Fixtures, "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	return pokeapi.New(srv.URL)
+}
+
+func TestHTTPClientPokemon(t *testing.T) {
+	c := newFixtureClient(t)
+	for _, identifier := range []string{"pikachu", "Pikachu", "25"} {
+		p, err := c.Pokemon(context.Background(), identifier)
+		if err != nil {
+			t.Fatalf("Pokemon(%q): %v", identifier, err)
+		}
+		if p.ID != 25 || p.Name != "pikachu" {
+			t.Errorf("Pokemon(%q) = #%d %s, want #25 pikachu", identifier, p.ID, p.Name)
+		}
+	}
+}
+
+func TestHTTPClientMove(t *testing.T) {
+	c := newFixtureClient(t)
+	m, err := c.Move(context.Background(), "thunderbolt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "thunderbolt" || m.Type.Name != "electric" || m.Power == nil || *m.Power != 90 {
+		t.Errorf("Move(thunderbolt) = %+v", m)
+	}
+}
+
+func TestHTTPClientNotFound(t *testing.T) {
+	c := newFixtureClient(t)
+	if _, err := c.Pokemon(context.Background(), "missingno"); !errors.Is(err, pokeapi.ErrNotFound) {
+		t.Errorf("Pokemon(missingno) error = %v, want ErrNotFound", err)
+	}
+	if _, err := c.Move(context.Background(), "not-a-move"); !errors.Is(err, pokeapi.ErrNotFound) {
+		t.Errorf("Move(not-a-move) error = %v, want ErrNotFound", err)
+	}
+	// A 404 is an answer, it mustn't count towards opening the breaker
+	if state := c.Breaker.State(); state != "closed" {
+		t.Errorf("breaker is %s after 404s, want closed", state)
+	}
+}
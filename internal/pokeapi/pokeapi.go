@@ -0,0 +1,150 @@
+// Package pokeapi fetches species, moves, evolution chains and items from
+// PokéAPI. Handlers use the Client interface, so tests can swap the live API
+// for a Fake or a stand-in server from pokeapitest.
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when PokéAPI has nothing by that name or ID
+var ErrNotFound = errors.New("not found on PokéAPI")
+
+type Client interface {
+	Pokemon(ctx context.Context, identifier string) (*Pokemon, error)
+	Species(ctx context.Context, identifier string) (*Species, error)
+	Move(ctx context.Context, identifier string) (*Move, error)
+	EvolutionChain(ctx context.Context, id int) (*EvolutionChain, error)
+	Item(ctx context.Context, identifier string) (*Item, error)
+}
+
+// NamedResource is PokéAPI's link to another resource
+type NamedResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ID is the ID at the end of the resource's URL
+func (r NamedResource) ID() (int, bool) {
+	return ResourceID(r.URL)
+}
+
+// ResourceID parses the ID at the end of a PokéAPI URL like
+// https://pokeapi.co/api/v2/move/85/
+func ResourceID(url string) (int, bool) {
+	parts := strings.Split(strings.Trim(url, "/"), "/")
+	id, err := strconv.Atoi(parts[len(parts)-1])
+	return id, err == nil
+}
+
+type FlavorText struct {
+	FlavorText   string        `json:"flavor_text"`
+	Language     NamedResource `json:"language"`
+	Version      NamedResource `json:"version"`       // on species
+	VersionGroup NamedResource `json:"version_group"` // on moves
+}
+
+type Name struct {
+	Name     string        `json:"name"`
+	Language NamedResource `json:"language"`
+}
+
+// Pokemon is /pokemon/{id or name}, the battle data of a species' default form
+type Pokemon struct {
+	ID      int           `json:"id"`
+	Name    string        `json:"name"`
+	Species NamedResource `json:"species"`
+	Types   []struct {
+		Slot int           `json:"slot"`
+		Type NamedResource `json:"type"`
+	} `json:"types"`
+	Stats []struct {
+		BaseStat int           `json:"base_stat"`
+		Stat     NamedResource `json:"stat"`
+	} `json:"stats"`
+	Moves []struct {
+		Move NamedResource `json:"move"`
+	} `json:"moves"`
+	Abilities []struct {
+		Ability  NamedResource `json:"ability"`
+		IsHidden bool          `json:"is_hidden"`
+		Slot     int           `json:"slot"`
+	} `json:"abilities"`
+	Sprites struct {
+		FrontDefault string `json:"front_default"`
+		BackDefault  string `json:"back_default"`
+		FrontShiny   string `json:"front_shiny"`
+		BackShiny    string `json:"back_shiny"`
+		Other        struct {
+			OfficialArtwork struct {
+				FrontDefault string `json:"front_default"`
+			} `json:"official-artwork"`
+		} `json:"other"`
+	} `json:"sprites"`
+}
+
+// Species is /pokemon-species/{id or name}, what's shared by all of a
+// species' forms
+type Species struct {
+	ID             int           `json:"id"`
+	Name           string        `json:"name"`
+	Generation     NamedResource `json:"generation"`
+	EvolutionChain struct {
+		URL string `json:"url"`
+	} `json:"evolution_chain"`
+	EvolvesFrom       *NamedResource `json:"evolves_from_species"`
+	Names             []Name         `json:"names"`
+	FlavorTextEntries []FlavorText   `json:"flavor_text_entries"`
+}
+
+// Move is /move/{id or name}
+type Move struct {
+	ID       int           `json:"id"`
+	Name     string        `json:"name"`
+	Power    *int          `json:"power"`
+	Priority int           `json:"priority"`
+	Target   NamedResource `json:"target"`
+	Meta     *struct {
+		MinHits *int `json:"min_hits"`
+		MaxHits *int `json:"max_hits"`
+	} `json:"meta"`
+	DamageClass       NamedResource `json:"damage_class"`
+	Type              NamedResource `json:"type"`
+	Names             []Name        `json:"names"`
+	FlavorTextEntries []FlavorText  `json:"flavor_text_entries"`
+}
+
+// EvolutionChain is /evolution-chain/{id}
+type EvolutionChain struct {
+	ID    int       `json:"id"`
+	Chain ChainLink `json:"chain"`
+}
+
+// ChainLink is one species in an evolution chain and what it evolves into
+type ChainLink struct {
+	Species          NamedResource `json:"species"`
+	EvolutionDetails []struct {
+		Trigger  NamedResource  `json:"trigger"`
+		MinLevel *int           `json:"min_level"`
+		Item     *NamedResource `json:"item"`
+	} `json:"evolution_details"`
+	EvolvesTo []ChainLink `json:"evolves_to"`
+}
+
+// Item is /item/{id or name}
+type Item struct {
+	ID            int           `json:"id"`
+	Name          string        `json:"name"`
+	Cost          int           `json:"cost"`
+	Category      NamedResource `json:"category"`
+	EffectEntries []struct {
+		ShortEffect string        `json:"short_effect"`
+		Language    NamedResource `json:"language"`
+	} `json:"effect_entries"`
+	Sprites struct {
+		Default string `json:"default"`
+	} `json:"sprites"`
+}
@@ -0,0 +1,152 @@
+package pokeapitest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
+)
+
+// Fake is an in-memory pokeapi.Client. Anything it hasn't been given is
+// pokeapi.ErrNotFound. It counts lookups by resource, so tests can check
+// what was fetched.
+type Fake struct {
+	mu      sync.Mutex
+	pokemon map[string]*pokeapi.Pokemon
+	species map[string]*pokeapi.Species
+	moves   map[string]*pokeapi.Move
+	chains  map[string]*pokeapi.EvolutionChain
+	items   map[string]*pokeapi.Item
+	calls   map[string]int
+}
+
+var _ pokeapi.Client = (*Fake)(nil)
+
+func NewFake() *Fake {
+	return &Fake{
+		pokemon: map[string]*pokeapi.Pokemon{},
+		species: map[string]*pokeapi.Species{},
+		moves:   map[string]*pokeapi.Move{},
+		chains:  map[string]*pokeapi.EvolutionChain{},
+		items:   map[string]*pokeapi.Item{},
+		calls:   map[string]int{},
+	}
+}
+
+// LoadFake makes a Fake holding every fixture under dir in fsys, e.g.
+// LoadFake(Fixtures, "fixtures")
+func LoadFake(fsys fs.FS, dir string) (*Fake, error) {
+	idx, err := readFixtures(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	f := NewFake()
+	for resource, byKey := range idx {
+		for key, data := range byKey {
+			var dst any
+			switch resource {
+			case "pokemon":
+				dst = &pokeapi.Pokemon{}
+			case "pokemon-species":
+				dst = &pokeapi.Species{}
+			case "move":
+				dst = &pokeapi.Move{}
+			case "evolution-chain":
+				dst = &pokeapi.EvolutionChain{}
+			case "item":
+				dst = &pokeapi.Item{}
+			}
+			if err := json.Unmarshal(data, dst); err != nil {
+				return nil, fmt.Errorf("error parsing %s fixture %s: %w", resource, key, err)
+			}
+			switch v := dst.(type) {
+			case *pokeapi.Pokemon:
+				f.pokemon[key] = v
+			case *pokeapi.Species:
+				f.species[key] = v
+			case *pokeapi.Move:
+				f.moves[key] = v
+			case *pokeapi.EvolutionChain:
+				f.chains[key] = v
+			case *pokeapi.Item:
+				f.items[key] = v
+			}
+		}
+	}
+	return f, nil
+}
+
+func (f *Fake) AddPokemon(p pokeapi.Pokemon) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pokemon[strconv.Itoa(p.ID)], f.pokemon[p.Name] = &p, &p
+}
+
+func (f *Fake) AddSpecies(s pokeapi.Species) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.species[strconv.Itoa(s.ID)], f.species[s.Name] = &s, &s
+}
+
+func (f *Fake) AddMove(m pokeapi.Move) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.moves[strconv.Itoa(m.ID)], f.moves[m.Name] = &m, &m
+}
+
+func (f *Fake) AddEvolutionChain(e pokeapi.EvolutionChain) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.chains[strconv.Itoa(e.ID)] = &e
+}
+
+func (f *Fake) AddItem(i pokeapi.Item) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[strconv.Itoa(i.ID)], f.items[i.Name] = &i, &i
+}
+
+// Calls is how many times a resource, like "pokemon" or "move", was looked up
+func (f *Fake) Calls(resource string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[resource]
+}
+
+func (f *Fake) Pokemon(ctx context.Context, identifier string) (*pokeapi.Pokemon, error) {
+	return lookup(f, "pokemon", f.pokemon, identifier)
+}
+
+func (f *Fake) Species(ctx context.Context, identifier string) (*pokeapi.Species, error) {
+	return lookup(f, "pokemon-species", f.species, identifier)
+}
+
+func (f *Fake) Move(ctx context.Context, identifier string) (*pokeapi.Move, error) {
+	return lookup(f, "move", f.moves, identifier)
+}
+
+func (f *Fake) EvolutionChain(ctx context.Context, id int) (*pokeapi.EvolutionChain, error) {
+	return lookup(f, "evolution-chain", f.chains, strconv.Itoa(id))
+}
+
+func (f *Fake) Item(ctx context.Context, identifier string) (*pokeapi.Item, error) {
+	return lookup(f, "item", f.items, identifier)
+}
+
+// Returns a copy, so callers can change what they get back
+func lookup[T any](f *Fake, resource string, m map[string]*T, identifier string) (*T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[resource]++
+	v, ok := m[strings.ToLower(identifier)]
+	if !ok {
+		return nil, fmt.Errorf("%s %s: %w", resource, identifier, pokeapi.ErrNotFound)
+	}
+	c := *v
+	return &c, nil
+}
@@ -0,0 +1,60 @@
+// Package pokeapitest provides stand-ins for PokéAPI so handlers can be
+// exercised offline: an in-memory Fake client and an httptest server, both
+// loaded from fixture JSON.
+//
+// Fixtures are PokéAPI responses laid out by resource, e.g.
+// fixtures/pokemon/pikachu.json or fixtures/move/thunderbolt.json. Each can be
+// looked up by its id or name.
+package pokeapitest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Fixtures are the bundled responses: pikachu and meowth with their species,
+// moves and evolution chains, and a few items
+//
+//go:embed fixtures
+var Fixtures embed.FS
+
+// Resources that fixtures can be given for, by PokéAPI path
+var resources = []string{"pokemon", "pokemon-species", "move", "evolution-chain", "item"}
+
+// Raw fixture JSON by resource, then by id and name
+type index map[string]map[string][]byte
+
+// Reads every fixture under a directory of fsys, like Fixtures' "fixtures"
+func readFixtures(fsys fs.FS, dir string) (index, error) {
+	idx := index{}
+	for _, resource := range resources {
+		idx[resource] = map[string][]byte{}
+		files, err := fs.Glob(fsys, path.Join(dir, resource, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			data, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, err
+			}
+			var key struct {
+				ID   int    `json:"id"`
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal(data, &key); err != nil {
+				return nil, fmt.Errorf("error parsing fixture %s: %w", file, err)
+			}
+			idx[resource][strconv.Itoa(key.ID)] = data
+			if key.Name != "" {
+				idx[resource][strings.ToLower(key.Name)] = data
+			}
+		}
+	}
+	return idx, nil
+}
@@ -0,0 +1,28 @@
+package pokeapitest
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// NewServer starts a server answering PokéAPI paths like /pokemon/25/ from
+// the fixtures under dir in fsys, 404 for anything else. Point a client at it
+// with pokeapi.New(server.URL). Close it when done.
+func NewServer(fsys fs.FS, dir string) (*httptest.Server, error) {
+	idx, err := readFixtures(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource, key, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+		data, ok := idx[resource][strings.ToLower(key)]
+		if r.Method != http.MethodGet || !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})), nil
+}
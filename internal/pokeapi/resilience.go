@@ -0,0 +1,215 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned when PokéAPI keeps failing, is rate limiting us
+// for longer than we'll wait, or the circuit breaker is open. Errors wrapping
+// it are *UnavailableError.
+var ErrUnavailable = errors.New("PokéAPI is unavailable")
+
+// UnavailableError says when it's worth trying again
+type UnavailableError struct {
+	RetryAfter time.Duration
+	Err        error // The last failure, nil when the breaker was open
+}
+
+func (e *UnavailableError) Error() string {
+	if e.Err == nil {
+		return ErrUnavailable.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrUnavailable, e.Err)
+}
+
+func (e *UnavailableError) Is(target error) bool { return target == ErrUnavailable }
+
+func (e *UnavailableError) Unwrap() error { return e.Err }
+
+// Metrics counts outbound PokéAPI calls by outcome, published through expvar
+// as "pokeapi". main serves it on the METRICS_ADDR listener only.
+var Metrics = expvar.NewMap("pokeapi")
+
+// Metric keys
+const (
+	metricRequests      = "requests"       // Attempts sent, retries included
+	metricOK            = "ok"             // 200s
+	metricNotFound      = "not_found"      // 404s
+	metricRateLimited   = "rate_limited"   // 429s
+	metricServerErrors  = "server_errors"  // 5xx
+	metricNetworkErrors = "network_errors" // No response at all
+	metricRetries       = "retries"        // Attempts after the first
+	metricUnavailable   = "unavailable"    // Calls given up on with ErrUnavailable
+	metricBreakerOpen   = "breaker_open"   // Calls failed fast by the breaker
+	metricBreakerTrips  = "breaker_trips"  // Times the breaker opened
+	metricLimiterWaitMS = "limiter_wait_ms"
+)
+
+// Limiter is a token bucket allowing Rate requests a second on average and up
+// to Burst at once
+type Limiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	start := time.Now()
+	defer func() { Metrics.Add(metricLimiterWaitMS, time.Since(start).Milliseconds()) }()
+
+	// Take a token now, possibly going into debt, and sleep off the debt
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, wait); err != nil {
+		// Hand the token back, it was never used
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Breaker states, as reported by State
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// Breaker opens after Threshold failures in a row and fails calls fast for
+// Cooldown. After that one trial call is let through: success closes it, a
+// failure opens it for another Cooldown.
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openTill time.Time
+	trialAt  time.Time // When the half-open trial call went out, zero if none has
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{Threshold: threshold, Cooldown: cooldown}
+}
+
+// Allow says whether a call may go ahead. When it may not, it returns how
+// long until one can.
+func (b *Breaker) Allow() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.Threshold {
+		return true, 0
+	}
+	if left := time.Until(b.openTill); left > 0 {
+		return false, left
+	}
+	// A trial that never reported back, e.g. its caller gave up, frees up
+	// after a Cooldown
+	if !b.trialAt.IsZero() && time.Since(b.trialAt) < b.Cooldown {
+		return false, b.Cooldown - time.Since(b.trialAt)
+	}
+	b.trialAt = time.Now()
+	return true, 0
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trialAt = time.Time{}
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	// Calls that were already in flight when it opened don't reopen it
+	if b.failures == b.Threshold || !b.trialAt.IsZero() {
+		Metrics.Add(metricBreakerTrips, 1)
+		b.openTill = time.Now().Add(b.Cooldown)
+	}
+	b.trialAt = time.Time{}
+}
+
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < b.Threshold:
+		return BreakerClosed
+	case time.Now().Before(b.openTill):
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// Whether a status is worth retrying
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// Exponential backoff with full jitter: a random delay up to base*2^attempt,
+// capped at limit
+func backoff(attempt int, base, limit time.Duration) time.Duration {
+	ceiling := base << attempt
+	if ceiling <= 0 || ceiling > limit {
+		ceiling = limit
+	}
+	return rand.N(ceiling) + 1
+}
+
+// Parses a Retry-After header, either seconds or an HTTP date
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
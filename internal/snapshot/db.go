@@ -0,0 +1,96 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JadedPigeon/pokemongolang/internal/database"
+)
+
+// FromDB copies the whole cached pokedex into a snapshot
+func FromDB(ctx context.Context, q *database.Queries) (*Snapshot, error) {
+	pokedex, err := q.ListPokedex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pokedex: %w", err)
+	}
+	s := &Snapshot{}
+	for _, p := range pokedex {
+		if err := s.AddSpecies(ctx, q, p); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// AddSpecies adds a cached species with its abilities and moves, once
+func (s *Snapshot) AddSpecies(ctx context.Context, q *database.Queries, p database.Pokedex) error {
+	if _, ok := s.Species(p.Name); ok {
+		return nil
+	}
+	species := Species{
+		ID:    p.ID,
+		Name:  p.Name,
+		Types: []string{p.Type1},
+		Stats: Stats{
+			HP:             p.Hp,
+			Attack:         p.Attack,
+			Defense:        p.Defense,
+			SpecialAttack:  p.SpecialAttack,
+			SpecialDefense: p.SpecialDefense,
+			Speed:          p.Speed,
+		},
+		ImageURL: p.ImageUrl.String,
+	}
+	if p.Type2.Valid {
+		species.Types = append(species.Types, p.Type2.String)
+	}
+
+	abilities, err := q.GetPokemonAbilities(ctx, p.ID)
+	if err != nil {
+		return fmt.Errorf("error getting abilities for %s: %w", p.Name, err)
+	}
+	for _, a := range abilities {
+		species.Abilities = append(species.Abilities, Ability{Name: a.Ability, IsHidden: a.IsHidden, Slot: a.Slot})
+	}
+
+	names, err := q.GetPokemonNames(ctx, p.ID)
+	if err != nil {
+		return fmt.Errorf("error getting names for %s: %w", p.Name, err)
+	}
+	for _, n := range names {
+		species.Names = append(species.Names, Name{Language: n.Language, Name: n.Name.String, FlavorText: n.FlavorText.String})
+	}
+
+	moves, err := q.GetPokemonMoves(ctx, p.ID)
+	if err != nil {
+		return fmt.Errorf("error getting moves for %s: %w", p.Name, err)
+	}
+	for _, m := range moves {
+		species.Moves = append(species.Moves, m.MoveID)
+		if _, ok := s.Move(m.MoveID); ok {
+			continue
+		}
+		moveNames, err := q.GetMoveNames(ctx, m.MoveID)
+		if err != nil {
+			return fmt.Errorf("error getting names for %s: %w", m.Name, err)
+		}
+		move := Move{
+			ID:          m.MoveID,
+			Name:        m.Name,
+			Type:        m.Type,
+			Power:       m.Power,
+			DamageClass: m.DamageClass,
+			Priority:    m.Priority,
+			MinHits:     m.MinHits.Int32,
+			MaxHits:     m.MaxHits.Int32,
+			Target:      m.Target,
+			Description: m.Description.String,
+		}
+		for _, n := range moveNames {
+			move.Names = append(move.Names, Name{Language: n.Language, Name: n.Name.String, FlavorText: n.FlavorText.String})
+		}
+		s.Moves = append(s.Moves, move)
+	}
+	s.Pokemon = append(s.Pokemon, species)
+	return nil
+}
@@ -0,0 +1,116 @@
+// Package snapshot reads and writes a JSON copy of the cached pokedex, so
+// tools can work without a database or PokéAPI
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Snapshot is the cached pokedex and moves, plus any saved teams
+type Snapshot struct {
+	Pokemon []Species           `json:"pokemon"`
+	Moves   []Move              `json:"moves"`
+	Teams   map[string][]Member `json:"teams,omitempty"`
+}
+
+// Species is a pokedex entry with the abilities and moves it has cached
+type Species struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	Types     []string  `json:"types"`
+	Stats     Stats     `json:"stats"`
+	ImageURL  string    `json:"image_url,omitempty"`
+	Abilities []Ability `json:"abilities,omitempty"`
+	Moves     []int32   `json:"moves"` // move IDs
+	Names     []Name    `json:"names,omitempty"`
+}
+
+type Stats struct {
+	HP             int32 `json:"hp"`
+	Attack         int32 `json:"attack"`
+	Defense        int32 `json:"defense"`
+	SpecialAttack  int32 `json:"special_attack"`
+	SpecialDefense int32 `json:"special_defense"`
+	Speed          int32 `json:"speed"`
+}
+
+type Ability struct {
+	Name     string `json:"name"`
+	IsHidden bool   `json:"is_hidden,omitempty"`
+	Slot     int32  `json:"slot"`
+}
+
+type Move struct {
+	ID          int32  `json:"id"`
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Power       int32  `json:"power"`
+	DamageClass string `json:"damage_class"`
+	Priority    int32  `json:"priority,omitempty"`
+	MinHits     int32  `json:"min_hits,omitempty"`
+	MaxHits     int32  `json:"max_hits,omitempty"`
+	Target      string `json:"target,omitempty"`
+	Description string `json:"description,omitempty"`
+	Names       []Name `json:"names,omitempty"`
+}
+
+// Name is a species' or move's name and flavor text in one PokéAPI language
+type Name struct {
+	Language   string `json:"language"`
+	Name       string `json:"name,omitempty"`
+	FlavorText string `json:"flavor_text,omitempty"`
+}
+
+// Member is a pokemon in a saved team
+type Member struct {
+	Species  string `json:"species"`
+	Ability  string `json:"ability,omitempty"`
+	HeldItem string `json:"held_item,omitempty"`
+}
+
+// Load reads a snapshot from a JSON file
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("error parsing snapshot %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes a snapshot to a JSON file
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Species finds a species by name, case insensitively, or by pokedex number
+func (s *Snapshot) Species(identifier string) (*Species, bool) {
+	identifier = strings.ToLower(identifier)
+	for i := range s.Pokemon {
+		p := &s.Pokemon[i]
+		if p.Name == identifier || fmt.Sprint(p.ID) == identifier {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Move finds a move by ID
+func (s *Snapshot) Move(id int32) (*Move, bool) {
+	for i := range s.Moves {
+		if s.Moves[i].ID == id {
+			return &s.Moves[i], true
+		}
+	}
+	return nil, false
+}
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"expvar"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/JadedPigeon/pokemongolang/internal/database"
 	"github.com/JadedPigeon/pokemongolang/internal/describe"
 	"github.com/JadedPigeon/pokemongolang/internal/handlers"
+	"github.com/JadedPigeon/pokemongolang/internal/pokeapi"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
@@ -45,18 +50,78 @@ func main() {
 		d = describe.NewOpenAI(model)
 	}
 
+	// Turn timer for trainer battles, a Go duration like "2m", off when unset
+	var turnTimeout time.Duration
+	if v := os.Getenv("BATTLE_TURN_TIMEOUT"); v != "" {
+		turnTimeout, err = time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid BATTLE_TURN_TIMEOUT: %v", err)
+		}
+	}
+
+	// Idle battles are closed after this many hours
+	battleExpiry := handlers.DefaultBattleExpiry
+	if v := os.Getenv("BATTLE_EXPIRY_HOURS"); v != "" {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours < 1 {
+			log.Fatalf("Invalid BATTLE_EXPIRY_HOURS: %q", v)
+		}
+		battleExpiry = time.Duration(hours) * time.Hour
+	}
+
+	// Outbound PokéAPI requests a second, 0 turns the limiter off
+	api := pokeapi.New(os.Getenv("POKEAPI_URL"))
+	if v := os.Getenv("POKEAPI_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 {
+			log.Fatalf("Invalid POKEAPI_RATE: %q", v)
+		}
+		api.Limiter = nil
+		if rate > 0 {
+			api.Limiter = pokeapi.NewLimiter(rate, max(int(rate), 1))
+		}
+	}
+
+	// Downloaded sprites are kept here, served by /sprites/{id}
+	spriteDir := os.Getenv("SPRITE_DIR")
+	if spriteDir == "" {
+		spriteDir = handlers.DefaultSpriteDir
+	}
+
 	cfg := &handlers.Config{
-		DB:        database.New(db),
-		Describer: d,
+		DB:           database.New(db),
+		DBConn:       db,
+		Describer:    d,
+		PokeAPI:      api,
+		TurnTimeout:  turnTimeout,
+		BattleExpiry: battleExpiry,
+		SpriteDir:    spriteDir,
+	}
+
+	go cfg.RunBattleJanitor(context.Background(), handlers.JanitorInterval)
+	go cfg.RunSpriteJob(context.Background(), handlers.SpriteInterval)
+
+	// Outbound call metrics are at /debug/vars under "pokeapi", on their own
+	// listener so they're never public. Off when METRICS_ADDR is unset.
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		ops := http.NewServeMux()
+		ops.Handle("/debug/vars", expvar.Handler())
+		go func() { log.Fatal(http.ListenAndServe(addr, ops)) }()
 	}
 
-	// Health route (for Docker healthchecks and quick smoke tests)
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	// Routes get their own mux, since packages like expvar register on
+	// http.DefaultServeMux
+	mux := http.NewServeMux()
+
+	// Health route (for Docker healthchecks and quick smoke tests). PokéAPI
+	// being down doesn't fail it, cached species still work.
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		status := struct {
-			OK bool   `json:"ok"`
-			DB string `json:"db"`
-		}{OK: true, DB: "up"}
+			OK      bool   `json:"ok"`
+			DB      string `json:"db"`
+			PokeAPI string `json:"pokeapi"`
+		}{OK: true, DB: "up", PokeAPI: api.Breaker.State()}
 		if err := db.Ping(); err != nil {
 			status.DB = "down"
 			w.WriteHeader(http.StatusServiceUnavailable)
@@ -65,17 +130,46 @@ func main() {
 	})
 
 	// Set up routes
-	http.HandleFunc("/register", cfg.RegisterHandler)
-	http.HandleFunc("/login", cfg.LoginHandler)
-	http.HandleFunc("/logout", cfg.AuthMiddleware(cfg.LogoutHandler))
-	http.HandleFunc("/protected", cfg.AuthMiddleware(cfg.ProtectedHandler))
-	http.HandleFunc("/catch", cfg.AuthMiddleware(cfg.CatchPokemonHandler))
-	http.HandleFunc("/challenge", cfg.AuthMiddleware(cfg.ChooseChallengePokemonHandler))
-	http.HandleFunc("/GetUserPokemon", cfg.AuthMiddleware(cfg.GetUserPokemonHandler))
-	http.HandleFunc("/ChangeActivePokemon", cfg.AuthMiddleware(cfg.ChangeActivePokemonHandler))
-	http.HandleFunc("/StartBattle", cfg.AuthMiddleware(cfg.StartBattleHandler))
-	http.HandleFunc("/Fight", cfg.AuthMiddleware(cfg.FightHandler))
-
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	mux.HandleFunc("/register", cfg.RegisterHandler)
+	mux.HandleFunc("/login", cfg.LoginHandler)
+	mux.HandleFunc("/logout", cfg.AuthMiddleware(cfg.LogoutHandler))
+	mux.HandleFunc("/protected", cfg.AuthMiddleware(cfg.ProtectedHandler))
+	mux.HandleFunc("/GetPokedex", cfg.GetPokedexHandler)
+	mux.HandleFunc("/GetPokedexEntry", cfg.GetPokedexEntryHandler)
+	mux.HandleFunc("/catch", cfg.AuthMiddleware(cfg.CatchPokemonHandler))
+	mux.HandleFunc("/challenge", cfg.AuthMiddleware(cfg.ChooseChallengePokemonHandler))
+	mux.HandleFunc("/GetUserPokemon", cfg.AuthMiddleware(cfg.GetUserPokemonHandler))
+	mux.HandleFunc("/ChangeActivePokemon", cfg.AuthMiddleware(cfg.ChangeActivePokemonHandler))
+	mux.HandleFunc("/DepositPokemon", cfg.AuthMiddleware(cfg.DepositPokemonHandler))
+	mux.HandleFunc("/WithdrawPokemon", cfg.AuthMiddleware(cfg.WithdrawPokemonHandler))
+	mux.HandleFunc("/GetBoxPokemon", cfg.AuthMiddleware(cfg.GetBoxPokemonHandler))
+	mux.HandleFunc("/NicknamePokemon", cfg.AuthMiddleware(cfg.NicknamePokemonHandler))
+	mux.HandleFunc("/ReleasePokemon", cfg.AuthMiddleware(cfg.ReleasePokemonHandler))
+	mux.HandleFunc("/ReorderParty", cfg.AuthMiddleware(cfg.ReorderPartyHandler))
+	mux.HandleFunc("/GetTradeablePokemon", cfg.AuthMiddleware(cfg.GetTradeablePokemonHandler))
+	mux.HandleFunc("/OfferTrade", cfg.AuthMiddleware(cfg.OfferTradeHandler))
+	mux.HandleFunc("/RespondTrade", cfg.AuthMiddleware(cfg.RespondTradeHandler))
+	mux.HandleFunc("/GetTradeOffers", cfg.AuthMiddleware(cfg.GetTradeOffersHandler))
+	mux.HandleFunc("/GetTradeHistory", cfg.AuthMiddleware(cfg.GetTradeHistoryHandler))
+	mux.HandleFunc("/GetBag", cfg.AuthMiddleware(cfg.GetBagHandler))
+	mux.HandleFunc("/GetItem", cfg.AuthMiddleware(cfg.GetItemHandler))
+	mux.HandleFunc("/UseItem", cfg.AuthMiddleware(cfg.UseItemHandler))
+	mux.HandleFunc("/EquipItem", cfg.AuthMiddleware(cfg.EquipItemHandler))
+	mux.HandleFunc("/UnequipItem", cfg.AuthMiddleware(cfg.UnequipItemHandler))
+	mux.HandleFunc("/GetShop", cfg.AuthMiddleware(cfg.GetShopHandler))
+	mux.HandleFunc("/BuyItem", cfg.AuthMiddleware(cfg.BuyItemHandler))
+	mux.HandleFunc("/GetBalance", cfg.AuthMiddleware(cfg.GetBalanceHandler))
+	mux.HandleFunc("/StartBattle", cfg.AuthMiddleware(cfg.StartBattleHandler))
+	mux.HandleFunc("/Fight", cfg.AuthMiddleware(cfg.FightHandler))
+	mux.HandleFunc("/Run", cfg.AuthMiddleware(cfg.RunHandler))
+	mux.HandleFunc("/GetBattleHistory", cfg.AuthMiddleware(cfg.GetBattleHistoryHandler))
+	mux.HandleFunc("/GetLeaderboard", cfg.AuthMiddleware(cfg.GetLeaderboardHandler))
+	mux.HandleFunc("/CalculateDamage", cfg.AuthMiddleware(cfg.CalculateDamageHandler))
+	mux.HandleFunc("/GetMoves", cfg.GetMovesHandler)
+	mux.HandleFunc("/GetMove", cfg.GetMoveHandler)
+	mux.HandleFunc("/GetPokedexProgress", cfg.AuthMiddleware(cfg.GetPokedexProgressHandler))
+	mux.HandleFunc("/sprites/{id}", cfg.AuthMiddleware(cfg.GetSpriteHandler))
+
+	log.Fatal(http.ListenAndServe(":8080", mux))
 
 }
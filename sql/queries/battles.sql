@@ -0,0 +1,131 @@
+-- name: AbandonBattle :exec
+-- Swapping challengers mid-battle counts as running from it
+UPDATE battles
+SET result = CASE kind WHEN 'trainer' THEN 'forfeited' ELSE 'fled' END,
+    ended_at = NOW(),
+    updated_at = NOW()
+WHERE challenger_pokemon_id = $1 AND result IS NULL AND turn > 0;
+
+-- name: DeleteUnstartedBattle :exec
+-- A challenger swapped out before a single turn was played never became a battle
+DELETE FROM battles
+WHERE challenger_pokemon_id = $1 AND result IS NULL AND turn = 0;
+
+-- name: CreateBattle :exec
+INSERT INTO battles (
+    id,
+    user_id,
+    challenger_pokemon_id,
+    challenger_species_id,
+    kind,
+    format,
+    challenger_partner_id,
+    created_at,
+    updated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, DEFAULT, DEFAULT
+)
+ON CONFLICT (challenger_pokemon_id) DO NOTHING;
+
+-- name: GetBattle :one
+SELECT * FROM battles
+WHERE challenger_pokemon_id = $1;
+
+-- name: GetBattleForUpdate :one
+SELECT * FROM battles
+WHERE challenger_pokemon_id = $1
+FOR UPDATE;
+
+-- name: DeleteChallengerPartner :exec
+-- The second challenger of a double battle goes when its lead does
+DELETE FROM challenger_pokemon
+WHERE id = (SELECT challenger_partner_id FROM battles WHERE challenger_pokemon_id = $1);
+
+-- name: UpdateBattleState :exec
+UPDATE battles
+SET user_pokemon_id = $2,
+    turn = $3,
+    weather = $4,
+    weather_turns = $5,
+    user_charging_move_id = $6,
+    user_recharging = $7,
+    challenger_charging_move_id = $8,
+    challenger_recharging = $9,
+    flee_attempts = $10,
+    user_partner_id = $11,
+    user_partner_charging_move_id = $12,
+    user_partner_recharging = $13,
+    challenger_partner_charging_move_id = $14,
+    challenger_partner_recharging = $15,
+    user_attack_stage = $16,
+    user_partner_attack_stage = $17,
+    challenger_attack_stage = $18,
+    challenger_partner_attack_stage = $19,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: FinishBattle :exec
+UPDATE battles
+SET result = $2, ended_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND result IS NULL;
+
+-- name: ListBattleHistory :many
+-- Challenges that expired without a turn played were never fought
+SELECT b.id, b.kind, b.result, b.turn, b.created_at, b.ended_at,
+    cs.name AS challenger_species_name,
+    us.name AS user_species_name
+FROM battles b
+LEFT JOIN pokedex cs ON b.challenger_species_id = cs.id
+LEFT JOIN user_pokemon up ON b.user_pokemon_id = up.id
+LEFT JOIN pokedex us ON up.pokemon_id = us.id
+WHERE b.user_id = $1 AND b.result IS NOT NULL
+  AND NOT (b.result = 'expired' AND b.turn = 0)
+ORDER BY b.ended_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountBattleHistory :one
+SELECT COUNT(*) FROM battles
+WHERE user_id = $1 AND result IS NOT NULL
+  AND NOT (result = 'expired' AND turn = 0);
+
+-- name: ListLeaderboard :many
+-- Trainer battles only, forfeits count as losses
+SELECT u.username,
+    COUNT(*) FILTER (WHERE b.result = 'won') AS wins,
+    COUNT(*) FILTER (WHERE b.result IN ('lost', 'forfeited')) AS losses
+FROM battles b
+JOIN users u ON b.user_id = u.id
+WHERE b.kind = 'trainer' AND b.result IS NOT NULL
+GROUP BY u.id, u.username
+ORDER BY wins DESC, losses ASC, u.username
+LIMIT $1 OFFSET $2;
+
+-- name: CountLeaderboard :one
+SELECT COUNT(DISTINCT user_id) FROM battles
+WHERE kind = 'trainer' AND result IS NOT NULL;
+
+-- name: ExpireInactiveBattles :execrows
+UPDATE battles
+SET result = 'expired', ended_at = NOW(), updated_at = NOW()
+WHERE result IS NULL AND updated_at < $1;
+
+-- name: ForfeitTimedOutBattles :execrows
+-- Trainer battles where the user hasn't moved within the turn time limit
+UPDATE battles
+SET result = 'forfeited', ended_at = NOW(), updated_at = NOW()
+WHERE result IS NULL AND kind = 'trainer' AND turn > 0 AND updated_at < $1;
+
+-- name: UnlinkEndedChallengers :execrows
+-- Battles the user left, or that timed out, no longer hold on to their challenger
+UPDATE users u
+SET challenge_pokemon_id = NULL
+FROM battles b
+WHERE b.challenger_pokemon_id = u.challenge_pokemon_id
+  AND b.result IN ('fled', 'forfeited', 'expired');
+
+-- name: DeleteOrphanedChallengePokemon :execrows
+-- Challengers no user or open battle points at, left behind by races between replacing and deleting them
+DELETE FROM challenger_pokemon cp
+WHERE (cp.created_at IS NULL OR cp.created_at < $1)
+  AND NOT EXISTS (SELECT 1 FROM users u WHERE u.challenge_pokemon_id = cp.id)
+  AND NOT EXISTS (SELECT 1 FROM battles b WHERE b.challenger_partner_id = cp.id AND b.result IS NULL);
@@ -0,0 +1,34 @@
+-- name: GetUserBalance :one
+SELECT balance FROM users WHERE id = $1;
+
+-- name: CreditUserBalance :one
+UPDATE users
+SET balance = balance + @amount
+WHERE id = @user_id
+RETURNING balance;
+
+-- name: DebitUserBalance :one
+-- Only succeeds if the user can afford it, so concurrent purchases can't overdraw
+UPDATE users
+SET balance = balance - @amount
+WHERE id = @user_id AND balance >= @amount
+RETURNING balance;
+
+-- name: InsertLedgerEntry :exec
+INSERT INTO currency_ledger (id, user_id, amount, balance_after, reason, reference, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, DEFAULT);
+
+-- name: ListLedgerEntries :many
+SELECT * FROM currency_ledger
+WHERE user_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2 OFFSET $3;
+
+-- name: CountLedgerEntries :one
+SELECT COUNT(*) FROM currency_ledger WHERE user_id = $1;
+
+-- name: ListShopItems :many
+SELECT * FROM shop_items ORDER BY price, item_name;
+
+-- name: GetShopItem :one
+SELECT * FROM shop_items WHERE item_name = $1;
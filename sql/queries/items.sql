@@ -0,0 +1,38 @@
+-- name: GetItemByID :one
+SELECT * FROM items WHERE id = $1;
+
+-- name: GetItemByName :one
+SELECT * FROM items WHERE name = LOWER($1);
+
+-- name: UpsertItem :exec
+INSERT INTO items (id, name, category, cost, effect, image_url)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    category = EXCLUDED.category,
+    cost = EXCLUDED.cost,
+    effect = EXCLUDED.effect,
+    image_url = EXCLUDED.image_url;
+
+-- name: GetUserBag :many
+SELECT i.*, ui.quantity
+FROM user_items ui
+JOIN items i ON ui.item_id = i.id
+WHERE ui.user_id = $1 AND ui.quantity > 0
+ORDER BY i.category, i.name;
+
+-- name: GetUserItemQuantity :one
+SELECT quantity FROM user_items WHERE user_id = $1 AND item_id = $2;
+
+-- name: ConsumeUserItem :one
+UPDATE user_items
+SET quantity = quantity - 1
+WHERE user_id = $1 AND item_id = $2 AND quantity > 0
+RETURNING quantity;
+
+-- name: AddUserItem :one
+INSERT INTO user_items (user_id, item_id, quantity)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, item_id) DO UPDATE
+SET quantity = user_items.quantity + EXCLUDED.quantity
+RETURNING quantity;
@@ -0,0 +1,22 @@
+-- name: SearchMoves :many
+SELECT * FROM moves
+WHERE (sqlc.narg('name')::text IS NULL OR strpos(name, sqlc.narg('name')) > 0)
+  AND (sqlc.narg('type')::text IS NULL OR type = sqlc.narg('type'))
+  AND (sqlc.narg('damage_class')::text IS NULL OR damage_class = sqlc.narg('damage_class'))
+  AND power BETWEEN @min_power AND @max_power
+ORDER BY name
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: CountMovesSearch :one
+SELECT COUNT(*) FROM moves
+WHERE (sqlc.narg('name')::text IS NULL OR strpos(name, sqlc.narg('name')) > 0)
+  AND (sqlc.narg('type')::text IS NULL OR type = sqlc.narg('type'))
+  AND (sqlc.narg('damage_class')::text IS NULL OR damage_class = sqlc.narg('damage_class'))
+  AND power BETWEEN @min_power AND @max_power;
+
+-- name: ListMoveLearners :many
+SELECT p.*
+FROM pokemon_moves pm
+JOIN pokedex p ON pm.pokemon_id = p.id
+WHERE pm.move_id = $1
+ORDER BY p.id;
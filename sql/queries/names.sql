@@ -0,0 +1,33 @@
+-- name: UpsertPokemonName :exec
+INSERT INTO pokemon_names (pokemon_id, language, name, flavor_text)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (pokemon_id, language) DO UPDATE
+SET name = EXCLUDED.name, flavor_text = EXCLUDED.flavor_text;
+
+-- name: UpsertMoveName :exec
+INSERT INTO move_names (move_id, language, name, flavor_text)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (move_id, language) DO UPDATE
+SET name = EXCLUDED.name, flavor_text = EXCLUDED.flavor_text;
+
+-- name: ListPokemonNames :many
+-- The names of several species in any of the given languages
+SELECT * FROM pokemon_names
+WHERE pokemon_id = ANY(@pokemon_ids::int[])
+  AND language = ANY(@languages::text[]);
+
+-- name: ListMoveNames :many
+-- The names of several moves in any of the given languages
+SELECT * FROM move_names
+WHERE move_id = ANY(@move_ids::int[])
+  AND language = ANY(@languages::text[]);
+
+-- name: GetPokemonNames :many
+SELECT * FROM pokemon_names
+WHERE pokemon_id = $1
+ORDER BY language;
+
+-- name: GetMoveNames :many
+SELECT * FROM move_names
+WHERE move_id = $1
+ORDER BY language;
@@ -0,0 +1,58 @@
+-- name: SearchPokedex :many
+-- stat picks the stat min_stat and max_stat apply to, anything else is the
+-- base stat total. sort is id, name, a stat or total.
+SELECT * FROM pokedex
+WHERE (sqlc.narg('type')::text IS NULL OR type_1 = sqlc.narg('type') OR type_2 = sqlc.narg('type'))
+  AND id BETWEEN @min_id AND @max_id
+  AND (CASE @stat::text
+      WHEN 'hp' THEN hp
+      WHEN 'attack' THEN attack
+      WHEN 'defense' THEN defense
+      WHEN 'special_attack' THEN special_attack
+      WHEN 'special_defense' THEN special_defense
+      WHEN 'speed' THEN speed
+      ELSE hp + attack + defense + special_attack + special_defense + speed
+    END) BETWEEN @min_stat AND @max_stat
+ORDER BY
+  CASE WHEN @descending::bool THEN
+    CASE @sort::text
+      WHEN 'hp' THEN hp
+      WHEN 'attack' THEN attack
+      WHEN 'defense' THEN defense
+      WHEN 'special_attack' THEN special_attack
+      WHEN 'special_defense' THEN special_defense
+      WHEN 'speed' THEN speed
+      WHEN 'total' THEN hp + attack + defense + special_attack + special_defense + speed
+      WHEN 'id' THEN id
+    END
+  END DESC,
+  CASE WHEN NOT @descending::bool THEN
+    CASE @sort::text
+      WHEN 'hp' THEN hp
+      WHEN 'attack' THEN attack
+      WHEN 'defense' THEN defense
+      WHEN 'special_attack' THEN special_attack
+      WHEN 'special_defense' THEN special_defense
+      WHEN 'speed' THEN speed
+      WHEN 'total' THEN hp + attack + defense + special_attack + special_defense + speed
+      WHEN 'id' THEN id
+    END
+  END ASC,
+  CASE WHEN @sort::text = 'name' AND @descending::bool THEN name END DESC,
+  CASE WHEN @sort::text = 'name' AND NOT @descending::bool THEN name END ASC,
+  id
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: CountPokedexSearch :one
+SELECT COUNT(*) FROM pokedex
+WHERE (sqlc.narg('type')::text IS NULL OR type_1 = sqlc.narg('type') OR type_2 = sqlc.narg('type'))
+  AND id BETWEEN @min_id AND @max_id
+  AND (CASE @stat::text
+      WHEN 'hp' THEN hp
+      WHEN 'attack' THEN attack
+      WHEN 'defense' THEN defense
+      WHEN 'special_attack' THEN special_attack
+      WHEN 'special_defense' THEN special_defense
+      WHEN 'speed' THEN speed
+      ELSE hp + attack + defense + special_attack + special_defense + speed
+    END) BETWEEN @min_stat AND @max_stat;
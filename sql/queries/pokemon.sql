@@ -1,9 +1,20 @@
--- name: InsertPokedex :exec
+-- name: UpsertPokedex :exec
 INSERT INTO pokedex (
     id, name, type_1, type_2, hp, attack, defense, special_attack, special_defense, speed, image_url
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-);
+)
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    type_1 = EXCLUDED.type_1,
+    type_2 = EXCLUDED.type_2,
+    hp = EXCLUDED.hp,
+    attack = EXCLUDED.attack,
+    defense = EXCLUDED.defense,
+    special_attack = EXCLUDED.special_attack,
+    special_defense = EXCLUDED.special_defense,
+    speed = EXCLUDED.speed,
+    image_url = EXCLUDED.image_url;
 
 -- name: FetchPokemonDataById :one
 SELECT * FROM pokedex WHERE id = $1;
@@ -11,18 +22,53 @@ SELECT * FROM pokedex WHERE id = $1;
 -- name: FetchPokemonDataByName :one
 SELECT * FROM pokedex WHERE LOWER(name) = LOWER($1);
 
+-- name: ListPokedex :many
+SELECT * FROM pokedex ORDER BY id;
+
+-- name: ListPokedexNames :many
+SELECT name FROM pokedex ORDER BY name;
+
+-- name: ListPokedexWithoutAbilities :many
+-- Cached species whose abilities haven't been stored yet
+SELECT id FROM pokedex p
+WHERE NOT EXISTS (SELECT 1 FROM pokemon_abilities a WHERE a.pokemon_id = p.id)
+ORDER BY id;
+
 -- name: GetMoveByID :one
 SELECT * FROM moves WHERE move_id = $1;
 
--- name: InsertMove :exec
-INSERT INTO moves (move_id, name, power, type, description)
-VALUES ($1, $2, $3, $4, $5);
+-- name: GetMoveByName :one
+SELECT * FROM moves WHERE name = $1;
+
+-- name: UpsertMove :exec
+INSERT INTO moves (move_id, name, power, type, description, damage_class, priority, min_hits, max_hits, target)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (move_id) DO UPDATE SET
+    name = EXCLUDED.name,
+    power = EXCLUDED.power,
+    type = EXCLUDED.type,
+    description = EXCLUDED.description,
+    damage_class = EXCLUDED.damage_class,
+    priority = EXCLUDED.priority,
+    min_hits = EXCLUDED.min_hits,
+    max_hits = EXCLUDED.max_hits,
+    target = EXCLUDED.target;
 
 -- name: InsertPokemonMove :exec
 INSERT INTO pokemon_moves (pokemon_id, move_id)
 VALUES ($1, $2)
 ON CONFLICT (pokemon_id, move_id) DO NOTHING;
 
+-- name: InsertPokemonAbility :exec
+INSERT INTO pokemon_abilities (pokemon_id, ability, is_hidden, slot)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (pokemon_id, ability) DO NOTHING;
+
+-- name: GetPokemonAbilities :many
+SELECT * FROM pokemon_abilities
+WHERE pokemon_id = $1
+ORDER BY slot;
+
 -- name: InsertUserPokemon :exec
 INSERT INTO user_pokemon (
     id,
@@ -31,14 +77,23 @@ INSERT INTO user_pokemon (
     nickname,
     current_hp,
     is_active,
+    in_box,
+    party_slot,
+    ability,
     created_at
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, DEFAULT
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, DEFAULT
 );
 
 -- name: CountUserPokemon :one
 SELECT COUNT(*) FROM user_pokemon WHERE user_id = $1;
 
+-- name: CountUserPartyPokemon :one
+SELECT COUNT(*) FROM user_pokemon WHERE user_id = $1 AND in_box = false;
+
+-- name: CountUserBoxPokemon :one
+SELECT COUNT(*) FROM user_pokemon WHERE user_id = $1 AND in_box = true;
+
 -- name: DeactivateAllUserPokemon :exec
 UPDATE user_pokemon
 SET is_active = false
@@ -55,9 +110,11 @@ INSERT INTO challenger_pokemon (
     id,
     pokemon_id,
     current_hp,
+    held_item_id,
+    ability,
     created_at
 ) VALUES (
-    $1, $2, $3, DEFAULT
+    $1, $2, $3, $4, $5, DEFAULT
 );
 
 -- name: SetUserChallengePokemon :exec
@@ -71,22 +128,141 @@ FROM users u
 JOIN challenger_pokemon cp ON u.challenge_pokemon_id = cp.id
 WHERE u.id = $1;
 
+-- name: GetChallengePokemon :one
+SELECT * FROM challenger_pokemon
+WHERE id = $1;
+
+-- name: GetChallengePokemonForUpdate :one
+SELECT * FROM challenger_pokemon
+WHERE id = $1
+FOR UPDATE;
+
+-- name: SetChallengePokemonHealth :exec
+UPDATE challenger_pokemon
+SET current_hp = $2, status = $3
+WHERE id = $1;
+
 -- name: DeleteChallengePokemon :exec
 DELETE FROM challenger_pokemon
 WHERE id = $1;
 
 -- name: GetAllUserPokemon :many
-SELECT p.*, up.is_active
+SELECT p.*, up.id AS user_pokemon_id, up.is_active, up.nickname, up.party_slot
 FROM user_pokemon up
 JOIN pokedex p ON up.pokemon_id = p.id
-WHERE up.user_id = $1;
+WHERE up.user_id = $1 AND up.in_box = false
+ORDER BY up.party_slot;
 
+-- name: ListUserBoxPokemon :many
+SELECT p.*, up.id AS user_pokemon_id, up.nickname
+FROM user_pokemon up
+JOIN pokedex p ON up.pokemon_id = p.id
+WHERE up.user_id = $1 AND up.in_box = true
+ORDER BY up.created_at, up.id
+LIMIT $2 OFFSET $3;
+
+
+-- name: GetUserPokemonByID :one
+SELECT *
+FROM user_pokemon
+WHERE user_id = $1 AND id = $2;
+
+-- name: GetUserPokemonForUpdate :one
+SELECT *
+FROM user_pokemon
+WHERE id = $1
+FOR UPDATE;
+
+-- name: TransferUserPokemon :exec
+-- Hands a pokemon to another user, placing it where the pokemon it was swapped for used to be
+UPDATE user_pokemon
+SET user_id = $2,
+    in_box = $3,
+    party_slot = $4,
+    is_active = false
+WHERE id = $1;
+
+-- name: ListUserPokemonForTrade :many
+SELECT up.id, up.nickname, up.in_box, p.id AS pokemon_id, p.name, p.type_1, p.type_2, p.image_url
+FROM user_pokemon up
+JOIN pokedex p ON up.pokemon_id = p.id
+WHERE up.user_id = $1
+ORDER BY up.in_box, up.party_slot, up.created_at;
 
 -- name: GetOneUserPokemon :one
+-- Deprecated: a user can own several of the same species, use GetUserPokemonByID
 SELECT *
 FROM user_pokemon
 WHERE user_id = $1 and pokemon_id = $2;
 
+-- name: GetOneUserPokemonByLocation :one
+SELECT *
+FROM user_pokemon
+WHERE user_id = $1 AND pokemon_id = $2 AND in_box = $3
+ORDER BY created_at
+LIMIT 1;
+
+-- name: MoveUserPokemonToBox :execrows
+UPDATE user_pokemon
+SET in_box = true, party_slot = NULL
+WHERE user_id = $1 AND id = $2 AND in_box = false AND is_active = false;
+
+-- name: MoveUserPokemonToParty :execrows
+UPDATE user_pokemon
+SET in_box = false, party_slot = $3
+WHERE user_id = $1 AND id = $2 AND in_box = true;
+
+-- name: GetUserPartyPokemon :many
+SELECT *
+FROM user_pokemon
+WHERE user_id = $1 AND in_box = false
+ORDER BY party_slot;
+
+-- name: GetNextPartySlot :one
+SELECT (COALESCE(MAX(party_slot), 0) + 1)::int AS next_slot
+FROM user_pokemon
+WHERE user_id = $1 AND in_box = false;
+
+-- name: SetUserPokemonPartySlot :exec
+UPDATE user_pokemon
+SET party_slot = $3
+WHERE user_id = $1 AND id = $2 AND in_box = false;
+
+-- name: CompactUserPartySlots :exec
+-- Renumber party slots 1..n, keeping their current order
+UPDATE user_pokemon up
+SET party_slot = s.new_slot
+FROM (
+    SELECT id, ROW_NUMBER() OVER (ORDER BY party_slot, created_at) AS new_slot
+    FROM user_pokemon
+    WHERE user_id = $1 AND in_box = false
+) s
+WHERE up.id = s.id AND up.party_slot IS DISTINCT FROM s.new_slot;
+
+-- name: SetUserPokemonHealth :exec
+UPDATE user_pokemon
+SET current_hp = $3, status = $4
+WHERE user_id = $1 AND id = $2;
+
+-- name: SetUserPokemonHeldItem :exec
+UPDATE user_pokemon
+SET held_item_id = $3
+WHERE user_id = $1 AND id = $2;
+
+-- name: ClearChallengePokemonHeldItem :exec
+UPDATE challenger_pokemon
+SET held_item_id = NULL
+WHERE id = $1;
+
+-- name: SetUserPokemonNickname :exec
+UPDATE user_pokemon
+SET nickname = $3
+WHERE user_id = $1 AND id = $2;
+
+-- name: DeleteUserPokemon :exec
+DELETE FROM user_pokemon
+WHERE user_id = $1 AND id = $2;
+
 -- name: GetActiveUserPokemon :one
 SELECT *
 FROM user_pokemon
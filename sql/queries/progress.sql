@@ -0,0 +1,17 @@
+-- name: MarkPokemonSeen :exec
+INSERT INTO pokedex_progress (user_id, pokemon_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: MarkPokemonCaught :exec
+INSERT INTO pokedex_progress (user_id, pokemon_id, caught, caught_at)
+VALUES ($1, $2, TRUE, NOW())
+ON CONFLICT (user_id, pokemon_id) DO UPDATE
+SET caught = TRUE, caught_at = COALESCE(pokedex_progress.caught_at, NOW());
+
+-- name: ListPokedexProgress :many
+SELECT pp.pokemon_id, pp.caught, p.type_1, p.type_2
+FROM pokedex_progress pp
+JOIN pokedex p ON pp.pokemon_id = p.id
+WHERE pp.user_id = $1
+ORDER BY pp.pokemon_id;
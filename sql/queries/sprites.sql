@@ -0,0 +1,17 @@
+-- name: UpsertPokemonSprite :exec
+INSERT INTO pokemon_sprites (pokemon_id, variant, url)
+VALUES ($1, $2, $3)
+ON CONFLICT (pokemon_id, variant) DO UPDATE
+SET url = EXCLUDED.url;
+
+-- name: ListPokemonSprites :many
+-- Every sprite PokéAPI has, for the sprite job to download
+SELECT * FROM pokemon_sprites
+WHERE url IS NOT NULL
+ORDER BY pokemon_id, variant;
+
+-- name: ListPokedexWithoutSprites :many
+-- Cached species whose sprite URLs haven't been stored yet
+SELECT id FROM pokedex p
+WHERE NOT EXISTS (SELECT 1 FROM pokemon_sprites s WHERE s.pokemon_id = p.id)
+ORDER BY id;
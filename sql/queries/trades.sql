@@ -0,0 +1,64 @@
+-- name: CreateTrade :exec
+INSERT INTO trades (
+    id,
+    from_user_id,
+    to_user_id,
+    offered_pokemon_id,
+    requested_pokemon_id,
+    offered_species_id,
+    requested_species_id,
+    counter_of,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, DEFAULT
+);
+
+-- name: GetTradeForUpdate :one
+SELECT * FROM trades WHERE id = $1 FOR UPDATE;
+
+-- name: SetTradeStatus :exec
+UPDATE trades
+SET status = $2, resolved_at = NOW()
+WHERE id = $1;
+
+-- name: CancelPendingTradesForPokemon :exec
+-- Once a pokemon changes hands every other offer involving it is stale
+UPDATE trades
+SET status = 'cancelled', resolved_at = NOW()
+WHERE status = 'pending'
+  AND (offered_pokemon_id IN (@pokemon_a::uuid, @pokemon_b::uuid)
+    OR requested_pokemon_id IN (@pokemon_a::uuid, @pokemon_b::uuid));
+
+-- name: ListPendingTradesForUser :many
+SELECT t.*,
+    fu.username AS from_username,
+    tu.username AS to_username,
+    op.name AS offered_species_name,
+    rp.name AS requested_species_name
+FROM trades t
+JOIN users fu ON t.from_user_id = fu.id
+JOIN users tu ON t.to_user_id = tu.id
+JOIN pokedex op ON t.offered_species_id = op.id
+JOIN pokedex rp ON t.requested_species_id = rp.id
+WHERE t.status = 'pending' AND (t.from_user_id = @user_id OR t.to_user_id = @user_id)
+ORDER BY t.created_at DESC;
+
+-- name: ListTradeHistoryForUser :many
+SELECT t.*,
+    fu.username AS from_username,
+    tu.username AS to_username,
+    op.name AS offered_species_name,
+    rp.name AS requested_species_name
+FROM trades t
+JOIN users fu ON t.from_user_id = fu.id
+JOIN users tu ON t.to_user_id = tu.id
+JOIN pokedex op ON t.offered_species_id = op.id
+JOIN pokedex rp ON t.requested_species_id = rp.id
+WHERE t.status <> 'pending' AND (t.from_user_id = @user_id OR t.to_user_id = @user_id)
+ORDER BY t.resolved_at DESC, t.created_at DESC
+LIMIT @row_limit OFFSET @row_offset;
+
+-- name: CountTradeHistoryForUser :one
+SELECT COUNT(*)
+FROM trades
+WHERE status <> 'pending' AND (from_user_id = @user_id OR to_user_id = @user_id);
@@ -1,6 +1,10 @@
 -- name: GetUserByUsername :one
 SELECT * FROM users WHERE username = $1;
 
+-- name: LockUser :exec
+-- Serializes changes to a user's party and box, held until the transaction ends
+SELECT id FROM users WHERE id = $1 FOR UPDATE;
+
 -- name: GetUserBySessionToken :one
 SELECT * FROM users WHERE session_token = $1;
 
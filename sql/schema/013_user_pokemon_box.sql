@@ -0,0 +1,19 @@
+-- +goose Up
+-- Pokémon beyond the six-member party are stored in the PC box
+ALTER TABLE user_pokemon
+ADD COLUMN in_box BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The active Pokémon always has to be in the party
+ALTER TABLE user_pokemon
+ADD CONSTRAINT active_pokemon_not_in_box CHECK (NOT (is_active AND in_box));
+
+CREATE INDEX IF NOT EXISTS idx_user_pokemon_user_box ON user_pokemon (user_id, in_box);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_pokemon_user_box;
+
+ALTER TABLE user_pokemon
+DROP CONSTRAINT IF EXISTS active_pokemon_not_in_box;
+
+ALTER TABLE user_pokemon
+DROP COLUMN in_box;
@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE user_pokemon
+ADD COLUMN party_slot INT;
+
+-- Existing party members keep the order they were caught in
+UPDATE user_pokemon up
+SET party_slot = s.slot
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at, id) AS slot
+    FROM user_pokemon
+    WHERE in_box = false
+) s
+WHERE up.id = s.id;
+
+-- Deferred so a reorder can shuffle slots inside a single transaction
+ALTER TABLE user_pokemon
+ADD CONSTRAINT unique_party_slot_per_user UNIQUE (user_id, party_slot)
+DEFERRABLE INITIALLY DEFERRED;
+
+-- Party members always have a slot, boxed pokemon never do
+ALTER TABLE user_pokemon
+ADD CONSTRAINT party_slot_only_in_party CHECK (in_box = (party_slot IS NULL));
+
+-- +goose Down
+ALTER TABLE user_pokemon
+DROP CONSTRAINT IF EXISTS party_slot_only_in_party;
+
+ALTER TABLE user_pokemon
+DROP CONSTRAINT IF EXISTS unique_party_slot_per_user;
+
+ALTER TABLE user_pokemon
+DROP COLUMN party_slot;
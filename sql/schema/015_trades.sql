@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE trades (
+    id UUID PRIMARY KEY,
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- user_pokemon ids, no FK so the history survives a release
+    offered_pokemon_id UUID NOT NULL,
+    requested_pokemon_id UUID NOT NULL,
+    offered_species_id INT NOT NULL REFERENCES pokedex(id),
+    requested_species_id INT NOT NULL REFERENCES pokedex(id),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'accepted', 'rejected', 'countered', 'cancelled')),
+    counter_of UUID REFERENCES trades(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_from_user ON trades (from_user_id, status);
+CREATE INDEX IF NOT EXISTS idx_trades_to_user ON trades (to_user_id, status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_trades_to_user;
+DROP INDEX IF EXISTS idx_trades_from_user;
+DROP TABLE IF EXISTS trades;
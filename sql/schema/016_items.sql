@@ -0,0 +1,29 @@
+-- +goose Up
+-- Item catalog, cached from PokéAPI like the pokedex
+CREATE TABLE items (
+    id INT PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    category TEXT NOT NULL,
+    cost INT NOT NULL DEFAULT 0,
+    effect TEXT,
+    image_url TEXT
+);
+
+-- Each user's bag
+CREATE TABLE user_items (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    item_id INT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    PRIMARY KEY (user_id, item_id)
+);
+
+-- Non-volatile status condition (poison, paralysis, burn, freeze, sleep), NULL when healthy
+ALTER TABLE user_pokemon
+ADD COLUMN status TEXT;
+
+-- +goose Down
+ALTER TABLE user_pokemon
+DROP COLUMN status;
+
+DROP TABLE IF EXISTS user_items;
+DROP TABLE IF EXISTS items;
@@ -0,0 +1,39 @@
+-- +goose Up
+ALTER TABLE moves
+ADD COLUMN damage_class TEXT NOT NULL DEFAULT 'physical';
+
+-- Moves cached before damage_class was stored fall back to the old physical/special split by type
+UPDATE moves
+SET damage_class = 'special'
+WHERE type IN ('fire', 'water', 'grass', 'electric', 'ice', 'psychic', 'dragon', 'dark');
+
+ALTER TABLE moves
+ADD CONSTRAINT moves_damage_class_check CHECK (damage_class IN ('physical', 'special', 'status'));
+
+-- current_hp was measured against base HP, battles now use level 50 stats (base HP + 60)
+UPDATE user_pokemon up
+SET current_hp = up.current_hp * (p.hp + 60) / p.hp
+FROM pokedex p
+WHERE up.pokemon_id = p.id AND p.hp > 0;
+
+UPDATE challenger_pokemon cp
+SET current_hp = cp.current_hp * (p.hp + 60) / p.hp
+FROM pokedex p
+WHERE cp.pokemon_id = p.id AND p.hp > 0;
+
+-- +goose Down
+UPDATE challenger_pokemon cp
+SET current_hp = cp.current_hp * p.hp / (p.hp + 60)
+FROM pokedex p
+WHERE cp.pokemon_id = p.id;
+
+UPDATE user_pokemon up
+SET current_hp = up.current_hp * p.hp / (p.hp + 60)
+FROM pokedex p
+WHERE up.pokemon_id = p.id;
+
+ALTER TABLE moves
+DROP CONSTRAINT IF EXISTS moves_damage_class_check;
+
+ALTER TABLE moves
+DROP COLUMN damage_class;
@@ -0,0 +1,53 @@
+-- +goose Up
+ALTER TABLE users
+ADD COLUMN balance INT NOT NULL DEFAULT 0 CONSTRAINT users_balance_non_negative CHECK (balance >= 0);
+
+-- Every balance change, so any balance can be reconstructed and audited
+CREATE TABLE currency_ledger (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount INT NOT NULL,
+    balance_after INT NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('starting_balance', 'battle_prize', 'shop_purchase')),
+    reference TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_currency_ledger_user ON currency_ledger (user_id, created_at DESC);
+
+-- What the shop sells and for how much, keyed by PokéAPI item name.
+-- Edit these rows to change prices or stock without a deploy.
+CREATE TABLE shop_items (
+    item_name TEXT PRIMARY KEY,
+    price INT NOT NULL CHECK (price > 0)
+);
+
+INSERT INTO shop_items (item_name, price) VALUES
+    ('poke-ball', 200),
+    ('great-ball', 600),
+    ('ultra-ball', 800),
+    ('potion', 300),
+    ('super-potion', 700),
+    ('hyper-potion', 1500),
+    ('max-potion', 2500),
+    ('revive', 2000),
+    ('antidote', 100),
+    ('paralyze-heal', 200),
+    ('burn-heal', 300),
+    ('ice-heal', 100),
+    ('awakening', 100),
+    ('full-heal', 400);
+
+-- Existing trainers get the same starting money as new ones
+UPDATE users SET balance = 3000;
+
+INSERT INTO currency_ledger (id, user_id, amount, balance_after, reason)
+SELECT gen_random_uuid(), id, 3000, 3000, 'starting_balance'
+FROM users;
+
+-- +goose Down
+DROP TABLE IF EXISTS shop_items;
+DROP TABLE IF EXISTS currency_ledger;
+
+ALTER TABLE users
+DROP COLUMN balance;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE user_pokemon
+ADD COLUMN held_item_id INT REFERENCES items(id);
+
+ALTER TABLE challenger_pokemon
+ADD COLUMN held_item_id INT REFERENCES items(id);
+
+-- +goose Down
+ALTER TABLE challenger_pokemon
+DROP COLUMN held_item_id;
+
+ALTER TABLE user_pokemon
+DROP COLUMN held_item_id;
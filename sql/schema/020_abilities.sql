@@ -0,0 +1,27 @@
+-- +goose Up
+-- Possible abilities for each cached species, from PokéAPI's abilities array
+CREATE TABLE pokemon_abilities (
+    pokemon_id INT NOT NULL REFERENCES pokedex(id) ON DELETE CASCADE,
+    ability TEXT NOT NULL,
+    is_hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    slot INT NOT NULL,
+    PRIMARY KEY (pokemon_id, ability)
+);
+
+-- The one ability each pokemon was rolled when caught or challenged
+ALTER TABLE user_pokemon
+ADD COLUMN ability TEXT;
+
+ALTER TABLE challenger_pokemon
+ADD COLUMN ability TEXT,
+ADD COLUMN status TEXT;
+
+-- +goose Down
+ALTER TABLE challenger_pokemon
+DROP COLUMN status,
+DROP COLUMN ability;
+
+ALTER TABLE user_pokemon
+DROP COLUMN ability;
+
+DROP TABLE IF EXISTS pokemon_abilities;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- State that belongs to a battle rather than either pokemon, one battle per challenger
+CREATE TABLE battles (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    challenger_pokemon_id UUID NOT NULL UNIQUE REFERENCES challenger_pokemon(id) ON DELETE CASCADE,
+    -- the user's pokemon currently out, so we know when a new one enters
+    user_pokemon_id UUID REFERENCES user_pokemon(id) ON DELETE SET NULL,
+    turn INT NOT NULL DEFAULT 0,
+    weather TEXT CHECK (weather IN ('rain', 'sun', 'sandstorm', 'hail')),
+    weather_turns INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Challenges already in progress get a battle too
+INSERT INTO battles (id, user_id, challenger_pokemon_id)
+SELECT gen_random_uuid(), id, challenge_pokemon_id
+FROM users
+WHERE challenge_pokemon_id IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS battles;
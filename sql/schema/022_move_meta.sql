@@ -0,0 +1,49 @@
+-- +goose Up
+ALTER TABLE moves
+ADD COLUMN priority INT NOT NULL DEFAULT 0,
+ADD COLUMN min_hits INT,
+ADD COLUMN max_hits INT;
+
+-- Moves cached before priority and hit counts were stored, from PokéAPI's
+-- priority and meta.min_hits/max_hits
+UPDATE moves SET priority = 2 WHERE name IN ('extreme-speed', 'feint', 'first-impression');
+UPDATE moves SET priority = 1 WHERE name IN (
+    'quick-attack', 'mach-punch', 'bullet-punch', 'ice-shard', 'shadow-sneak', 'aqua-jet',
+    'vacuum-wave', 'sucker-punch', 'accelerock', 'jet-punch', 'water-shuriken'
+);
+UPDATE moves SET priority = -1 WHERE name = 'vital-throw';
+UPDATE moves SET priority = -3 WHERE name = 'focus-punch';
+UPDATE moves SET priority = -4 WHERE name IN ('avalanche', 'revenge');
+UPDATE moves SET priority = -5 WHERE name IN ('counter', 'mirror-coat');
+UPDATE moves SET priority = -6 WHERE name IN ('circle-throw', 'dragon-tail');
+
+UPDATE moves SET min_hits = 2, max_hits = 2 WHERE name IN (
+    'double-kick', 'bonemerang', 'double-hit', 'dual-chop', 'twineedle', 'gear-grind',
+    'dragon-darts', 'double-iron-bash', 'dual-wingbeat'
+);
+UPDATE moves SET min_hits = 3, max_hits = 3 WHERE name IN ('triple-kick', 'triple-axel', 'surging-strikes');
+UPDATE moves SET min_hits = 2, max_hits = 5 WHERE name IN (
+    'fury-attack', 'fury-swipes', 'pin-missile', 'bullet-seed', 'rock-blast', 'icicle-spear',
+    'arm-thrust', 'barrage', 'comet-punch', 'double-slap', 'spike-cannon', 'tail-slap',
+    'bone-rush', 'water-shuriken', 'scale-shot'
+);
+
+-- Moves a pokemon is locked into for the next turn, charging a move like
+-- solar-beam or recharging after one like hyper-beam
+ALTER TABLE battles
+ADD COLUMN user_charging_move_id INT REFERENCES moves(move_id),
+ADD COLUMN user_recharging BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN challenger_charging_move_id INT REFERENCES moves(move_id),
+ADD COLUMN challenger_recharging BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE battles
+DROP COLUMN challenger_recharging,
+DROP COLUMN challenger_charging_move_id,
+DROP COLUMN user_recharging,
+DROP COLUMN user_charging_move_id;
+
+ALTER TABLE moves
+DROP COLUMN max_hits,
+DROP COLUMN min_hits,
+DROP COLUMN priority;
@@ -0,0 +1,45 @@
+-- +goose Up
+-- Battles outlive their challenger so they can be kept as history
+ALTER TABLE battles
+DROP CONSTRAINT battles_challenger_pokemon_id_fkey,
+ALTER COLUMN challenger_pokemon_id DROP NOT NULL,
+ADD CONSTRAINT battles_challenger_pokemon_id_fkey
+    FOREIGN KEY (challenger_pokemon_id) REFERENCES challenger_pokemon(id) ON DELETE SET NULL;
+
+ALTER TABLE battles
+ADD COLUMN kind TEXT NOT NULL DEFAULT 'trainer' CHECK (kind IN ('wild', 'trainer')),
+ADD COLUMN challenger_species_id INT REFERENCES pokedex(id) ON DELETE SET NULL,
+ADD COLUMN flee_attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN result TEXT CHECK (result IN ('won', 'lost', 'fled', 'forfeited')),
+ADD COLUMN ended_at TIMESTAMPTZ;
+
+UPDATE battles b
+SET challenger_species_id = cp.pokemon_id
+FROM challenger_pokemon cp
+WHERE b.challenger_pokemon_id = cp.id;
+
+-- Challengers already knocked out were won
+UPDATE battles b
+SET result = 'won', ended_at = NOW()
+FROM challenger_pokemon cp
+WHERE b.challenger_pokemon_id = cp.id AND cp.current_hp <= 0;
+
+CREATE INDEX idx_battles_user ON battles (user_id, ended_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_battles_user;
+
+DELETE FROM battles WHERE challenger_pokemon_id IS NULL;
+
+ALTER TABLE battles
+DROP COLUMN ended_at,
+DROP COLUMN result,
+DROP COLUMN flee_attempts,
+DROP COLUMN challenger_species_id,
+DROP COLUMN kind;
+
+ALTER TABLE battles
+DROP CONSTRAINT battles_challenger_pokemon_id_fkey,
+ALTER COLUMN challenger_pokemon_id SET NOT NULL,
+ADD CONSTRAINT battles_challenger_pokemon_id_fkey
+    FOREIGN KEY (challenger_pokemon_id) REFERENCES challenger_pokemon(id) ON DELETE CASCADE;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Battles closed by the janitor after sitting idle
+ALTER TABLE battles
+DROP CONSTRAINT battles_result_check,
+ADD CONSTRAINT battles_result_check CHECK (result IN ('won', 'lost', 'fled', 'forfeited', 'expired'));
+
+CREATE INDEX idx_battles_open ON battles (updated_at) WHERE result IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_battles_open;
+
+UPDATE battles SET result = 'fled' WHERE result = 'expired';
+
+ALTER TABLE battles
+DROP CONSTRAINT battles_result_check,
+ADD CONSTRAINT battles_result_check CHECK (result IN ('won', 'lost', 'fled', 'forfeited'));
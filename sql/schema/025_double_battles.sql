@@ -0,0 +1,52 @@
+-- +goose Up
+-- Who a move hits, PokéAPI's target name, e.g. selected-pokemon or all-opponents
+ALTER TABLE moves
+ADD COLUMN target TEXT NOT NULL DEFAULT 'selected-pokemon';
+
+-- Moves cached before targets were stored
+UPDATE moves SET target = 'all-opponents' WHERE name IN (
+    'rock-slide', 'heat-wave', 'blizzard', 'icy-wind', 'hyper-voice', 'muddy-water',
+    'dazzling-gleam', 'air-cutter', 'eruption', 'water-spout', 'snarl', 'razor-leaf',
+    'swift', 'bubble', 'powder-snow', 'acid', 'electroweb', 'struggle-bug', 'twister',
+    'glaciate', 'origin-pulse', 'precipice-blades', 'diamond-storm', 'lands-wrath',
+    'breaking-swipe', 'razor-wind', 'overdrive', 'burning-jealousy', 'clanging-scales',
+    'make-it-rain', 'bleakwind-storm', 'wildbolt-storm', 'sandsear-storm', 'springtide-storm'
+);
+UPDATE moves SET target = 'all-other-pokemon' WHERE name IN (
+    'earthquake', 'surf', 'discharge', 'lava-plume', 'explosion', 'self-destruct',
+    'bulldoze', 'magnitude', 'sludge-wave', 'boomburst', 'parabolic-charge',
+    'petal-blizzard', 'searing-shot', 'mind-blown', 'brutal-swing', 'synchronoise',
+    'misty-explosion'
+);
+UPDATE moves SET target = 'random-opponent' WHERE name IN (
+    'outrage', 'thrash', 'petal-dance', 'uproar', 'struggle', 'raging-fury'
+);
+UPDATE moves SET target = 'entire-field' WHERE name IN ('rain-dance', 'sunny-day', 'sandstorm', 'hail');
+
+-- Double battles put a second pokemon out on each side. The user's lead is
+-- user_pokemon_id and the challenger's is challenger_pokemon_id as before
+ALTER TABLE battles
+ADD COLUMN format TEXT NOT NULL DEFAULT 'singles' CHECK (format IN ('singles', 'doubles')),
+ADD COLUMN user_partner_id UUID REFERENCES user_pokemon(id) ON DELETE SET NULL,
+ADD COLUMN user_partner_charging_move_id INT REFERENCES moves(move_id),
+ADD COLUMN user_partner_recharging BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN challenger_partner_id UUID REFERENCES challenger_pokemon(id) ON DELETE SET NULL,
+ADD COLUMN challenger_partner_charging_move_id INT REFERENCES moves(move_id),
+ADD COLUMN challenger_partner_recharging BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+DELETE FROM challenger_pokemon cp
+USING battles b
+WHERE b.challenger_partner_id = cp.id;
+
+ALTER TABLE battles
+DROP COLUMN challenger_partner_recharging,
+DROP COLUMN challenger_partner_charging_move_id,
+DROP COLUMN challenger_partner_id,
+DROP COLUMN user_partner_recharging,
+DROP COLUMN user_partner_charging_move_id,
+DROP COLUMN user_partner_id,
+DROP COLUMN format;
+
+ALTER TABLE moves
+DROP COLUMN target;
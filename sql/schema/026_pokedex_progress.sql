@@ -0,0 +1,43 @@
+-- +goose Up
+-- Species each user has seen or caught. Rows are never removed when a
+-- pokemon is released or traded away, so progress only goes up
+CREATE TABLE pokedex_progress (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pokemon_id INT NOT NULL REFERENCES pokedex(id) ON DELETE CASCADE,
+    caught BOOLEAN NOT NULL DEFAULT FALSE,
+    seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    caught_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, pokemon_id)
+);
+
+-- Everything users own or got in a trade counts as caught
+INSERT INTO pokedex_progress (user_id, pokemon_id, caught, seen_at, caught_at)
+SELECT user_id, pokemon_id, TRUE, MIN(created_at), MIN(created_at)
+FROM (
+    SELECT user_id, pokemon_id, COALESCE(created_at, NOW()) AS created_at
+    FROM user_pokemon
+    WHERE pokemon_id IS NOT NULL
+    UNION ALL
+    SELECT to_user_id, offered_species_id, resolved_at FROM trades WHERE status = 'accepted'
+    UNION ALL
+    SELECT from_user_id, requested_species_id, resolved_at FROM trades WHERE status = 'accepted'
+) caught
+GROUP BY user_id, pokemon_id;
+
+-- and everything they've battled as seen
+INSERT INTO pokedex_progress (user_id, pokemon_id, seen_at)
+SELECT user_id, challenger_species_id, MIN(created_at)
+FROM battles
+WHERE challenger_species_id IS NOT NULL
+GROUP BY user_id, challenger_species_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO pokedex_progress (user_id, pokemon_id)
+SELECT u.id, cp.pokemon_id
+FROM users u
+JOIN challenger_pokemon cp ON u.challenge_pokemon_id = cp.id
+WHERE cp.pokemon_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE pokedex_progress;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- Species and move names and flavor text in each language PokéAPI has them
+-- in, keyed by PokéAPI's language names like es, fr or ja-Hrkt. Entries
+-- cached before these tables existed have no rows and fall back to their
+-- English slug
+CREATE TABLE pokemon_names (
+    pokemon_id INT NOT NULL REFERENCES pokedex(id) ON DELETE CASCADE,
+    language TEXT NOT NULL,
+    name TEXT,
+    flavor_text TEXT,
+    PRIMARY KEY (pokemon_id, language)
+);
+
+CREATE TABLE move_names (
+    move_id INT NOT NULL REFERENCES moves(move_id) ON DELETE CASCADE,
+    language TEXT NOT NULL,
+    name TEXT,
+    flavor_text TEXT,
+    PRIMARY KEY (move_id, language)
+);
+
+-- +goose Down
+DROP TABLE move_names;
+DROP TABLE pokemon_names;
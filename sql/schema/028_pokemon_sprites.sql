@@ -0,0 +1,14 @@
+-- +goose Up
+-- Where each species' sprites are on PokéAPI's sprite host, one row per
+-- variant. The sprite job downloads them into SPRITE_DIR; url is null when
+-- PokéAPI has no sprite for the variant. Species cached before this table
+-- existed get their rows on the job's next sweep
+CREATE TABLE pokemon_sprites (
+    pokemon_id INT NOT NULL REFERENCES pokedex(id) ON DELETE CASCADE,
+    variant TEXT NOT NULL,
+    url TEXT,
+    PRIMARY KEY (pokemon_id, variant)
+);
+
+-- +goose Down
+DROP TABLE pokemon_sprites;
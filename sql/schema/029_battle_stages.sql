@@ -0,0 +1,16 @@
+-- +goose Up
+-- Attack stages lowered by abilities like intimidate. They last until the
+-- pokemon is switched out, so entry abilities only trigger when a pokemon
+-- comes out instead of every turn
+ALTER TABLE battles
+ADD COLUMN user_attack_stage INT NOT NULL DEFAULT 0,
+ADD COLUMN user_partner_attack_stage INT NOT NULL DEFAULT 0,
+ADD COLUMN challenger_attack_stage INT NOT NULL DEFAULT 0,
+ADD COLUMN challenger_partner_attack_stage INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE battles
+DROP COLUMN challenger_partner_attack_stage,
+DROP COLUMN challenger_attack_stage,
+DROP COLUMN user_partner_attack_stage,
+DROP COLUMN user_attack_stage;
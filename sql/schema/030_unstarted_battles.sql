@@ -0,0 +1,8 @@
+-- +goose Up
+-- Battles left open at turn 0 when their challenger was replaced. The
+-- challenger is gone, so they'd only ever be closed as expired
+DELETE FROM battles
+WHERE result IS NULL AND turn = 0 AND challenger_pokemon_id IS NULL;
+
+-- +goose Down
+-- The deleted battles were never played, there's nothing to restore